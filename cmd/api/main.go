@@ -2,75 +2,397 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/sample-provider/buy-credit-api/internal/application"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/certs"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/config"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/health"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/handler"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/metrics"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/provisioning"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/risk"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/security"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/tracing"
 )
 
 func main() {
-	// Initialize repositories (in-memory for this example)
-	transactionRepo := repository.NewInMemoryTransactionRepository()
-	partnerRepo := repository.NewInMemoryPartnerRepository()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	flag.Parse()
 
-	// Initialize JWT service
-	jwtService := auth.NewJWTService("your-secret-key-change-in-production")
+	// Load configuration: defaults, then the config file, then environment
+	// variables. Invalid or insecure settings stop startup.
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
+
+	// Initialize logging first so later startup failures are structured too.
+	logLevel, err := logging.ParseLevel(cfg.Logging.Level)
+	if err != nil {
+		fatal("invalid log level", err)
+	}
+	logger, err := logging.New(logging.Config{
+		Level:  logLevel,
+		Format: logging.Format(cfg.Logging.Format),
+		Output: os.Stdout,
+	})
+	if err != nil {
+		fatal("invalid log format", err)
+	}
+	slog.SetDefault(logger)
+
+	if cfg.IsDevelopment() {
+		slog.Warn("running in development mode; insecure settings are permitted", "demo_data", cfg.DemoData)
+	}
+
+	// Initialize tracing. Spans are discarded unless the exporter is
+	// "console" or "otlp"; trace IDs are propagated either way.
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    tracing.Exporter(cfg.Tracing.Exporter),
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("failed to initialize tracing", err)
+	}
+
+	// Secrets (signing keys, demo credentials) are read through a provider
+	// and cached, so values rotated in the store take effect without a
+	// redeploy.
+	secretProvider, err := newSecretProvider(cfg.Secrets)
+	if err != nil {
+		fatal("failed to initialize secret provider", err)
+	}
+
+	// Initialize repositories (in-memory for this example). Repositories on
+	// the request path are wrapped so their calls appear in traces.
+	var demoSecrets auth.SecretProvider
+	if cfg.DemoData {
+		demoSecrets = secretProvider
+	}
+	inMemoryPartnerRepo, err := repository.NewInMemoryPartnerRepository(context.Background(), demoSecrets)
+	if err != nil {
+		fatal("failed to initialize partners", err)
+	}
+	transactionRepo := repository.NewTracedTransactionRepository(repository.NewInMemoryTransactionRepository())
+	partnerRepo := repository.NewTracedPartnerRepository(inMemoryPartnerRepo)
+	refreshTokenRepo := repository.NewTracedRefreshTokenRepository(repository.NewInMemoryRefreshTokenRepository())
+	revokedTokenRepo := repository.NewTracedRevokedTokenRepository(repository.NewInMemoryRevokedTokenRepository())
+	loginAttemptRepo := repository.NewInMemoryLoginAttemptRepository()
+	nonceRepo := repository.NewInMemoryNonceRepository()
+	rateLimitRepo := repository.NewInMemoryRateLimitRepository()
+	spendingRepo := repository.NewTracedSpendingRepository(repository.NewInMemorySpendingRepository())
+	purchaseHistoryRepo := repository.NewInMemoryPurchaseHistoryRepository()
+	auditRepo := repository.NewInMemoryAuditRepository()
+	leaseRepo := repository.NewInMemoryLeaseRepository()
+
+	// Partner lookups on authenticated requests go through a short-lived
+	// cache; status changes made via partnerCache take effect immediately.
+	partnerCache := repository.NewCachedPartnerRepository(partnerRepo, cfg.Auth.PartnerCacheTTL)
+
+	// Initialize signing keys and JWT service. Keys are generated and rotated
+	// in memory unless a signing key secret is configured. Rotated-out keys
+	// remain published for longer than the access token lifetime.
+	keyManagerConfig := auth.KeyManagerConfig{
+		Algorithm:        auth.Algorithm(cfg.Auth.SigningAlgorithm),
+		RotationInterval: cfg.Auth.KeyRotationInterval,
+		GracePeriod:      2 * cfg.Auth.TokenTTL,
+	}
+	if cfg.Auth.SigningKeySecret != "" {
+		keyManagerConfig.Secrets = secretProvider
+		keyManagerConfig.KeySecretName = cfg.Auth.SigningKeySecret
+		keyManagerConfig.RefreshInterval = cfg.Auth.SigningKeyRefreshInterval
+	}
+	keyManager, err := auth.NewKeyManager(keyManagerConfig)
+	if err != nil {
+		fatal("failed to initialize signing keys", err)
+	}
+
+	keyCtx, stopKeyRotation := context.WithCancel(context.Background())
+	defer stopKeyRotation()
+	go keyManager.Run(keyCtx)
+
+	jwtService := auth.NewJWTService(keyManager)
+
+	// Initialize security event publishing and brute-force protection
+	securityEvents := security.NewLogEventPublisher()
+	loginGuard := application.NewLoginGuard(loginAttemptRepo, securityEvents, application.DefaultLoginGuardConfig())
+
+	// Initialize fraud and velocity rules. Rules are read from the rules file
+	// when set, and reloaded when the file changes or on SIGHUP.
+	riskEngine, err := risk.NewEngine(purchaseHistoryRepo, cfg.Risk.RulesFile)
+	if err != nil {
+		fatal("failed to load risk rules", err)
+	}
+
+	riskCtx, stopRiskReload := context.WithCancel(context.Background())
+	defer stopRiskReload()
+	go riskEngine.Run(riskCtx, cfg.Risk.ReloadInterval)
+
+	// Initialize Prometheus metrics, served on a separate admin port
+	appMetrics := metrics.New()
 
 	// Initialize use cases
-	authUseCase := application.NewAuthUseCase(partnerRepo, jwtService)
-	transactionUseCase := application.NewTransactionUseCase(transactionRepo)
+	auditLog := application.NewAuditLog(auditRepo)
+	authUseCase := application.NewAuthUseCase(partnerCache, refreshTokenRepo, revokedTokenRepo, jwtService, loginGuard, securityEvents, auditLog, application.AuthConfig{
+		TokenTTL:        cfg.Auth.TokenTTL,
+		RefreshTokenTTL: cfg.Auth.RefreshTokenTTL,
+	})
+	transactionUseCase := application.NewTransactionUseCase(transactionRepo, partnerCache, spendingRepo, riskEngine, appMetrics, auditLog)
+	credentialUseCase := application.NewCredentialUseCase(partnerCache, auditLog)
+	partnerAdminUseCase := application.NewPartnerAdminUseCase(partnerCache, auditLog)
+	reviewUseCase := application.NewReviewUseCase(transactionRepo, spendingRepo, leaseRepo, auditLog, appMetrics, cfg.Review.SLA)
+	transactionAdminUseCase := application.NewTransactionAdminUseCase(transactionRepo, spendingRepo, leaseRepo, auditLog, appMetrics)
+
+	appMetrics.RegisterQueueDepth("review", reviewUseCase.HeldCount)
+
+	// Held transactions not reviewed within the SLA are rejected automatically
+	reviewCtx, stopReviewSweep := context.WithCancel(context.Background())
+	defer stopReviewSweep()
+	go reviewUseCase.Run(reviewCtx, time.Minute)
+
+	// Transactions left PENDING, e.g. by a crash before provisioning, are
+	// settled with the provisioning gateway. The sweep and per-transaction
+	// locks use leaseRepo, which is in memory and so only keeps sweeps apart
+	// within this process; outside development recovery therefore runs
+	// only when the deployment declares a single instance.
+	var gateway *provisioning.HTTPGateway
+	switch {
+	case !cfg.Recovery.Enabled:
+		slog.Warn("transaction recovery disabled")
+	case cfg.Provisioning.GatewayURL == "":
+		slog.Warn("transaction recovery disabled; no provisioning gateway configured")
+	case !cfg.IsDevelopment() && !cfg.Recovery.SingleInstance:
+		slog.Warn("transaction recovery disabled; leases are not shared between instances, set RECOVERY_SINGLE_INSTANCE=true if only one instance runs")
+	default:
+		hostname, _ := os.Hostname()
+		gateway = provisioning.NewHTTPGateway(cfg.Provisioning.GatewayURL, cfg.Provisioning.GatewayToken, cfg.Provisioning.Timeout)
+		recoveryUseCase := application.NewTransactionRecoveryUseCase(transactionRepo, spendingRepo, leaseRepo, gateway, auditLog, appMetrics, application.RecoveryConfig{
+			PendingThreshold: cfg.Recovery.PendingThreshold,
+			MaxAttempts:      cfg.Recovery.MaxAttempts,
+			BatchSize:        cfg.Recovery.BatchSize,
+			InstanceID:       fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		})
+
+		appMetrics.RegisterQueueDepth("escalated", recoveryUseCase.EscalatedCount)
+
+		recoveryCtx, stopRecoverySweep := context.WithCancel(context.Background())
+		defer stopRecoverySweep()
+		go recoveryUseCase.Run(recoveryCtx, cfg.Recovery.Interval)
+	}
+
+	// Readiness checks. Results are cached briefly so frequent probes do not
+	// load the dependencies; further checks can be registered as they are added.
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+	healthRegistry.Register("signing_keys", keyManager.Check)
+	healthRegistry.Register("audit_log", auditLog.Check)
+	healthRegistry.Register("review_queue", func(ctx context.Context) error {
+		_, err := reviewUseCase.HeldCount(ctx)
+		return err
+	})
+	if gateway != nil {
+		healthRegistry.Register("provisioning_gateway", gateway.Check)
+	}
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authUseCase)
 	transactionHandler := handler.NewTransactionHandler(transactionUseCase)
+	jwksHandler := handler.NewJWKSHandler(keyManager)
+	credentialHandler := handler.NewCredentialHandler(credentialUseCase)
+	partnerAdminHandler := handler.NewPartnerAdminHandler(partnerAdminUseCase)
+	riskAdminHandler := handler.NewRiskAdminHandler(riskEngine, auditLog)
+	reviewAdminHandler := handler.NewReviewAdminHandler(reviewUseCase)
+	transactionAdminHandler := handler.NewTransactionAdminHandler(transactionAdminUseCase)
+	auditAdminHandler := handler.NewAuditAdminHandler(auditLog)
+	healthHandler := handler.NewHealthHandler(healthRegistry)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtService)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, revokedTokenRepo, partnerCache)
+	signatureMiddleware := middleware.NewSignatureMiddleware(partnerCache, nonceRepo, secretProvider, middleware.DefaultSignatureClockSkew)
+	ipAllowlistMiddleware := middleware.NewIPAllowlistMiddleware(partnerCache, securityEvents)
+	// Requests are counted locally while the rate limit store is unavailable;
+	// past the fallback's capacity they are refused.
+	rateLimitFallback := repository.NewBoundedRateLimitRepository(middleware.RateLimitFallbackKeys)
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(rateLimitRepo, rateLimitFallback, partnerCache)
+
+	// Admin API keys, as comma-separated name:key pairs. With none configured
+	// the admin API rejects every request.
+	adminKeys, err := middleware.ParseAdminKeys(cfg.Admin.APIKeys)
+	if err != nil {
+		fatal("invalid admin API keys", err)
+	}
+	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(adminKeys)
+
+	// X-Forwarded-For is only honoured from these proxies; by default the
+	// peer address is always used.
+	trustedProxies, err := middleware.ParseTrustedProxies(strings.Join(cfg.Server.TrustedProxies, ","))
+	if err != nil {
+		fatal("invalid trusted proxies", err)
+	}
 
 	// Setup router
 	router := handler.SetupRouter(
 		authHandler,
 		transactionHandler,
+		jwksHandler,
+		credentialHandler,
+		partnerAdminHandler,
+		riskAdminHandler,
+		reviewAdminHandler,
+		transactionAdminHandler,
+		auditAdminHandler,
+		healthHandler,
+		appMetrics,
 		authMiddleware,
+		signatureMiddleware,
+		ipAllowlistMiddleware,
+		rateLimitMiddleware,
+		adminAuthMiddleware,
+		trustedProxies,
+		logger,
 	)
 
 	// Create HTTP server
 	srv := &http.Server{
-		Addr:         ":8080",
+		Addr:         cfg.Server.Addr,
 		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	// Optional TLS / mutual TLS. Certificates are reloaded from disk when
+	// they change, or immediately on SIGHUP.
+	var certReloader *certs.Reloader
+	if cfg.TLS.Enabled() {
+		certReloader, err = certs.NewReloader(certs.Config{
+			CertFile:     cfg.TLS.CertFile,
+			KeyFile:      cfg.TLS.KeyFile,
+			ClientCAFile: cfg.TLS.ClientCAFile,
+			ClientAuth:   certs.ClientAuthMode(cfg.TLS.ClientAuth),
+		})
+		if err != nil {
+			fatal("failed to load TLS certificates", err)
+		}
+		srv.TLSConfig = certReloader.TLSConfig()
+		healthRegistry.Register("tls_certificate", certReloader.Check)
+
+		certCtx, stopCertReload := context.WithCancel(context.Background())
+		defer stopCertReload()
+		go certReloader.Run(certCtx, cfg.TLS.ReloadInterval)
+	}
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", appMetrics.Handler())
+	metricsSrv := &http.Server{
+		Addr:         cfg.Metrics.Addr,
+		Handler:      metricsMux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
 	}
 
+	go func() {
+		slog.Info("starting metrics server", "addr", cfg.Metrics.Addr)
+		if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal("metrics server failed to start", err)
+		}
+	}()
+
 	// Start server in goroutine
 	go func() {
-		log.Println("Starting server on :8080")
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
+		var err error
+		if certReloader != nil {
+			slog.Info("starting TLS server", "addr", cfg.Server.Addr)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			slog.Info("starting server", "addr", cfg.Server.Addr)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			fatal("server failed to start", err)
 		}
 	}()
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range quit {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		if err := riskEngine.Reload(); err != nil {
+			slog.Error("risk rules reload failed", "error", err)
+		}
+		if certReloader == nil {
+			continue
+		}
+		if err := certReloader.Reload(); err != nil {
+			slog.Error("TLS certificate reload failed", "error", err)
+			continue
+		}
+		slog.Info("TLS certificates reloaded")
+	}
 
-	log.Println("Shutting down server...")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Fail readiness first and keep serving while load balancers notice, so
+	// in-flight and newly routed requests are not cut off.
+	healthRegistry.SetDraining()
+	slog.Info("draining before shutdown", "delay", cfg.Server.DrainDelay.String())
+	time.Sleep(cfg.Server.DrainDelay)
+
+	slog.Info("shutting down server")
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal("server forced to shutdown", err)
+	}
+	if err := metricsSrv.Shutdown(ctx); err != nil {
+		slog.Error("metrics server forced to shutdown", "error", err)
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("trace exporter shutdown failed", "error", err)
+	}
+
+	slog.Info("server exited")
+}
+
+// newSecretProvider builds the configured secret provider behind a cache.
+func newSecretProvider(cfg config.SecretsConfig) (auth.SecretProvider, error) {
+	var provider auth.SecretProvider
+	switch cfg.Provider {
+	case config.SecretsProviderEnv:
+		provider = auth.NewEnvSecretProvider(cfg.EnvPrefix)
+	case config.SecretsProviderVault:
+		vault, err := auth.NewVaultSecretProvider(auth.VaultConfig{
+			Addr:  cfg.VaultAddr,
+			Token: cfg.VaultToken,
+			Mount: cfg.VaultMount,
+		})
+		if err != nil {
+			return nil, err
+		}
+		provider = vault
+	default:
+		provider = auth.NewFileSecretProvider(cfg.Dir)
+	}
+	return auth.NewCachedSecretProvider(provider, cfg.CacheTTL), nil
+}
 
-	log.Println("Server exited")
+// fatal logs err and exits without running deferred cleanup.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
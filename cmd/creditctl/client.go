@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+// client calls the admin API.
+type client struct {
+	baseURL  *url.URL
+	adminKey string
+	http     *http.Client
+}
+
+// apiError is an error response from the API.
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+	TraceID string
+}
+
+func (e *apiError) Error() string {
+	msg := fmt.Sprintf("%s: %s (HTTP %d)", e.Code, e.Message, e.Status)
+	if e.TraceID != "" {
+		msg += ", trace " + e.TraceID
+	}
+	return msg
+}
+
+func newClient(server, adminKey string, timeout time.Duration) (*client, error) {
+	baseURL, err := url.Parse(strings.TrimRight(server, "/"))
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return nil, usageError("-server must be an http or https URL")
+	}
+	return &client{
+		baseURL:  baseURL,
+		adminKey: adminKey,
+		http:     &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// get fetches path under /admin/v1 and decodes the response into out.
+func (c *client) get(ctx context.Context, path string, query url.Values, out any) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// post sends body to path under /admin/v1 and decodes the response into out.
+func (c *client) post(ctx context.Context, path string, body, out any) error {
+	return c.do(ctx, http.MethodPost, path, nil, body, out)
+}
+
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	u := *c.baseURL
+	u.Path += "/admin/v1" + path
+	u.RawQuery = query.Encode()
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(middleware.AdminKeyHeader, c.adminKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := &apiError{Status: resp.StatusCode, Code: "HTTP_ERROR", Message: resp.Status}
+		var envelope response.ErrorResponse
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&envelope); err == nil && envelope.Error.Code != "" {
+			apiErr.Code = envelope.Error.Code
+			apiErr.Message = envelope.Error.Message
+			apiErr.TraceID = envelope.Error.TraceID
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
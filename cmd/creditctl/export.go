@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+// exporter writes one record at a time: a JSON object per line in json mode,
+// so large exports stream and can be processed line by line, or a table.
+type exporter struct {
+	out   *bufio.Writer
+	file  *os.File
+	enc   *json.Encoder
+	table *table
+	count int
+}
+
+func (c *cli) newExporter(path string, header ...string) (*exporter, error) {
+	e := &exporter{}
+	var w io.Writer = c.stdout
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		e.file = file
+		w = file
+	}
+	e.out = bufio.NewWriter(w)
+	if c.output == "json" {
+		e.enc = json.NewEncoder(e.out)
+	} else {
+		e.table = &table{header: header}
+	}
+	return e, nil
+}
+
+func (e *exporter) write(record any, row ...string) error {
+	e.count++
+	if e.enc != nil {
+		return e.enc.Encode(record)
+	}
+	e.table.add(row...)
+	return nil
+}
+
+// close flushes the export. A failed export removes its partial file.
+func (e *exporter) close(exportErr error) error {
+	if exportErr == nil && e.table != nil {
+		exportErr = writeTable(e.out, e.table)
+	}
+	if exportErr == nil {
+		exportErr = e.out.Flush()
+	}
+	if e.file != nil {
+		exportErr = errors.Join(exportErr, e.file.Close())
+		if exportErr != nil {
+			os.Remove(e.file.Name())
+		}
+	}
+	return exportErr
+}
+
+func (c *cli) exportFlags(name string) (*flag.FlagSet, *string) {
+	fs := c.flagSet(name)
+	file := fs.String("file", "", "write to this new file instead of stdout")
+	return fs, file
+}
+
+func (c *cli) exportPartners(ctx context.Context, args []string) error {
+	fs, file := c.exportFlags("export partners")
+	status := fs.String("status", "", "only partners with this status")
+	if _, err := parseCommand(fs, args, 0, ""); err != nil {
+		return err
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	e, err := c.newExporter(*file, partnerTable(nil).header...)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	setQuery(query, "status", *status)
+	err = listAllPartners(ctx, api, query, func(p *application.PartnerResponse) error {
+		return e.write(p, partnerTable([]*application.PartnerResponse{p}).rows[0]...)
+	})
+	return c.finishExport(e, err, *file, "partners")
+}
+
+func (c *cli) exportTransactions(ctx context.Context, args []string) error {
+	fs, file := c.exportFlags("export transactions")
+	var filter transactionQuery
+	filter.register(fs, true)
+	if _, err := parseCommand(fs, args, 0, ""); err != nil {
+		return err
+	}
+	query, err := filter.values(time.Now())
+	if err != nil {
+		return err
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	e, err := c.newExporter(*file, transactionTable(nil).header...)
+	if err != nil {
+		return err
+	}
+	err = listAllTransactions(ctx, api, query, func(txn *application.TransactionDetailResponse) error {
+		return e.write(txn, transactionTable([]*application.TransactionDetailResponse{txn}).rows[0]...)
+	})
+	return c.finishExport(e, err, *file, "transactions")
+}
+
+func (c *cli) exportAudit(ctx context.Context, args []string) error {
+	fs, file := c.exportFlags("export audit")
+	action := fs.String("action", "", "only entries for this action")
+	resourceType := fs.String("resource-type", "", "only entries for this resource type")
+	resourceID := fs.String("resource-id", "", "only entries for this resource")
+	since := fs.String("since", "", "recorded at or after this time")
+	until := fs.String("until", "", "recorded before this time")
+	if _, err := parseCommand(fs, args, 0, ""); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	setQuery(query, "action", *action)
+	setQuery(query, "resourceType", *resourceType)
+	setQuery(query, "resourceId", *resourceID)
+	now := time.Now()
+	for name, value := range map[string]string{"since": *since, "until": *until} {
+		t, err := parseTime(name, value, now)
+		if err != nil {
+			return err
+		}
+		if !t.IsZero() {
+			query.Set(name, t.UTC().Format(time.RFC3339Nano))
+		}
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	e, err := c.newExporter(*file, auditTable(nil).header...)
+	if err != nil {
+		return err
+	}
+	query.Set("limit", strconv.Itoa(application.MaxAuditQueryLimit))
+	err = listAllAudit(ctx, api, query, func(entry *entity.AuditEntry) error {
+		return e.write(entry, auditTable([]*entity.AuditEntry{entry}).rows[0]...)
+	})
+	return c.finishExport(e, err, *file, "audit entries")
+}
+
+func (c *cli) finishExport(e *exporter, err error, file, what string) error {
+	if err := e.close(err); err != nil {
+		return err
+	}
+	if file != "" {
+		c.note("exported %d %s to %s", e.count, what, file)
+	}
+	return nil
+}
+
+// listAllPartners pages through every partner matching query.
+func listAllPartners(ctx context.Context, api *client, query url.Values, each func(*application.PartnerResponse) error) error {
+	query.Set("limit", strconv.Itoa(application.MaxPartnerListLimit))
+	for {
+		var resp application.PartnerListResponse
+		if err := api.get(ctx, "/partners", query, &resp); err != nil {
+			return err
+		}
+		for _, partner := range resp.Partners {
+			if err := each(partner); err != nil {
+				return err
+			}
+		}
+		if resp.NextAfter == "" {
+			return nil
+		}
+		query.Set("after", resp.NextAfter)
+	}
+}
+
+// listAllAudit pages through every audit entry matching query.
+func listAllAudit(ctx context.Context, api *client, query url.Values, each func(*entity.AuditEntry) error) error {
+	for {
+		var resp application.AuditQueryResponse
+		if err := api.get(ctx, "/audit", query, &resp); err != nil {
+			return err
+		}
+		for _, entry := range resp.Entries {
+			if err := each(entry); err != nil {
+				return err
+			}
+		}
+		if resp.NextAfter == 0 {
+			return nil
+		}
+		query.Set("after", strconv.FormatInt(resp.NextAfter, 10))
+	}
+}
+
+func auditTable(entries []*entity.AuditEntry) *table {
+	t := &table{header: []string{"SEQ", "TIME", "ACTION", "ACTOR", "RESOURCE", "DETAILS"}}
+	for _, entry := range entries {
+		details := "-"
+		if len(entry.Details) > 0 {
+			data, _ := json.Marshal(entry.Details)
+			details = string(data)
+		}
+		t.add(strconv.FormatInt(entry.Sequence, 10), formatTime(entry.Timestamp), string(entry.Action),
+			orDash(entry.Actor), fmt.Sprintf("%s/%s", entry.ResourceType, entry.ResourceID), details)
+	}
+	return t
+}
@@ -0,0 +1,265 @@
+// Command creditctl is the operations CLI for the buy-credit API. It talks to
+// the admin API, so it works against any running instance without access to
+// its storage.
+//
+//	creditctl [flags] <command> <subcommand> [flags] [args]
+//
+// Run creditctl help for the command list.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Exit codes. Reconciliation exits with exitFindings when it finds
+// discrepancies so scheduled runs can alert on them.
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitFindings = 3
+)
+
+const usage = `Usage: creditctl [flags] <command> [args]
+
+Commands:
+  partners list [-status S] [-limit N] [-after ID]
+  partners get <partnerId>
+  partners create -name NAME -wallet ID -scopes S1,S2 [-client-id ID]
+                  [-auth-modes BEARER,HMAC] [-transaction-types T1,T2]
+  partners suspend <partnerId> -reason TEXT
+  partners reactivate <partnerId> -reason TEXT
+  partners rotate-secret <partnerId> [-grace DURATION]
+
+  transactions list [-partner ID] [-status S] [-since T] [-until T] [-limit N] [-after ID]
+  transactions get <transactionId>
+  transactions fail <transactionId> -reason TEXT
+
+  reconcile [-since T] [-until T] [-partner ID] [-stale DURATION]
+  export partners|transactions|audit [-since T] [-until T] [-file PATH]
+
+Times (T) are RFC 3339 timestamps or durations before now, such as 24h.
+
+Flags, accepted before or after the command:
+  -server URL           API base URL (env CREDITCTL_SERVER, default http://localhost:8080)
+  -admin-key-file PATH  file holding the admin API key (env CREDITCTL_ADMIN_KEY_FILE);
+                        otherwise the key is read from CREDITCTL_ADMIN_KEY
+  -o json|table         output format (default table)
+  -timeout DURATION     per-request timeout (default 30s)
+
+Exit status is 0 on success, 1 on error, 2 on bad usage and 3 when
+reconcile finds discrepancies.
+`
+
+var errUsage = errors.New("usage")
+
+// usageError reports a mistake in the command line, which exits with
+// exitUsage.
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// errFindings is returned by commands that completed but found problems the
+// operator has to act on.
+var errFindings = errors.New("discrepancies found")
+
+type cli struct {
+	server       string
+	adminKeyFile string
+	output       string
+	timeout      time.Duration
+
+	client *client
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := &cli{
+		server:       envOr("CREDITCTL_SERVER", "http://localhost:8080"),
+		adminKeyFile: os.Getenv("CREDITCTL_ADMIN_KEY_FILE"),
+		output:       "table",
+		timeout:      30 * time.Second,
+		stdout:       stdout,
+		stderr:       stderr,
+	}
+
+	err := c.dispatch(ctx, args)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "creditctl: %s\nRun 'creditctl help' for usage.\n", strings.TrimPrefix(err.Error(), "usage: "))
+		return exitUsage
+	case errors.Is(err, errFindings):
+		fmt.Fprintf(stderr, "creditctl: %s\n", err)
+		return exitFindings
+	default:
+		fmt.Fprintf(stderr, "creditctl: %s\n", err)
+		return exitError
+	}
+}
+
+func (c *cli) dispatch(ctx context.Context, args []string) error {
+	// Global flags before the command stop at its name; the command's own
+	// flag set accepts them again afterwards.
+	fs := c.flagSet("creditctl")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError("%s", err)
+	}
+	args = fs.Args()
+	if len(args) == 0 || args[0] == "help" {
+		return flag.ErrHelp
+	}
+
+	commands := map[string]map[string]func(context.Context, []string) error{
+		"partners": {
+			"list":          c.partnersList,
+			"get":           c.partnersGet,
+			"create":        c.partnersCreate,
+			"suspend":       c.partnersSuspend,
+			"reactivate":    c.partnersReactivate,
+			"rotate-secret": c.partnersRotateSecret,
+		},
+		"transactions": {
+			"list": c.transactionsList,
+			"get":  c.transactionsGet,
+			"fail": c.transactionsFail,
+		},
+		"export": {
+			"partners":     c.exportPartners,
+			"transactions": c.exportTransactions,
+			"audit":        c.exportAudit,
+		},
+	}
+
+	name := args[0]
+	if name == "reconcile" {
+		return c.reconcile(ctx, args[1:])
+	}
+	subcommands, ok := commands[name]
+	if !ok {
+		return usageError("unknown command %q", name)
+	}
+	if len(args) < 2 {
+		return usageError("%s needs a subcommand", name)
+	}
+	command, ok := subcommands[args[1]]
+	if !ok {
+		return usageError("unknown command %q", name+" "+args[1])
+	}
+	return command(ctx, args[2:])
+}
+
+// api returns the admin API client. It is built on first use, after the
+// command has parsed its flags, since global flags may follow the command.
+func (c *cli) api() (*client, error) {
+	if c.client != nil {
+		return c.client, nil
+	}
+	switch c.output {
+	case "json", "table":
+	default:
+		return nil, usageError("-o must be json or table")
+	}
+
+	key := os.Getenv("CREDITCTL_ADMIN_KEY")
+	if c.adminKeyFile != "" {
+		data, err := os.ReadFile(c.adminKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read admin key: %w", err)
+		}
+		key = strings.TrimRight(string(data), "\r\n")
+	}
+	if key == "" {
+		return nil, usageError("set CREDITCTL_ADMIN_KEY or -admin-key-file")
+	}
+
+	client, err := newClient(c.server, key, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	return client, nil
+}
+
+// flagSet returns a flag set carrying the global flags, so they are accepted
+// anywhere on the command line.
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&c.server, "server", c.server, "API base URL")
+	fs.StringVar(&c.adminKeyFile, "admin-key-file", c.adminKeyFile, "file holding the admin API key")
+	fs.StringVar(&c.output, "o", c.output, "output format: json or table")
+	fs.DurationVar(&c.timeout, "timeout", c.timeout, "per-request timeout")
+	return fs
+}
+
+// parseFlags parses flags wherever they appear among the positional
+// arguments, which the flag package alone stops at, and returns the
+// positional arguments. A bare "--" ends flag parsing.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageError("%s", err)
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(args) > 0 && len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// parseCommand parses a command's flags and checks it received exactly
+// want positional arguments.
+func parseCommand(fs *flag.FlagSet, args []string, want int, names string) ([]string, error) {
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != want {
+		if want == 0 {
+			return nil, usageError("%s takes no arguments", fs.Name())
+		}
+		return nil, usageError("%s needs %s", fs.Name(), names)
+	}
+	return positional, nil
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+const testAdminKey = "test-admin-key"
+
+// fakeAdminAPI serves the admin endpoints creditctl uses from fixed data and
+// records the requests it receives.
+type fakeAdminAPI struct {
+	transactions []*application.TransactionDetailResponse
+	audit        []*entity.AuditEntry
+	chain        application.AuditVerification
+
+	requests []string
+	bodies   []map[string]string
+}
+
+func (api *fakeAdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(middleware.AdminKeyHeader) != testAdminKey {
+		response.Error(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid admin key")
+		return
+	}
+	api.requests = append(api.requests, r.Method+" "+r.URL.Path)
+	if r.Method == http.MethodPost {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		api.bodies = append(api.bodies, body)
+	}
+
+	switch r.URL.Path {
+	case "/admin/v1/transactions":
+		response.JSON(w, http.StatusOK, application.TransactionListResponse{Transactions: api.transactions})
+	case "/admin/v1/audit":
+		response.JSON(w, http.StatusOK, application.AuditQueryResponse{Entries: api.audit})
+	case "/admin/v1/audit/verify":
+		response.JSON(w, http.StatusOK, api.chain)
+	case "/admin/v1/partners/partner_acme/suspend":
+		response.JSON(w, http.StatusOK, application.PartnerResponse{ID: "partner_acme", Status: entity.PartnerStatusSuspended})
+	default:
+		response.Error(w, http.StatusNotFound, "PARTNER_NOT_FOUND", "Partner not found")
+	}
+}
+
+func runCLI(t *testing.T, api http.Handler, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	t.Setenv("CREDITCTL_ADMIN_KEY", testAdminKey)
+	t.Setenv("CREDITCTL_ADMIN_KEY_FILE", "")
+	if api != nil {
+		srv := httptest.NewServer(api)
+		t.Cleanup(srv.Close)
+		t.Setenv("CREDITCTL_SERVER", srv.URL)
+	}
+
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestRunUsage(t *testing.T) {
+	emptyKeyFile := filepath.Join(t.TempDir(), "admin_key")
+	if err := os.WriteFile(emptyKeyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "help", args: nil, wantCode: exitOK},
+		{name: "unknown command", args: []string{"refund"}, wantCode: exitUsage, wantErr: `unknown command "refund"`},
+		{name: "missing subcommand", args: []string{"partners"}, wantCode: exitUsage, wantErr: "needs a subcommand"},
+		{name: "missing argument", args: []string{"partners", "get"}, wantCode: exitUsage, wantErr: "needs a partner ID"},
+		{name: "extra argument", args: []string{"partners", "get", "a", "b"}, wantCode: exitUsage},
+		{name: "missing reason", args: []string{"partners", "suspend", "partner_acme"}, wantCode: exitUsage, wantErr: "needs -reason"},
+		{name: "bad output format", args: []string{"-o", "xml", "partners", "list"}, wantCode: exitUsage, wantErr: "-o must be"},
+		{name: "bad time", args: []string{"transactions", "list", "-since", "yesterday"}, wantCode: exitUsage, wantErr: "-since"},
+		{name: "bad server", args: []string{"-server", "ftp://host", "partners", "list"}, wantCode: exitUsage, wantErr: "-server"},
+		{name: "empty admin key", args: []string{"partners", "list", "-admin-key-file", emptyKeyFile}, wantCode: exitUsage, wantErr: "CREDITCTL_ADMIN_KEY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CREDITCTL_SERVER", "http://127.0.0.1:1")
+			code, _, stderr := runCLI(t, nil, tt.args...)
+			if code != tt.wantCode || !strings.Contains(stderr, tt.wantErr) {
+				t.Fatalf("exit %d, stderr %q; want exit %d mentioning %q", code, stderr, tt.wantCode, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		args       []string
+		wantReason string
+		wantArgs   []string
+	}{
+		{args: []string{"p1", "-reason", "fraud"}, wantReason: "fraud", wantArgs: []string{"p1"}},
+		{args: []string{"-reason", "fraud", "p1"}, wantReason: "fraud", wantArgs: []string{"p1"}},
+		{args: []string{"-reason", "x", "--", "-p1"}, wantReason: "x", wantArgs: []string{"-p1"}},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		reason := fs.String("reason", "", "")
+		got, err := parseFlags(fs, tt.args)
+		if err != nil {
+			t.Fatalf("parseFlags(%q): %v", tt.args, err)
+		}
+		if *reason != tt.wantReason || strings.Join(got, " ") != strings.Join(tt.wantArgs, " ") {
+			t.Errorf("parseFlags(%q) = %q, reason %q; want %q, reason %q", tt.args, got, *reason, tt.wantArgs, tt.wantReason)
+		}
+	}
+}
+
+func TestRunCallsAdminAPI(t *testing.T) {
+	api := &fakeAdminAPI{}
+	code, stdout, stderr := runCLI(t, api, "partners", "suspend", "partner_acme", "-reason", "chargebacks", "-o", "json")
+	if code != exitOK {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if len(api.requests) != 1 || api.requests[0] != "POST /admin/v1/partners/partner_acme/suspend" {
+		t.Fatalf("requests = %q", api.requests)
+	}
+	if api.bodies[0]["reason"] != "chargebacks" {
+		t.Fatalf("request body = %v, want the reason", api.bodies[0])
+	}
+	var partner application.PartnerResponse
+	if err := json.Unmarshal([]byte(stdout), &partner); err != nil || partner.Status != entity.PartnerStatusSuspended {
+		t.Fatalf("stdout %q is not the suspended partner: %v", stdout, err)
+	}
+
+	code, _, stderr = runCLI(t, api, "partners", "get", "partner_missing")
+	if code != exitError || !strings.Contains(stderr, "PARTNER_NOT_FOUND") || !strings.Contains(stderr, "404") {
+		t.Fatalf("exit %d, stderr %q; want exit %d with the API error", code, stderr, exitError)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	created := func(id string, status entity.TransactionStatus) *entity.AuditEntry {
+		entry := entity.NewAuditEntry(entity.AuditActionTransactionCreated, "partner_acme", entity.AuditResourceTransaction, id)
+		entry.Timestamp = now.Add(-time.Hour)
+		entry.After, _ = json.Marshal(map[string]entity.TransactionStatus{"status": status})
+		return entry
+	}
+	transaction := func(id string, status entity.TransactionStatus) *application.TransactionDetailResponse {
+		return &application.TransactionDetailResponse{
+			TransactionID: id, PartnerID: "partner_acme", Amount: 10, Currency: "USD", Status: status,
+			CreatedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		api          *fakeAdminAPI
+		wantCode     int
+		wantFindings []string
+	}{
+		{
+			name: "clean",
+			api: &fakeAdminAPI{
+				transactions: []*application.TransactionDetailResponse{transaction("txn_1", entity.TransactionStatusSuccessful)},
+				audit:        []*entity.AuditEntry{created("txn_1", entity.TransactionStatusSuccessful)},
+				chain:        application.AuditVerification{Valid: true},
+			},
+			wantCode: exitOK,
+		},
+		{
+			name: "findings",
+			api: &fakeAdminAPI{
+				transactions: []*application.TransactionDetailResponse{
+					transaction("txn_stuck", entity.TransactionStatusPending),
+					transaction("txn_unaudited", entity.TransactionStatusSuccessful),
+					transaction("txn_changed", entity.TransactionStatusFailed),
+				},
+				audit: []*entity.AuditEntry{
+					created("txn_stuck", entity.TransactionStatusPending),
+					created("txn_changed", entity.TransactionStatusSuccessful),
+				},
+				chain: application.AuditVerification{Valid: false, BrokenAt: 7, Reason: "hash mismatch"},
+			},
+			wantCode:     exitFindings,
+			wantFindings: []string{"txn_stuck", "txn_unaudited", "txn_changed", "broken at 7"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runCLI(t, tt.api, "reconcile", "-since", "24h")
+			if code != tt.wantCode {
+				t.Fatalf("exit %d, want %d: %s", code, tt.wantCode, stderr)
+			}
+			for _, finding := range tt.wantFindings {
+				if !strings.Contains(stdout, finding) {
+					t.Errorf("report does not mention %q:\n%s", finding, stdout)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+)
+
+func (c *cli) partnersList(ctx context.Context, args []string) error {
+	fs := c.flagSet("partners list")
+	status := fs.String("status", "", "only partners with this status")
+	limit := fs.Int("limit", 0, "page size")
+	after := fs.String("after", "", "continue after this partner ID")
+	if _, err := parseCommand(fs, args, 0, ""); err != nil {
+		return err
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	setQuery(query, "status", *status)
+	setQuery(query, "after", *after)
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+
+	var resp application.PartnerListResponse
+	if err := api.get(ctx, "/partners", query, &resp); err != nil {
+		return err
+	}
+	if err := c.print(resp, func() *table { return partnerTable(resp.Partners) }); err != nil {
+		return err
+	}
+	if resp.NextAfter != "" && c.output == "table" {
+		c.note("more partners: -after %s", resp.NextAfter)
+	}
+	return nil
+}
+
+func (c *cli) partnersGet(ctx context.Context, args []string) error {
+	fs := c.flagSet("partners get")
+	positional, err := parseCommand(fs, args, 1, "a partner ID")
+	if err != nil {
+		return err
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	var partner application.PartnerResponse
+	if err := api.get(ctx, "/partners/"+url.PathEscape(positional[0]), nil, &partner); err != nil {
+		return err
+	}
+	return c.print(partner, func() *table { return partnerFields(&partner) })
+}
+
+func (c *cli) partnersCreate(ctx context.Context, args []string) error {
+	fs := c.flagSet("partners create")
+	name := fs.String("name", "", "partner name")
+	clientID := fs.String("client-id", "", "client ID; generated when empty")
+	wallet := fs.String("wallet", "", "wallet ID credited by purchases")
+	scopes := fs.String("scopes", "", "comma-separated scopes")
+	authModes := fs.String("auth-modes", "", "comma-separated auth modes (default BEARER)")
+	transactionTypes := fs.String("transaction-types", "", "comma-separated allowed transaction types (default all)")
+	if _, err := parseCommand(fs, args, 0, ""); err != nil {
+		return err
+	}
+	if *name == "" || *wallet == "" || *scopes == "" {
+		return usageError("partners create needs -name, -wallet and -scopes")
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	body := map[string]any{
+		"name":     *name,
+		"walletId": *wallet,
+		"scopes":   splitList(*scopes),
+	}
+	if *clientID != "" {
+		body["clientId"] = *clientID
+	}
+	if modes := splitList(*authModes); len(modes) > 0 {
+		body["authModes"] = modes
+	}
+	if types := splitList(*transactionTypes); len(types) > 0 {
+		body["allowedTransactionTypes"] = types
+	}
+
+	var resp application.CreatePartnerResponse
+	if err := api.post(ctx, "/partners", body, &resp); err != nil {
+		return err
+	}
+	if err := c.print(resp, func() *table {
+		return fields(
+			"id", resp.Partner.ID,
+			"name", resp.Partner.Name,
+			"status", string(resp.Partner.Status),
+			"clientId", resp.Credentials.ClientID,
+			"secretId", resp.Credentials.SecretID,
+			"clientSecret", resp.Credentials.ClientSecret,
+			"signingKeyId", orDash(resp.Credentials.SigningKeyID),
+			"signingSecret", orDash(resp.Credentials.SigningSecret),
+		)
+	}); err != nil {
+		return err
+	}
+	c.note("store these credentials now: the secrets cannot be retrieved again")
+	return nil
+}
+
+func (c *cli) partnersSuspend(ctx context.Context, args []string) error {
+	return c.partnersChangeStatus(ctx, "suspend", args)
+}
+
+func (c *cli) partnersReactivate(ctx context.Context, args []string) error {
+	return c.partnersChangeStatus(ctx, "reactivate", args)
+}
+
+func (c *cli) partnersChangeStatus(ctx context.Context, action string, args []string) error {
+	fs := c.flagSet("partners " + action)
+	reason := fs.String("reason", "", "reason recorded in the audit log")
+	positional, err := parseCommand(fs, args, 1, "a partner ID")
+	if err != nil {
+		return err
+	}
+	if *reason == "" {
+		return usageError("partners %s needs -reason", action)
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	var partner application.PartnerResponse
+	path := "/partners/" + url.PathEscape(positional[0]) + "/" + action
+	if err := api.post(ctx, path, map[string]string{"reason": *reason}, &partner); err != nil {
+		return err
+	}
+	return c.print(partner, func() *table { return partnerFields(&partner) })
+}
+
+func (c *cli) partnersRotateSecret(ctx context.Context, args []string) error {
+	fs := c.flagSet("partners rotate-secret")
+	grace := fs.Duration("grace", -1, "how long existing secrets stay valid (default: the server's grace period)")
+	positional, err := parseCommand(fs, args, 1, "a partner ID")
+	if err != nil {
+		return err
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	body := map[string]any{}
+	if *grace >= 0 {
+		body["gracePeriodSeconds"] = int64(*grace / time.Second)
+	}
+
+	var resp application.RotateSecretResponse
+	path := "/partners/" + url.PathEscape(positional[0]) + "/credentials/secrets"
+	if err := api.post(ctx, path, body, &resp); err != nil {
+		return err
+	}
+	if err := c.print(resp, func() *table {
+		return fields(
+			"clientId", resp.ClientID,
+			"secretId", resp.SecretID,
+			"clientSecret", resp.ClientSecret,
+			"createdAt", resp.CreatedAt,
+			"previousSecretsExpireAt", resp.PreviousSecretsExpireAt,
+		)
+	}); err != nil {
+		return err
+	}
+	c.note("store the new secret now: it cannot be retrieved again")
+	return nil
+}
+
+func partnerTable(partners []*application.PartnerResponse) *table {
+	t := &table{header: []string{"ID", "NAME", "CLIENT ID", "STATUS", "WALLET", "AUTH MODES", "CREATED"}}
+	for _, p := range partners {
+		t.add(p.ID, p.Name, p.ClientID, string(p.Status), orDash(p.WalletID), joinOrDash(p.AuthModes), p.CreatedAt)
+	}
+	return t
+}
+
+func partnerFields(p *application.PartnerResponse) *table {
+	transactionTypes := joinOrDash(p.AllowedTransactionTypes)
+	if len(p.AllowedTransactionTypes) == 0 {
+		transactionTypes = "all"
+	}
+	return fields(
+		"id", p.ID,
+		"name", p.Name,
+		"clientId", p.ClientID,
+		"status", string(p.Status),
+		"walletId", orDash(p.WalletID),
+		"scopes", joinOrDash(p.Scopes),
+		"authModes", joinOrDash(p.AuthModes),
+		"transactionTypes", transactionTypes,
+		"allowedCidrs", joinOrDash(p.AllowedCIDRs),
+		"activeClientSecrets", strconv.Itoa(p.ActiveClientSecrets),
+		"createdAt", p.CreatedAt,
+		"updatedAt", p.UpdatedAt,
+	)
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+// reconcileReport compares the transactions created in a window with the
+// audit log. Totals summarise the window; the remaining lists are findings.
+type reconcileReport struct {
+	Since        time.Time                     `json:"since"`
+	Until        time.Time                     `json:"until"`
+	Transactions int                           `json:"transactions"`
+	Totals       []*reconcileTotal             `json:"totals"`
+	Stuck        []stuckTransaction            `json:"stuck"`
+	MissingAudit []string                      `json:"missingAudit"`
+	Mismatches   []statusMismatch              `json:"statusMismatches"`
+	AuditChain   application.AuditVerification `json:"auditChain"`
+}
+
+// reconcileTotal sums one partner's transactions in one currency.
+type reconcileTotal struct {
+	PartnerID  string  `json:"partnerId"`
+	Currency   string  `json:"currency"`
+	Count      int     `json:"count"`
+	Successful float64 `json:"successful"`
+	Pending    float64 `json:"pending"`
+	Held       float64 `json:"held"`
+	Failed     float64 `json:"failed"`
+}
+
+type stuckTransaction struct {
+	TransactionID string    `json:"transactionId"`
+	PartnerID     string    `json:"partnerId"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// statusMismatch is a transaction whose stored status differs from the
+// status its latest audit entry recorded.
+type statusMismatch struct {
+	TransactionID string                   `json:"transactionId"`
+	Status        entity.TransactionStatus `json:"status"`
+	AuditedStatus entity.TransactionStatus `json:"auditedStatus"`
+}
+
+func (r *reconcileReport) findings() int {
+	n := len(r.Stuck) + len(r.MissingAudit) + len(r.Mismatches)
+	if !r.AuditChain.Valid {
+		n++
+	}
+	return n
+}
+
+func (c *cli) reconcile(ctx context.Context, args []string) error {
+	fs := c.flagSet("reconcile")
+	filter := transactionQuery{since: "24h"}
+	filter.register(fs, false)
+	stale := fs.Duration("stale", 15*time.Minute, "report PENDING transactions older than this as stuck")
+	if _, err := parseCommand(fs, args, 0, ""); err != nil {
+		return err
+	}
+	if *stale <= 0 {
+		return usageError("-stale must be positive")
+	}
+
+	// Anything that changes after the run starts is left out of the
+	// comparison so in-flight transactions are not reported.
+	started := time.Now()
+	if filter.until == "" {
+		filter.until = started.Format(time.RFC3339Nano)
+	}
+	query, err := filter.values(started)
+	if err != nil {
+		return err
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	report := &reconcileReport{
+		Totals:       []*reconcileTotal{},
+		Stuck:        []stuckTransaction{},
+		MissingAudit: []string{},
+		Mismatches:   []statusMismatch{},
+	}
+	report.Since, _ = time.Parse(time.RFC3339, query.Get("since"))
+	report.Until, _ = time.Parse(time.RFC3339, query.Get("until"))
+
+	var transactions []*application.TransactionDetailResponse
+	totals := make(map[[2]string]*reconcileTotal)
+	err = listAllTransactions(ctx, api, query, func(txn *application.TransactionDetailResponse) error {
+		transactions = append(transactions, txn)
+
+		key := [2]string{txn.PartnerID, txn.Currency}
+		total, ok := totals[key]
+		if !ok {
+			total = &reconcileTotal{PartnerID: txn.PartnerID, Currency: txn.Currency}
+			totals[key] = total
+			report.Totals = append(report.Totals, total)
+		}
+		total.Count++
+		switch txn.Status {
+		case entity.TransactionStatusSuccessful:
+			total.Successful += txn.Amount
+		case entity.TransactionStatusPending:
+			total.Pending += txn.Amount
+			if started.Sub(txn.CreatedAt) > *stale {
+				report.Stuck = append(report.Stuck, stuckTransaction{
+					TransactionID: txn.TransactionID,
+					PartnerID:     txn.PartnerID,
+					Amount:        txn.Amount,
+					Currency:      txn.Currency,
+					CreatedAt:     txn.CreatedAt,
+				})
+			}
+		case entity.TransactionStatusHeldForReview:
+			total.Held += txn.Amount
+		case entity.TransactionStatusFailed:
+			total.Failed += txn.Amount
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.Transactions = len(transactions)
+	sort.Slice(report.Totals, func(i, j int) bool {
+		a, b := report.Totals[i], report.Totals[j]
+		if a.PartnerID != b.PartnerID {
+			return a.PartnerID < b.PartnerID
+		}
+		return a.Currency < b.Currency
+	})
+
+	audited, err := auditedTransactions(ctx, api, report.Since, started)
+	if err != nil {
+		return err
+	}
+	for _, txn := range transactions {
+		state, ok := audited[txn.TransactionID]
+		if !ok || !state.created {
+			report.MissingAudit = append(report.MissingAudit, txn.TransactionID)
+			continue
+		}
+		if txn.UpdatedAt.After(started) {
+			continue
+		}
+		if state.status != txn.Status {
+			report.Mismatches = append(report.Mismatches, statusMismatch{
+				TransactionID: txn.TransactionID,
+				Status:        txn.Status,
+				AuditedStatus: state.status,
+			})
+		}
+	}
+
+	if err := api.get(ctx, "/audit/verify", nil, &report.AuditChain); err != nil {
+		return err
+	}
+
+	if err := c.print(report, func() *table { return reconcileTotalsTable(report) }); err != nil {
+		return err
+	}
+	if c.output == "table" {
+		fmt.Fprintln(c.stdout)
+		if err := writeTable(c.stdout, reconcileFindingsTable(report)); err != nil {
+			return err
+		}
+	}
+	if n := report.findings(); n > 0 {
+		return fmt.Errorf("%w: %d", errFindings, n)
+	}
+	return nil
+}
+
+type auditedTransaction struct {
+	created bool
+	status  entity.TransactionStatus
+}
+
+// auditedTransactions replays the transaction audit entries recorded between
+// since and before, returning each transaction's last audited status.
+func auditedTransactions(ctx context.Context, api *client, since, before time.Time) (map[string]*auditedTransaction, error) {
+	query := url.Values{}
+	query.Set("resourceType", entity.AuditResourceTransaction)
+	query.Set("limit", strconv.Itoa(application.MaxAuditQueryLimit))
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339Nano))
+	}
+
+	audited := make(map[string]*auditedTransaction)
+	err := listAllAudit(ctx, api, query, func(entry *entity.AuditEntry) error {
+		if !entry.Timestamp.Before(before) {
+			return nil
+		}
+		state, ok := audited[entry.ResourceID]
+		if !ok {
+			state = &auditedTransaction{}
+			audited[entry.ResourceID] = state
+		}
+		if entry.Action == entity.AuditActionTransactionCreated {
+			state.created = true
+		}
+		var after struct {
+			Status entity.TransactionStatus `json:"status"`
+		}
+		if len(entry.After) > 0 && json.Unmarshal(entry.After, &after) == nil && after.Status != "" {
+			state.status = after.Status
+		}
+		return nil
+	})
+	return audited, err
+}
+
+func reconcileTotalsTable(r *reconcileReport) *table {
+	t := &table{header: []string{"PARTNER", "CURRENCY", "COUNT", "SUCCESSFUL", "PENDING", "HELD", "FAILED"}}
+	for _, total := range r.Totals {
+		t.add(total.PartnerID, total.Currency, strconv.Itoa(total.Count),
+			formatAmount(total.Successful), formatAmount(total.Pending),
+			formatAmount(total.Held), formatAmount(total.Failed))
+	}
+	return t
+}
+
+func reconcileFindingsTable(r *reconcileReport) *table {
+	t := &table{header: []string{"FINDING", "TRANSACTION", "DETAIL"}}
+	for _, s := range r.Stuck {
+		t.add("stuck", s.TransactionID, "PENDING since "+formatTime(s.CreatedAt))
+	}
+	for _, id := range r.MissingAudit {
+		t.add("missing audit", id, "no TRANSACTION_CREATED entry")
+	}
+	for _, m := range r.Mismatches {
+		t.add("status mismatch", m.TransactionID, fmt.Sprintf("%s, audited %s", m.Status, orDash(string(m.AuditedStatus))))
+	}
+	if !r.AuditChain.Valid {
+		t.add("audit chain", "-", fmt.Sprintf("broken at %d: %s", r.AuditChain.BrokenAt, r.AuditChain.Reason))
+	}
+	if r.findings() == 0 {
+		t.add("none", "-", fmt.Sprintf("%d transactions reconciled", r.Transactions))
+	}
+	return t
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+)
+
+// transactionQuery holds the filter flags shared by transactions list,
+// reconcile and export transactions.
+type transactionQuery struct {
+	partner string
+	status  string
+	since   string
+	until   string
+}
+
+func (q *transactionQuery) register(fs *flag.FlagSet, withStatus bool) {
+	fs.StringVar(&q.partner, "partner", "", "only this partner's transactions")
+	if withStatus {
+		fs.StringVar(&q.status, "status", "", "only transactions with this status")
+	}
+	fs.StringVar(&q.since, "since", q.since, "created at or after this time")
+	fs.StringVar(&q.until, "until", "", "created before this time")
+}
+
+func (q *transactionQuery) values(now time.Time) (url.Values, error) {
+	query := url.Values{}
+	setQuery(query, "partnerId", q.partner)
+	setQuery(query, "status", q.status)
+	for name, value := range map[string]string{"since": q.since, "until": q.until} {
+		t, err := parseTime(name, value, now)
+		if err != nil {
+			return nil, err
+		}
+		if !t.IsZero() {
+			query.Set(name, t.UTC().Format(time.RFC3339Nano))
+		}
+	}
+	return query, nil
+}
+
+func (c *cli) transactionsList(ctx context.Context, args []string) error {
+	fs := c.flagSet("transactions list")
+	var filter transactionQuery
+	filter.register(fs, true)
+	limit := fs.Int("limit", 0, "page size")
+	after := fs.String("after", "", "continue after this transaction ID")
+	if _, err := parseCommand(fs, args, 0, ""); err != nil {
+		return err
+	}
+	query, err := filter.values(time.Now())
+	if err != nil {
+		return err
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	setQuery(query, "after", *after)
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+
+	var resp application.TransactionListResponse
+	if err := api.get(ctx, "/transactions", query, &resp); err != nil {
+		return err
+	}
+	if err := c.print(resp, func() *table { return transactionTable(resp.Transactions) }); err != nil {
+		return err
+	}
+	if resp.NextAfter != "" && c.output == "table" {
+		c.note("more transactions: -after %s", resp.NextAfter)
+	}
+	return nil
+}
+
+func (c *cli) transactionsGet(ctx context.Context, args []string) error {
+	fs := c.flagSet("transactions get")
+	positional, err := parseCommand(fs, args, 1, "a transaction ID")
+	if err != nil {
+		return err
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	var txn application.TransactionDetailResponse
+	if err := api.get(ctx, "/transactions/"+url.PathEscape(positional[0]), nil, &txn); err != nil {
+		return err
+	}
+	if err := c.print(txn, func() *table { return transactionFields(&txn) }); err != nil {
+		return err
+	}
+	if c.output == "table" && len(txn.AuditTrail) > 0 {
+		fmt.Fprintln(c.stdout)
+		return writeTable(c.stdout, auditTable(txn.AuditTrail))
+	}
+	return nil
+}
+
+// transactionsFail force-fails a transaction stuck in PENDING. The server
+// releases its spending allowance and records the reason in the audit log.
+func (c *cli) transactionsFail(ctx context.Context, args []string) error {
+	fs := c.flagSet("transactions fail")
+	reason := fs.String("reason", "", "reason recorded in the audit log")
+	positional, err := parseCommand(fs, args, 1, "a transaction ID")
+	if err != nil {
+		return err
+	}
+	if *reason == "" {
+		return usageError("transactions fail needs -reason")
+	}
+	api, err := c.api()
+	if err != nil {
+		return err
+	}
+
+	var txn application.TransactionDetailResponse
+	path := "/transactions/" + url.PathEscape(positional[0]) + "/fail"
+	if err := api.post(ctx, path, map[string]string{"reason": *reason}, &txn); err != nil {
+		return err
+	}
+	return c.print(txn, func() *table { return transactionFields(&txn) })
+}
+
+// listAllTransactions pages through every transaction matching query.
+func listAllTransactions(ctx context.Context, api *client, query url.Values, each func(*application.TransactionDetailResponse) error) error {
+	query.Set("limit", strconv.Itoa(application.MaxTransactionListLimit))
+	for {
+		var resp application.TransactionListResponse
+		if err := api.get(ctx, "/transactions", query, &resp); err != nil {
+			return err
+		}
+		for _, txn := range resp.Transactions {
+			if err := each(txn); err != nil {
+				return err
+			}
+		}
+		if resp.NextAfter == "" {
+			return nil
+		}
+		query.Set("after", resp.NextAfter)
+	}
+}
+
+func transactionTable(transactions []*application.TransactionDetailResponse) *table {
+	t := &table{header: []string{"ID", "PARTNER", "STATUS", "AMOUNT", "CURRENCY", "USER", "CREATED"}}
+	for _, txn := range transactions {
+		t.add(txn.TransactionID, txn.PartnerID, string(txn.Status), formatAmount(txn.Amount), txn.Currency, txn.UserID, formatTime(txn.CreatedAt))
+	}
+	return t
+}
+
+func transactionFields(txn *application.TransactionDetailResponse) *table {
+	t := fields(
+		"transactionId", txn.TransactionID,
+		"partnerId", txn.PartnerID,
+		"userId", txn.UserID,
+		"walletId", orDash(txn.WalletID),
+		"amount", formatAmount(txn.Amount),
+		"currency", txn.Currency,
+		"status", string(txn.Status),
+		"provider", orDash(txn.Metadata.Provider),
+		"productId", orDash(txn.Metadata.ProductID),
+		"createdAt", formatTime(txn.CreatedAt),
+		"updatedAt", formatTime(txn.UpdatedAt),
+	)
+	if txn.Risk != nil {
+		t.add("riskDecision", string(txn.Risk.Decision))
+	}
+	if txn.Review != nil {
+		t.add("review", string(txn.Review.Outcome)+" by "+txn.Review.Reviewer+": "+txn.Review.Reason)
+	}
+	if rec := txn.Recovery; rec != nil {
+		t.add("recoveryChecks", fmt.Sprintf("%d, last %s at %s", rec.Attempts, rec.LastOutcome, formatTime(rec.LastAttemptAt)))
+		if rec.EscalatedAt != nil {
+			t.add("escalatedAt", formatTime(*rec.EscalatedAt))
+		}
+	}
+	return t
+}
@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+)
+
+const (
+	DefaultAuditQueryLimit = 100
+	MaxAuditQueryLimit     = 1000
+)
+
+type actorContextKey struct{}
+
+type actor struct {
+	name string
+	ip   string
+}
+
+// WithActor records who is making the current request and from which
+// address, for audit entries written while handling it. Names are prefixed
+// by kind, e.g. "partner:partner_bella" or "admin:ops".
+func WithActor(ctx context.Context, name, ip string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor{name: name, ip: ip})
+}
+
+func actorFromContext(ctx context.Context) actor {
+	a, _ := ctx.Value(actorContextKey{}).(actor)
+	return a
+}
+
+// PartnerActor, ClientActor and AdminActor build actor names. A client actor
+// is a caller that has not authenticated as a partner yet.
+func PartnerActor(partnerID string) string { return "partner:" + partnerID }
+func ClientActor(clientID string) string   { return "client:" + clientID }
+func AdminActor(name string) string        { return "admin:" + name }
+
+// AuditLog writes security and money-moving events to the append-only audit
+// repository and answers queries against it.
+type AuditLog struct {
+	auditRepo repository.AuditRepository
+}
+
+type AuditQueryResponse struct {
+	Entries []*entity.AuditEntry `json:"entries"`
+	// NextAfter is the cursor for the next page, or zero on the last page.
+	NextAfter int64 `json:"nextAfter,omitempty"`
+}
+
+// AuditVerification reports whether the hash chain is intact. BrokenAt is
+// the sequence number of the first entry that fails verification.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries"`
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func NewAuditLog(auditRepo repository.AuditRepository) *AuditLog {
+	return &AuditLog{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record appends entry. The actor and IP default to those of the current
+// request, and to the system actor outside a request.
+func (l *AuditLog) Record(ctx context.Context, entry *entity.AuditEntry) error {
+	a := actorFromContext(ctx)
+	if entry.Actor == "" {
+		entry.Actor = a.name
+	}
+	if entry.Actor == "" {
+		entry.Actor = entity.AuditActorSystem
+	}
+	if entry.IP == "" {
+		entry.IP = a.ip
+	}
+
+	if err := l.auditRepo.Append(ctx, entry); err != nil {
+		logging.FromContext(ctx).Error("audit append failed", "action", string(entry.Action), "error", err)
+		return err
+	}
+	return nil
+}
+
+// RecordCommitted appends entry for a change that has already been saved.
+// The change stands whether or not the entry is written, so failing the
+// caller would report a stored change as failed and invite a retry that
+// repeats it. Instead a failure is logged at error level with the whole
+// entry, so it can be restored from the logs.
+func (l *AuditLog) RecordCommitted(ctx context.Context, entry *entity.AuditEntry) {
+	if err := l.Record(ctx, entry); err != nil {
+		logging.FromContext(ctx).Error("AUDIT ENTRY LOST: change was saved but not audited",
+			"action", string(entry.Action),
+			"actor", entry.Actor,
+			"resource_type", entry.ResourceType,
+			"resource_id", entry.ResourceID,
+			"entry", entry,
+			"error", err,
+		)
+	}
+}
+
+func (l *AuditLog) FindByResource(ctx context.Context, resourceType, resourceID string) ([]*entity.AuditEntry, error) {
+	return l.auditRepo.FindByResource(ctx, resourceType, resourceID)
+}
+
+// Query returns one page of matching entries in sequence order.
+func (l *AuditLog) Query(ctx context.Context, filter repository.AuditFilter) (*AuditQueryResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultAuditQueryLimit
+	}
+	if filter.Limit > MaxAuditQueryLimit {
+		filter.Limit = MaxAuditQueryLimit
+	}
+
+	entries, err := l.auditRepo.Query(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &AuditQueryResponse{Entries: entries}
+	if len(entries) == filter.Limit {
+		resp.NextAfter = entries[len(entries)-1].Sequence
+	}
+	return resp, nil
+}
+
+// Check reports whether the audit store is reachable. Changes saved while it
+// is not are only logged; see RecordCommitted.
+func (l *AuditLog) Check(ctx context.Context) error {
+	_, err := l.auditRepo.Query(ctx, repository.AuditFilter{Limit: 1})
+	return err
+}
+
+// Verify walks the whole chain and recomputes every hash.
+func (l *AuditLog) Verify(ctx context.Context) (*AuditVerification, error) {
+	entries, err := l.auditRepo.Query(ctx, repository.AuditFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	prevHash := ""
+	for i, entry := range entries {
+		reason := ""
+		switch {
+		case entry.Sequence != int64(i+1):
+			reason = fmt.Sprintf("expected sequence %d", i+1)
+		case entry.PrevHash != prevHash:
+			reason = "previous hash does not match"
+		case entry.Hash != entry.ComputeHash():
+			reason = "entry content does not match its hash"
+		}
+		if reason != "" {
+			return &AuditVerification{Entries: len(entries), BrokenAt: int64(i + 1), Reason: reason}, nil
+		}
+		prevHash = entry.Hash
+	}
+	return &AuditVerification{Valid: true, Entries: len(entries)}, nil
+}
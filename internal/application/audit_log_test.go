@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+// storedAuditRepository serves a fixed chain, which tests may have tampered
+// with.
+type storedAuditRepository struct {
+	repository.AuditRepository
+	entries []*entity.AuditEntry
+}
+
+func (r storedAuditRepository) Query(context.Context, repository.AuditFilter) ([]*entity.AuditEntry, error) {
+	return r.entries, nil
+}
+
+// sealedChain appends n entries to an in-memory store and returns them.
+func sealedChain(t *testing.T, n int) []*entity.AuditEntry {
+	t.Helper()
+	ctx := context.Background()
+
+	audit := NewAuditLog(inmemory.NewInMemoryAuditRepository())
+	for i := 0; i < n; i++ {
+		entry := entity.NewAuditEntry(entity.AuditActionTransactionCreated, "", entity.AuditResourceTransaction, fmt.Sprintf("txn_%d", i))
+		entry.SetChange(nil, map[string]float64{"amount": float64(10 * (i + 1))})
+		if err := audit.Record(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := audit.Query(ctx, repository.AuditFilter{Limit: MaxAuditQueryLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Entries
+}
+
+func TestAuditLogVerify(t *testing.T) {
+	tests := []struct {
+		name         string
+		tamper       func([]*entity.AuditEntry) []*entity.AuditEntry
+		wantValid    bool
+		wantBrokenAt int64
+		wantReason   string
+	}{
+		{
+			name:      "intact chain",
+			tamper:    func(e []*entity.AuditEntry) []*entity.AuditEntry { return e },
+			wantValid: true,
+		},
+		{
+			name: "edited details",
+			tamper: func(e []*entity.AuditEntry) []*entity.AuditEntry {
+				e[1].Actor = "admin:someone-else"
+				return e
+			},
+			wantBrokenAt: 2,
+			wantReason:   "entry content does not match its hash",
+		},
+		{
+			name: "edited and resealed",
+			tamper: func(e []*entity.AuditEntry) []*entity.AuditEntry {
+				e[1].Timestamp = e[1].Timestamp.Add(-time.Hour)
+				e[1].Hash = e[1].ComputeHash()
+				return e
+			},
+			wantBrokenAt: 3,
+			wantReason:   "previous hash does not match",
+		},
+		{
+			name: "removed entry",
+			tamper: func(e []*entity.AuditEntry) []*entity.AuditEntry {
+				return append(e[:1], e[2:]...)
+			},
+			wantBrokenAt: 2,
+			wantReason:   "expected sequence 2",
+		},
+		{
+			name: "truncated tail",
+			tamper: func(e []*entity.AuditEntry) []*entity.AuditEntry {
+				return e[:2]
+			},
+			wantValid: true,
+		},
+		{
+			name: "reordered entries",
+			tamper: func(e []*entity.AuditEntry) []*entity.AuditEntry {
+				e[1], e[2] = e[2], e[1]
+				return e
+			},
+			wantBrokenAt: 2,
+			wantReason:   "expected sequence 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := tt.tamper(sealedChain(t, 3))
+			audit := NewAuditLog(storedAuditRepository{entries: entries})
+
+			got, err := audit.Verify(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Valid != tt.wantValid || got.BrokenAt != tt.wantBrokenAt || got.Reason != tt.wantReason {
+				t.Fatalf("Verify() = %+v, want valid %v, brokenAt %d, reason %q", got, tt.wantValid, tt.wantBrokenAt, tt.wantReason)
+			}
+		})
+	}
+}
@@ -3,50 +3,502 @@ package application
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
 	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeRefreshToken      = "refresh_token"
+	TokenTypeBearer            = "Bearer"
+
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+
+	DefaultTokenTTL        = 1 * time.Hour
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	refreshTokenPrefix = "rt_"
+)
+
+var (
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrUnsupportedGrantType = errors.New("unsupported grant type")
+	ErrInvalidGrant         = errors.New("invalid grant")
+	ErrInvalidScope         = errors.New("invalid scope")
+	ErrAuthModeNotAllowed   = errors.New("partner does not accept bearer tokens")
+	ErrIPNotAllowed         = errors.New("source IP is not allowed for partner")
+	ErrAuthBusy             = errors.New("authentication is temporarily unavailable")
 )
 
 type AuthUseCase struct {
-	partnerRepo repository.PartnerRepository
-	jwtService  *auth.JWTService
+	partnerRepo      repository.PartnerRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+	jwtService       *auth.JWTService
+	loginGuard       *LoginGuard
+	events           SecurityEventPublisher
+	auditLog         *AuditLog
+	tokenTTL         time.Duration
+	refreshTokenTTL  time.Duration
+}
+
+// AuthConfig sets token lifetimes. Zero values fall back to DefaultTokenTTL
+// and DefaultRefreshTokenTTL.
+type AuthConfig struct {
+	TokenTTL        time.Duration
+	RefreshTokenTTL time.Duration
 }
 
+// ClientInfo describes the connection a request arrived on. The certificate
+// fields are set only when a verified mutual TLS client certificate was
+// presented.
+type ClientInfo struct {
+	IP             string
+	CertThumbprint string
+	CertSubject    string
+}
+
+// AuthRequest is a token request. An empty GrantType is treated as
+// client_credentials so legacy integrations keep working.
 type AuthRequest struct {
-	APIKey    string `json:"apiKey"`
-	APISecret string `json:"apiSecret"`
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Scope        string
+	RefreshToken string
+	Client       ClientInfo
 }
 
 type AuthResponse struct {
-	AccessToken string `json:"accessToken"`
+	AccessToken  string `json:"accessToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	Scope        string `json:"scope,omitempty"`
 }
 
-func NewAuthUseCase(partnerRepo repository.PartnerRepository, jwtService *auth.JWTService) *AuthUseCase {
+// RevokeRequest follows RFC 7009: the client authenticates and names the
+// token to revoke, optionally hinting whether it is an access or refresh token.
+type RevokeRequest struct {
+	ClientID      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+	Client        ClientInfo
+}
+
+func NewAuthUseCase(
+	partnerRepo repository.PartnerRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	jwtService *auth.JWTService,
+	loginGuard *LoginGuard,
+	events SecurityEventPublisher,
+	auditLog *AuditLog,
+	config AuthConfig,
+) *AuthUseCase {
+	if config.TokenTTL <= 0 {
+		config.TokenTTL = DefaultTokenTTL
+	}
+	if config.RefreshTokenTTL <= 0 {
+		config.RefreshTokenTTL = DefaultRefreshTokenTTL
+	}
 	return &AuthUseCase{
-		partnerRepo: partnerRepo,
-		jwtService:  jwtService,
+		partnerRepo:      partnerRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		jwtService:       jwtService,
+		loginGuard:       loginGuard,
+		events:           events,
+		auditLog:         auditLog,
+		tokenTTL:         config.TokenTTL,
+		refreshTokenTTL:  config.RefreshTokenTTL,
+	}
+}
+
+func (uc *AuthUseCase) Authenticate(ctx context.Context, req AuthRequest) (_ *AuthResponse, err error) {
+	ctx, span := startSpan(ctx, "AuthUseCase.Authenticate",
+		attribute.String("client.id", req.ClientID),
+		attribute.String("oauth.grant_type", req.GrantType),
+	)
+	defer func() { endSpan(span, err) }()
+
+	switch req.GrantType {
+	case "", GrantTypeClientCredentials:
+	case GrantTypeRefreshToken:
+		return uc.Refresh(ctx, req)
+	default:
+		return nil, ErrUnsupportedGrantType
+	}
+
+	partner, err := uc.authenticateClient(ctx, req.ClientID, req.ClientSecret, req.Client)
+	if err != nil {
+		return nil, err
+	}
+	ctx = WithActor(ctx, PartnerActor(partner.ID), req.Client.IP)
+
+	if err := partner.CheckActive(); err != nil {
+		return nil, err
+	}
+
+	if !partner.AcceptsAuthMode(entity.AuthModeBearer) {
+		return nil, ErrAuthModeNotAllowed
+	}
+
+	scope, err := resolveScope(partner, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.issueTokens(ctx, partner, scope, uuid.New().String(), req.Client.CertThumbprint, GrantTypeClientCredentials)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Presenting a token that was already exchanged or revoked is treated
+// as theft: the whole token family and its access tokens are revoked.
+func (uc *AuthUseCase) Refresh(ctx context.Context, req AuthRequest) (_ *AuthResponse, err error) {
+	ctx, span := startSpan(ctx, "AuthUseCase.Refresh", attribute.String("client.id", req.ClientID))
+	defer func() { endSpan(span, err) }()
+
+	partner, err := uc.authenticateClient(ctx, req.ClientID, req.ClientSecret, req.Client)
+	if err != nil {
+		return nil, err
+	}
+	ctx = WithActor(ctx, PartnerActor(partner.ID), req.Client.IP)
+
+	if err := partner.CheckActive(); err != nil {
+		return nil, err
+	}
+
+	if !partner.AcceptsAuthMode(entity.AuthModeBearer) {
+		return nil, ErrAuthModeNotAllowed
+	}
+
+	if req.RefreshToken == "" {
+		return nil, ErrInvalidGrant
+	}
+
+	stored, err := uc.refreshTokenRepo.FindByHash(ctx, auth.HashOpaqueToken(req.RefreshToken))
+	if err != nil {
+		return nil, ErrInvalidGrant
+	}
+
+	if stored.ClientID != partner.ClientID {
+		return nil, ErrInvalidGrant
+	}
+
+	now := time.Now()
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		if err := uc.revokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidGrant
+	}
+
+	if !stored.IsActive(now) {
+		return nil, ErrInvalidGrant
+	}
+
+	if err := uc.refreshTokenRepo.MarkUsed(ctx, stored.ID, now); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			if err := uc.revokeFamily(ctx, stored.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, ErrInvalidGrant
+		}
+		return nil, err
+	}
+
+	// A refresh may narrow the original scope but never widen it. Scopes
+	// withdrawn from the partner since the original grant are dropped.
+	original := entity.ParseScope(stored.Scope)
+	scopes := original
+	if requested := entity.ParseScope(req.Scope); len(requested) > 0 {
+		for _, s := range requested {
+			if !containsScope(original, s) {
+				return nil, ErrInvalidScope
+			}
+		}
+		scopes = requested
+	}
+
+	var still []string
+	for _, s := range scopes {
+		if partner.HasScope(s) {
+			still = append(still, s)
+		}
+	}
+
+	return uc.issueTokens(ctx, partner, entity.FormatScope(still), stored.FamilyID, req.Client.CertThumbprint, GrantTypeRefreshToken)
+}
+
+// Revoke invalidates an access or refresh token owned by the authenticated
+// client. Unknown tokens are ignored, as required by RFC 7009. Partners that
+// are not active may still revoke their own tokens.
+func (uc *AuthUseCase) Revoke(ctx context.Context, req RevokeRequest) (err error) {
+	ctx, span := startSpan(ctx, "AuthUseCase.Revoke", attribute.String("client.id", req.ClientID))
+	defer func() { endSpan(span, err) }()
+
+	partner, err := uc.authenticateClient(ctx, req.ClientID, req.ClientSecret, req.Client)
+	if err != nil {
+		return err
+	}
+	ctx = WithActor(ctx, PartnerActor(partner.ID), req.Client.IP)
+
+	if req.TokenTypeHint == TokenTypeHintRefreshToken {
+		if found, err := uc.revokeRefreshToken(ctx, partner, req.Token); found || err != nil {
+			return err
+		}
+		_, err := uc.revokeAccessToken(ctx, partner, req.Token)
+		return err
 	}
+
+	if found, err := uc.revokeAccessToken(ctx, partner, req.Token); found || err != nil {
+		return err
+	}
+	_, err = uc.revokeRefreshToken(ctx, partner, req.Token)
+	return err
 }
 
-func (uc *AuthUseCase) Authenticate(ctx context.Context, req AuthRequest) (*AuthResponse, error) {
-	partner, err := uc.partnerRepo.FindByClientID(ctx, req.APIKey)
+// authenticateClient verifies the client secret only; callers decide whether
+// the partner's status permits the operation. Attempts are throttled by the
+// login guard per client ID and source IP pair, per client ID, and per
+// source IP. Partners bound to a client certificate must also present a
+// matching one, and partners with an IP allowlist must call from an allowed
+// address.
+func (uc *AuthUseCase) authenticateClient(ctx context.Context, clientID, clientSecret string, client ClientInfo) (partner *entity.Partner, err error) {
+	defer func() {
+		if err != nil && !isVerificationUnavailable(err) {
+			uc.recordAuthFailure(ctx, clientID, client.IP, err)
+		}
+	}()
+
+	if err := uc.loginGuard.Check(ctx, clientID, client.IP); err != nil {
+		return nil, err
+	}
+
+	partner, err = uc.verifyClientSecret(ctx, clientID, clientSecret)
+	if err == nil && !partner.MatchesClientCertificate(client.CertThumbprint, client.CertSubject) {
+		err = ErrInvalidCredentials
+	}
+	if isVerificationUnavailable(err) {
+		return nil, err
+	}
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		if guardErr := uc.loginGuard.RecordFailure(ctx, clientID, client.IP); guardErr != nil {
+			return nil, guardErr
+		}
+		return nil, err
 	}
 
-	if partner.ClientSecret != req.APISecret {
-		return nil, errors.New("invalid credentials")
+	if err := uc.loginGuard.RecordSuccess(ctx, clientID, client.IP); err != nil {
+		return nil, err
+	}
+
+	if !partner.AllowsIP(client.IP) {
+		event := entity.NewSecurityEvent(entity.SecurityEventIPNotAllowed, "token request from address outside allowlist")
+		event.PartnerID = partner.ID
+		event.ClientID = partner.ClientID
+		event.IP = client.IP
+		uc.events.Publish(ctx, event)
+		return nil, ErrIPNotAllowed
+	}
+
+	return partner, nil
+}
+
+func isVerificationUnavailable(err error) bool {
+	return errors.Is(err, ErrAuthBusy) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// recordAuthFailure audits a rejected client authentication. Failures to
+// write the entry are logged but do not change the response.
+func (uc *AuthUseCase) recordAuthFailure(ctx context.Context, clientID, ip string, cause error) {
+	entry := entity.NewAuditEntry(entity.AuditActionAuthFailed, ClientActor(clientID), entity.AuditResourceClient, clientID)
+	entry.IP = ip
+	entry.Details = map[string]string{"reason": cause.Error()}
+	_ = uc.auditLog.Record(ctx, entry)
+}
+
+// verifyClientSecret costs the same whether or not the client ID exists.
+// Secrets that carry their ID are checked against that one stored hash;
+// older secrets without one are checked against every active secret, padded
+// with throwaway verifications to MaxActiveClientSecrets.
+func (uc *AuthUseCase) verifyClientSecret(ctx context.Context, clientID, clientSecret string) (*entity.Partner, error) {
+	var candidates []entity.ClientSecret
+	partner, err := uc.partnerRepo.FindByClientID(ctx, clientID)
+	if err == nil {
+		candidates = partner.ActiveClientSecrets(time.Now())
+	}
+
+	verifications := MaxActiveClientSecrets
+	if id, ok := auth.ClientSecretID(clientSecret); ok {
+		verifications = 1
+		candidates = slices.DeleteFunc(candidates, func(s entity.ClientSecret) bool { return s.ID != id })
+	}
+
+	matched := false
+	for _, secret := range candidates {
+		ok, err := auth.VerifySecret(ctx, clientSecret, secret.Hash)
+		if err != nil && !errors.Is(err, auth.ErrInvalidHash) {
+			return nil, verificationError(err)
+		}
+		matched = matched || ok
+		verifications--
+	}
+	for ; verifications > 0; verifications-- {
+		if err := auth.BurnVerification(ctx, clientSecret); err != nil {
+			return nil, verificationError(err)
+		}
+	}
+
+	if !matched {
+		return nil, ErrInvalidCredentials
+	}
+	return partner, nil
+}
+
+// verificationError reports a verification that could not run, as opposed
+// to a wrong secret, so it is not counted against the client.
+func verificationError(err error) error {
+	if errors.Is(err, auth.ErrHashingBusy) {
+		return ErrAuthBusy
+	}
+	return err
+}
+
+// issueTokens creates an access and refresh token pair. Tokens requested over
+// mutual TLS are bound to the presented certificate (RFC 8705).
+func (uc *AuthUseCase) issueTokens(ctx context.Context, partner *entity.Partner, scope, familyID, certThumbprint, grantType string) (*AuthResponse, error) {
+	accessToken, claims, err := uc.jwtService.GenerateToken(partner.ID, partner.ClientID, scope, certThumbprint, uc.tokenTTL)
+	if err != nil {
+		return nil, err
 	}
 
-	expiresIn := 1 * time.Hour
-	token, err := uc.jwtService.GenerateToken(partner.ID, partner.ClientID, expiresIn)
+	refreshToken, err := auth.GenerateOpaqueToken(refreshTokenPrefix)
 	if err != nil {
 		return nil, err
 	}
 
+	stored := entity.NewRefreshToken(
+		fmt.Sprintf("rtk_%s", uuid.New().String()),
+		familyID,
+		auth.HashOpaqueToken(refreshToken),
+		partner.ID,
+		partner.ClientID,
+		scope,
+		uc.refreshTokenTTL,
+	)
+	stored.AccessTokenID = claims.ID
+	stored.AccessTokenExpiresAt = claims.ExpiresAt.Time
+
+	if err := uc.refreshTokenRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	entry := entity.NewAuditEntry(entity.AuditActionTokenIssued, "", entity.AuditResourcePartner, partner.ID)
+	entry.Details = map[string]string{
+		"grantType":     grantType,
+		"scope":         scope,
+		"accessTokenId": claims.ID,
+		"familyId":      familyID,
+	}
+	uc.auditLog.RecordCommitted(ctx, entry)
+
 	return &AuthResponse{
-		AccessToken: token,
+		AccessToken:  accessToken,
+		TokenType:    TokenTypeBearer,
+		ExpiresIn:    int64(uc.tokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scope,
 	}, nil
 }
+
+func (uc *AuthUseCase) revokeAccessToken(ctx context.Context, partner *entity.Partner, token string) (bool, error) {
+	claims, err := uc.jwtService.ValidateToken(token)
+	if err != nil || claims.ID == "" || claims.ClientID != partner.ClientID {
+		return false, nil
+	}
+
+	if err := uc.revokedTokenRepo.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return true, err
+	}
+	uc.recordRevocation(ctx, partner, TokenTypeHintAccessToken)
+	return true, nil
+}
+
+// revokeRefreshToken revokes the refresh token and the access token issued
+// alongside it, since both belong to the same grant.
+func (uc *AuthUseCase) revokeRefreshToken(ctx context.Context, partner *entity.Partner, token string) (bool, error) {
+	stored, err := uc.refreshTokenRepo.FindByHash(ctx, auth.HashOpaqueToken(token))
+	if err != nil || stored.ClientID != partner.ClientID {
+		return false, nil
+	}
+
+	if err := uc.revokeFamily(ctx, stored.FamilyID); err != nil {
+		return true, err
+	}
+	uc.recordRevocation(ctx, partner, TokenTypeHintRefreshToken)
+	return true, nil
+}
+
+func (uc *AuthUseCase) recordRevocation(ctx context.Context, partner *entity.Partner, tokenType string) {
+	entry := entity.NewAuditEntry(entity.AuditActionTokenRevoked, "", entity.AuditResourcePartner, partner.ID)
+	entry.Details = map[string]string{"tokenType": tokenType}
+	uc.auditLog.RecordCommitted(ctx, entry)
+}
+
+func (uc *AuthUseCase) revokeFamily(ctx context.Context, familyID string) error {
+	family, err := uc.refreshTokenRepo.FindByFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range family {
+		token.Revoke()
+		if err := uc.refreshTokenRepo.Update(ctx, token); err != nil {
+			return err
+		}
+		if token.AccessTokenID != "" {
+			if err := uc.revokedTokenRepo.Revoke(ctx, token.AccessTokenID, token.AccessTokenExpiresAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveScope validates a requested scope string against the partner's
+// grants. An empty request yields every scope the partner holds.
+func resolveScope(partner *entity.Partner, requested string) (string, error) {
+	scopes := entity.ParseScope(requested)
+	if len(scopes) == 0 {
+		return entity.FormatScope(partner.Scopes), nil
+	}
+
+	for _, s := range scopes {
+		if !entity.IsKnownScope(s) || !partner.HasScope(s) {
+			return "", ErrInvalidScope
+		}
+	}
+
+	return entity.FormatScope(scopes), nil
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
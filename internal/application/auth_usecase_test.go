@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+type recordingEvents struct {
+	mu     sync.Mutex
+	events []*entity.SecurityEvent
+}
+
+func (r *recordingEvents) Publish(ctx context.Context, event *entity.SecurityEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+type authTestEnv struct {
+	uc       *AuthUseCase
+	partners repository.PartnerRepository
+	audit    repository.AuditRepository
+	events   *recordingEvents
+}
+
+// newAuthTestEnv builds an AuthUseCase on in-memory repositories. The guard
+// has no failure delay so tests do not sleep.
+func newAuthTestEnv(t *testing.T, guard LoginGuardConfig) *authTestEnv {
+	t.Helper()
+
+	partners, err := inmemory.NewInMemoryPartnerRepository(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("partner repository: %v", err)
+	}
+	keys, err := auth.NewKeyManager(auth.KeyManagerConfig{Algorithm: auth.AlgorithmES256, GracePeriod: time.Hour})
+	if err != nil {
+		t.Fatalf("key manager: %v", err)
+	}
+
+	env := &authTestEnv{
+		partners: partners,
+		audit:    inmemory.NewInMemoryAuditRepository(),
+		events:   &recordingEvents{},
+	}
+	guard.BaseDelay = 0
+	env.uc = NewAuthUseCase(
+		partners,
+		inmemory.NewInMemoryRefreshTokenRepository(),
+		inmemory.NewInMemoryRevokedTokenRepository(),
+		auth.NewJWTService(keys),
+		NewLoginGuard(inmemory.NewInMemoryLoginAttemptRepository(), env.events, guard),
+		env.events,
+		NewAuditLog(env.audit),
+		AuthConfig{},
+	)
+	return env
+}
+
+// addPartner stores an active partner holding the given client secrets.
+// Secrets that carry an ID are stored under it; others get a generated ID.
+func (env *authTestEnv) addPartner(t *testing.T, clientID string, secrets ...string) *entity.Partner {
+	t.Helper()
+
+	partner := entity.NewPartner("partner_"+clientID, clientID, clientID, "wlt_"+clientID)
+	partner.Scopes = append([]string(nil), entity.AllScopes...)
+	partner.AuthModes = []entity.AuthMode{entity.AuthModeBearer}
+	for i, secret := range secrets {
+		hash, err := auth.HashSecret(secret)
+		if err != nil {
+			t.Fatalf("hash secret: %v", err)
+		}
+		id, ok := auth.ClientSecretID(secret)
+		if !ok {
+			id = fmt.Sprintf("sec_legacy_%d", i)
+		}
+		partner.AddClientSecret(entity.ClientSecret{ID: id, Hash: hash, CreatedAt: time.Now()})
+	}
+	if err := env.partners.Create(context.Background(), partner); err != nil {
+		t.Fatalf("create partner: %v", err)
+	}
+	return partner
+}
+
+func (env *authTestEnv) authenticate(clientID, secret, ip string) (*AuthResponse, error) {
+	return env.uc.Authenticate(context.Background(), AuthRequest{
+		GrantType:    GrantTypeClientCredentials,
+		ClientID:     clientID,
+		ClientSecret: secret,
+		Client:       ClientInfo{IP: ip},
+	})
+}
+
+func TestAuthenticateClientSecret(t *testing.T) {
+	env := newAuthTestEnv(t, DefaultLoginGuardConfig())
+
+	current, err := auth.GenerateClientSecret("sec_current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := auth.GenerateClientSecret("sec_other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.addPartner(t, "acme", current, "legacy_secret_value")
+
+	tests := []struct {
+		name     string
+		clientID string
+		secret   string
+		wantErr  error
+	}{
+		{name: "secret with ID", clientID: "acme", secret: current},
+		{name: "secret without ID", clientID: "acme", secret: "legacy_secret_value"},
+		{name: "wrong secret for known ID", clientID: "acme", secret: current + "x", wantErr: ErrInvalidCredentials},
+		{name: "secret naming unknown ID", clientID: "acme", secret: other, wantErr: ErrInvalidCredentials},
+		{name: "wrong secret without ID", clientID: "acme", secret: "guess", wantErr: ErrInvalidCredentials},
+		{name: "unknown client", clientID: "nobody", secret: current, wantErr: ErrInvalidCredentials},
+		{name: "unknown client without ID", clientID: "nobody", secret: "guess", wantErr: ErrInvalidCredentials},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A distinct IP per case keeps failures from earlier cases out
+			// of the way.
+			resp, err := env.authenticate(tt.clientID, tt.secret, fmt.Sprintf("198.51.100.%d", i+1))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && resp.AccessToken == "" {
+				t.Fatal("no access token issued")
+			}
+		})
+	}
+}
+
+func TestAuthenticateExpiredSecret(t *testing.T) {
+	env := newAuthTestEnv(t, DefaultLoginGuardConfig())
+
+	secret, err := auth.GenerateClientSecret("sec_old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partner := env.addPartner(t, "acme", secret)
+	partner.ExpireClientSecret("sec_old", time.Now().Add(-time.Minute))
+	if err := env.partners.Update(context.Background(), partner); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.authenticate("acme", secret, "198.51.100.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("error = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestAuthFailuresAudited(t *testing.T) {
+	env := newAuthTestEnv(t, DefaultLoginGuardConfig())
+	env.addPartner(t, "acme", "legacy_secret_value")
+
+	// Every rejection is recorded, however many arrive together, including
+	// those refused by the lockout without checking the secret.
+	const failures = 80
+	for i := 0; i < failures; i++ {
+		if _, err := env.authenticate("acme", "guess", "198.51.100.1"); err == nil {
+			t.Fatalf("attempt %d: wrong secret accepted", i+1)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := env.uc.Authenticate(ctx, AuthRequest{
+		GrantType:    GrantTypeClientCredentials,
+		ClientID:     "acme",
+		ClientSecret: "legacy_secret_value",
+		Client:       ClientInfo{IP: "203.0.113.1"},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled request: err = %v, want context.Canceled", err)
+	}
+
+	entries, err := env.audit.Query(context.Background(), repository.AuditFilter{Action: entity.AuditActionAuthFailed, ResourceID: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cancelled request was never checked, so it is not audited.
+	if len(entries) != failures {
+		t.Fatalf("%d AUTH_FAILED entries, want %d", len(entries), failures)
+	}
+	for _, entry := range entries {
+		if entry.Details["reason"] == "" || entry.IP == "" {
+			t.Fatalf("entry %+v lacks the reason or source IP", entry)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+)
+
+const (
+	// MaxActiveClientSecrets bounds how many secrets a partner may hold at once.
+	MaxActiveClientSecrets = 3
+
+	DefaultSecretRotationGracePeriod = 24 * time.Hour
+	MaxSecretRotationGracePeriod     = 30 * 24 * time.Hour
+)
+
+var (
+	ErrPartnerNotFound    = errors.New("partner not found")
+	ErrSecretNotFound     = errors.New("client secret not found")
+	ErrTooManySecrets     = errors.New("too many active client secrets")
+	ErrLastSecret         = errors.New("cannot revoke the only active client secret")
+	ErrInvalidGracePeriod = errors.New("invalid grace period")
+)
+
+// CredentialUseCase manages partner client secrets.
+type CredentialUseCase struct {
+	partnerRepo repository.PartnerRepository
+	auditLog    *AuditLog
+}
+
+type RotateSecretRequest struct {
+	// GracePeriod is how long existing secrets stay valid after rotation.
+	// Nil uses DefaultSecretRotationGracePeriod; zero expires them immediately.
+	GracePeriod *time.Duration
+}
+
+// RotateSecretResponse carries the plaintext secret. It is only ever returned
+// here; the service stores nothing but its hash.
+type RotateSecretResponse struct {
+	ClientID                string `json:"clientId"`
+	SecretID                string `json:"secretId"`
+	ClientSecret            string `json:"clientSecret"`
+	CreatedAt               string `json:"createdAt"`
+	PreviousSecretsExpireAt string `json:"previousSecretsExpireAt"`
+}
+
+type ClientSecretResponse struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"createdAt"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+func NewCredentialUseCase(partnerRepo repository.PartnerRepository, auditLog *AuditLog) *CredentialUseCase {
+	return &CredentialUseCase{
+		partnerRepo: partnerRepo,
+		auditLog:    auditLog,
+	}
+}
+
+// RotateSecret issues a new client secret and schedules every existing secret
+// to expire after the grace period, so integrations can switch over without
+// downtime.
+func (uc *CredentialUseCase) RotateSecret(ctx context.Context, partnerID string, req RotateSecretRequest) (*RotateSecretResponse, error) {
+	grace := DefaultSecretRotationGracePeriod
+	if req.GracePeriod != nil {
+		grace = *req.GracePeriod
+	}
+	if grace < 0 || grace > MaxSecretRotationGracePeriod {
+		return nil, ErrInvalidGracePeriod
+	}
+
+	// Checked again below; this spares the hash for a partner that cannot
+	// take another secret.
+	current, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+	if len(current.ActiveClientSecrets(time.Now())) >= MaxActiveClientSecrets {
+		return nil, ErrTooManySecrets
+	}
+
+	// Hashing is slow, so it happens before the partner is read for update
+	// to keep the window for a conflicting change short.
+	secretID := fmt.Sprintf("sec_%s", uuid.New().String()[:8])
+	secret, err := auth.GenerateClientSecret(secretID)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := auth.HashSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	var created entity.ClientSecret
+	var expireAt time.Time
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		now := time.Now()
+		active := partner.ActiveClientSecrets(now)
+		if len(active) >= MaxActiveClientSecrets {
+			return ErrTooManySecrets
+		}
+
+		expireAt = now.Add(grace)
+		for _, existing := range active {
+			partner.ExpireClientSecret(existing.ID, expireAt)
+		}
+		created = entity.ClientSecret{
+			ID:        secretID,
+			Hash:      hash,
+			CreatedAt: now,
+		}
+		partner.AddClientSecret(created)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entry := entity.NewAuditEntry(entity.AuditActionCredentialRotated, "", entity.AuditResourcePartner, partner.ID)
+	entry.Details = map[string]string{
+		"secretId":                created.ID,
+		"previousSecretsExpireAt": expireAt.Format(time.RFC3339),
+	}
+	uc.auditLog.RecordCommitted(ctx, entry)
+
+	return &RotateSecretResponse{
+		ClientID:                partner.ClientID,
+		SecretID:                created.ID,
+		ClientSecret:            secret,
+		CreatedAt:               created.CreatedAt.Format(time.RFC3339),
+		PreviousSecretsExpireAt: expireAt.Format(time.RFC3339),
+	}, nil
+}
+
+// ListSecrets returns metadata for the partner's active secrets.
+func (uc *CredentialUseCase) ListSecrets(ctx context.Context, partnerID string) ([]ClientSecretResponse, error) {
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+
+	secrets := []ClientSecretResponse{}
+	for _, s := range partner.ActiveClientSecrets(time.Now()) {
+		secrets = append(secrets, toClientSecretResponse(s))
+	}
+	return secrets, nil
+}
+
+// RevokeSecret expires a secret immediately. The last active secret cannot be
+// revoked, since that would lock the partner out.
+func (uc *CredentialUseCase) RevokeSecret(ctx context.Context, partnerID, secretID string) error {
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		now := time.Now()
+		active := partner.ActiveClientSecrets(now)
+
+		found := false
+		for _, s := range active {
+			if s.ID == secretID {
+				found = true
+			}
+		}
+		if !found {
+			return ErrSecretNotFound
+		}
+		if len(active) == 1 {
+			return ErrLastSecret
+		}
+
+		partner.ExpireClientSecret(secretID, now)
+		partner.PruneClientSecrets(now)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	entry := entity.NewAuditEntry(entity.AuditActionCredentialRevoked, "", entity.AuditResourcePartner, partner.ID)
+	entry.Details = map[string]string{"secretId": secretID}
+	uc.auditLog.RecordCommitted(ctx, entry)
+	return nil
+}
+
+func toClientSecretResponse(s entity.ClientSecret) ClientSecretResponse {
+	resp := ClientSecretResponse{
+		ID:        s.ID,
+		CreatedAt: s.CreatedAt.Format(time.RFC3339),
+	}
+	if s.ExpiresAt != nil {
+		resp.ExpiresAt = s.ExpiresAt.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+// LockoutError is returned while a client ID is locked out for a source IP,
+// or the source IP is locked out entirely, after too many failed
+// authentication attempts.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("too many failed attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+type LoginGuardConfig struct {
+	// MaxClientFailures locks a client ID for one source IP after this many
+	// failures from that IP in Window.
+	MaxClientFailures int
+	// MaxClientWideFailures locks a client ID for every source IP after this
+	// many failures across all IPs in Window, catching guessing spread over
+	// many addresses. It is set well above MaxClientFailures, since reaching
+	// it also locks the partner out of its own servers. Zero disables it.
+	MaxClientWideFailures int
+	// MaxIPFailures locks a source IP after this many failures in Window.
+	MaxIPFailures   int
+	Window          time.Duration
+	LockoutDuration time.Duration
+	// BaseDelay is the delay after the first failure; each further failure
+	// doubles it, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func DefaultLoginGuardConfig() LoginGuardConfig {
+	return LoginGuardConfig{
+		MaxClientFailures:     5,
+		MaxClientWideFailures: 100,
+		MaxIPFailures:         20,
+		Window:                15 * time.Minute,
+		LockoutDuration:       15 * time.Minute,
+		BaseDelay:             250 * time.Millisecond,
+		MaxDelay:              4 * time.Second,
+	}
+}
+
+// LoginGuard throttles credential guessing on the token endpoint. Failures
+// are counted per client ID and source IP pair, per client ID, and per
+// source IP, whether or not the client ID exists, so lockouts do not reveal
+// which client IDs are valid. A client ID is locked for every address only
+// at the much higher client-wide threshold, so a few guesses from someone
+// who knows a partner's client ID cannot lock the partner out of its own
+// servers, while guessing spread across many addresses is still stopped.
+type LoginGuard struct {
+	attemptRepo repository.LoginAttemptRepository
+	events      SecurityEventPublisher
+	config      LoginGuardConfig
+}
+
+func NewLoginGuard(attemptRepo repository.LoginAttemptRepository, events SecurityEventPublisher, config LoginGuardConfig) *LoginGuard {
+	return &LoginGuard{
+		attemptRepo: attemptRepo,
+		events:      events,
+		config:      config,
+	}
+}
+
+// Check returns a *LockoutError if the client ID is locked for this IP or
+// everywhere, or the IP is locked.
+func (g *LoginGuard) Check(ctx context.Context, clientID, ip string) error {
+	now := time.Now()
+	for _, key := range g.keys(clientID, ip) {
+		until, err := g.attemptRepo.LockedUntil(ctx, key)
+		if err != nil {
+			return err
+		}
+		if until.After(now) {
+			return &LockoutError{RetryAfter: until.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed attempt, locks keys that crossed their
+// threshold, and waits a progressively longer delay before returning so that
+// each guess costs the caller time.
+func (g *LoginGuard) RecordFailure(ctx context.Context, clientID, ip string) error {
+	worst := 0
+
+	if clientID != "" {
+		count, err := g.attemptRepo.RecordFailure(ctx, clientKey(clientID, ip), g.config.Window)
+		if err != nil {
+			return err
+		}
+		worst = count
+		if count >= g.config.MaxClientFailures {
+			if err := g.lock(ctx, clientKey(clientID, ip), clientID, ip, "client", count); err != nil {
+				return err
+			}
+		}
+	}
+
+	if clientID != "" && g.config.MaxClientWideFailures > 0 {
+		count, err := g.attemptRepo.RecordFailure(ctx, clientWideKey(clientID), g.config.Window)
+		if err != nil {
+			return err
+		}
+		if count >= g.config.MaxClientWideFailures {
+			if err := g.lock(ctx, clientWideKey(clientID), clientID, ip, "client_wide", count); err != nil {
+				return err
+			}
+		}
+	}
+
+	if ip != "" {
+		count, err := g.attemptRepo.RecordFailure(ctx, ipKey(ip), g.config.Window)
+		if err != nil {
+			return err
+		}
+		if count >= g.config.MaxIPFailures {
+			if err := g.lock(ctx, ipKey(ip), clientID, ip, "ip", count); err != nil {
+				return err
+			}
+		}
+	}
+
+	g.delay(ctx, worst)
+	return nil
+}
+
+// RecordSuccess clears the client ID's failure history for this IP. IP and
+// client-wide counters are left alone so one valid credential cannot launder
+// guesses against others, nor a partner's own logins those from elsewhere.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, clientID, ip string) error {
+	return g.attemptRepo.Reset(ctx, clientKey(clientID, ip))
+}
+
+func (g *LoginGuard) lock(ctx context.Context, key, clientID, ip, scope string, failures int) error {
+	until := time.Now().Add(g.config.LockoutDuration)
+	if err := g.attemptRepo.Lock(ctx, key, until); err != nil {
+		return err
+	}
+
+	event := entity.NewSecurityEvent(entity.SecurityEventLoginLockout, "too many failed authentication attempts")
+	event.ClientID = clientID
+	event.IP = ip
+	event.Details = map[string]string{
+		"scope":       scope,
+		"failures":    fmt.Sprint(failures),
+		"lockedUntil": until.UTC().Format(time.RFC3339),
+	}
+	g.events.Publish(ctx, event)
+	return nil
+}
+
+func (g *LoginGuard) delay(ctx context.Context, failures int) {
+	if failures <= 0 || g.config.BaseDelay <= 0 {
+		return
+	}
+
+	d := g.config.BaseDelay
+	for i := 1; i < failures && d < g.config.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > g.config.MaxDelay {
+		d = g.config.MaxDelay
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func (g *LoginGuard) keys(clientID, ip string) []string {
+	var keys []string
+	if clientID != "" {
+		keys = append(keys, clientKey(clientID, ip))
+		if g.config.MaxClientWideFailures > 0 {
+			keys = append(keys, clientWideKey(clientID))
+		}
+	}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	return keys
+}
+
+func clientKey(clientID, ip string) string {
+	return "client:" + clientID + "@" + ip
+}
+
+func clientWideKey(clientID string) string {
+	return "client:" + clientID
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
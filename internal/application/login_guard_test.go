@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+func TestLoginGuardLockoutIsPerSourceIP(t *testing.T) {
+	env := newAuthTestEnv(t, DefaultLoginGuardConfig())
+	secret, err := auth.GenerateClientSecret("sec_acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.addPartner(t, "acme", secret)
+
+	const attacker, partner = "203.0.113.66", "198.51.100.10"
+	for i := 0; i < DefaultLoginGuardConfig().MaxClientFailures; i++ {
+		if _, err := env.authenticate("acme", "wrong", attacker); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: error = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+
+	var lockout *LockoutError
+	if _, err := env.authenticate("acme", secret, attacker); !errors.As(err, &lockout) {
+		t.Fatalf("attacker after lockout: error = %v, want *LockoutError", err)
+	}
+	if _, err := env.authenticate("acme", secret, partner); err != nil {
+		t.Fatalf("partner from its own address: %v", err)
+	}
+}
+
+func TestLoginGuard(t *testing.T) {
+	config := DefaultLoginGuardConfig()
+	config.BaseDelay = 0
+
+	tests := []struct {
+		name string
+		// failures lists the client ID and IP of each failed attempt.
+		failures  [][2]string
+		clientID  string
+		ip        string
+		wantLock  bool
+		wantScope string
+	}{
+		{
+			name:     "below client threshold",
+			failures: repeat("acme", "192.0.2.1", config.MaxClientFailures-1),
+			clientID: "acme", ip: "192.0.2.1",
+		},
+		{
+			name:     "client locked for failing IP",
+			failures: repeat("acme", "192.0.2.1", config.MaxClientFailures),
+			clientID: "acme", ip: "192.0.2.1",
+			wantLock: true, wantScope: "client",
+		},
+		{
+			name:     "client not locked for other IP",
+			failures: repeat("acme", "192.0.2.1", config.MaxClientFailures),
+			clientID: "acme", ip: "192.0.2.2",
+			wantScope: "client",
+		},
+		{
+			name:     "below client-wide threshold",
+			failures: spreadIPs("acme", config.MaxClientWideFailures-1),
+			clientID: "acme", ip: "198.51.100.1",
+		},
+		{
+			name:     "client locked everywhere after failures across IPs",
+			failures: spreadIPs("acme", config.MaxClientWideFailures),
+			clientID: "acme", ip: "198.51.100.1",
+			wantLock: true, wantScope: "client_wide",
+		},
+		{
+			name:     "client-wide lock spares other client IDs",
+			failures: spreadIPs("acme", config.MaxClientWideFailures),
+			clientID: "other", ip: "198.51.100.1",
+			wantScope: "client_wide",
+		},
+		{
+			name:     "IP locked across client IDs",
+			failures: spread("192.0.2.1", config.MaxIPFailures),
+			clientID: "fresh", ip: "192.0.2.1",
+			wantLock: true, wantScope: "ip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &recordingEvents{}
+			guard := NewLoginGuard(inmemory.NewInMemoryLoginAttemptRepository(), events, config)
+			ctx := context.Background()
+
+			for _, f := range tt.failures {
+				if err := guard.RecordFailure(ctx, f[0], f[1]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := guard.Check(ctx, tt.clientID, tt.ip)
+			var lockout *LockoutError
+			if locked := errors.As(err, &lockout); locked != tt.wantLock {
+				t.Fatalf("Check error = %v, want locked %v", err, tt.wantLock)
+			}
+			if tt.wantScope != "" {
+				if len(events.events) == 0 || events.events[0].Details["scope"] != tt.wantScope {
+					t.Fatalf("lockout events = %+v, want scope %q", events.events, tt.wantScope)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginGuardClientWideCountSurvivesSuccess(t *testing.T) {
+	config := DefaultLoginGuardConfig()
+	config.BaseDelay = 0
+	guard := NewLoginGuard(inmemory.NewInMemoryLoginAttemptRepository(), &recordingEvents{}, config)
+	ctx := context.Background()
+
+	failures := spreadIPs("acme", config.MaxClientWideFailures)
+	for _, f := range failures[:len(failures)-1] {
+		if err := guard.RecordFailure(ctx, f[0], f[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The partner logging in from its own server does not wipe the count
+	// built up elsewhere.
+	if err := guard.RecordSuccess(ctx, "acme", "198.51.100.10"); err != nil {
+		t.Fatal(err)
+	}
+	last := failures[len(failures)-1]
+	if err := guard.RecordFailure(ctx, last[0], last[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	var lockout *LockoutError
+	if err := guard.Check(ctx, "acme", "198.51.100.10"); !errors.As(err, &lockout) {
+		t.Fatalf("Check error = %v, want *LockoutError", err)
+	}
+}
+
+func TestLoginGuardSuccessClearsOnlyThatIP(t *testing.T) {
+	config := DefaultLoginGuardConfig()
+	config.BaseDelay = 0
+	guard := NewLoginGuard(inmemory.NewInMemoryLoginAttemptRepository(), &recordingEvents{}, config)
+	ctx := context.Background()
+
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2"} {
+		for i := 0; i < config.MaxClientFailures-1; i++ {
+			if err := guard.RecordFailure(ctx, "acme", ip); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := guard.RecordSuccess(ctx, "acme", "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// One more failure locks only the address whose history was kept.
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2"} {
+		if err := guard.RecordFailure(ctx, "acme", ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := guard.Check(ctx, "acme", "192.0.2.1"); err != nil {
+		t.Fatalf("cleared IP: %v", err)
+	}
+	if err := guard.Check(ctx, "acme", "192.0.2.2"); err == nil {
+		t.Fatal("IP with kept history was not locked")
+	}
+}
+
+func repeat(clientID, ip string, n int) [][2]string {
+	out := make([][2]string, n)
+	for i := range out {
+		out[i] = [2]string{clientID, ip}
+	}
+	return out
+}
+
+// spreadIPs returns n failures against clientID, each from a different IP.
+func spreadIPs(clientID string, n int) [][2]string {
+	out := make([][2]string, n)
+	for i := range out {
+		out[i] = [2]string{clientID, fmt.Sprintf("10.0.%d.%d", i/256, i%256)}
+	}
+	return out
+}
+
+// spread returns n failures from ip, each against a different client ID.
+func spread(ip string, n int) [][2]string {
+	out := make([][2]string, n)
+	for i := range out {
+		out[i] = [2]string{fmt.Sprintf("client%d", i), ip}
+	}
+	return out
+}
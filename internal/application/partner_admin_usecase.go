@@ -0,0 +1,777 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+)
+
+const (
+	// MaxAllowedCIDRs bounds the size of a partner's IP allowlist.
+	MaxAllowedCIDRs = 50
+
+	// MaxClientCertificates bounds the thumbprints and subjects a partner
+	// may be bound to.
+	MaxClientCertificates = 20
+
+	// MaxRateLimitOverride bounds per-minute rate limit overrides.
+	MaxRateLimitOverride = 100000
+
+	DefaultPartnerListLimit = 50
+	MaxPartnerListLimit     = 200
+
+	maxPartnerNameLength = 100
+	maxWalletIDLength    = 64
+
+	maxCertificateSubjectLength = 512
+)
+
+var (
+	ErrInvalidCIDR          = errors.New("invalid CIDR range")
+	ErrInvalidCertificate   = errors.New("invalid client certificate binding")
+	ErrInvalidRateLimit     = errors.New("invalid rate limit")
+	ErrInvalidSpendingLimit = errors.New("invalid spending limit")
+	ErrInvalidPartner       = errors.New("invalid partner")
+	ErrPartnerExists        = errors.New("partner already exists")
+	ErrInvalidPartnerStatus = errors.New("invalid partner status change")
+)
+
+var clientIDPattern = regexp.MustCompile(`^[a-z0-9_-]{3,64}$`)
+
+// PartnerAdminUseCase implements operator-facing partner management.
+type PartnerAdminUseCase struct {
+	partnerRepo repository.PartnerRepository
+	auditLog    *AuditLog
+}
+
+type AllowedIPsResponse struct {
+	PartnerID    string   `json:"partnerId"`
+	AllowedCIDRs []string `json:"allowedCidrs"`
+}
+
+// ClientCertificatesResponse lists the certificates a partner is bound to. A
+// presented certificate matches if either its thumbprint or its subject DN
+// is listed.
+type ClientCertificatesResponse struct {
+	PartnerID   string   `json:"partnerId"`
+	Thumbprints []string `json:"clientCertThumbprints"`
+	Subjects    []string `json:"clientCertSubjects"`
+}
+
+// RateLimitsResponse lists the partner's effective per-minute limit for every
+// route group alongside the overrides that produce it.
+type RateLimitsResponse struct {
+	PartnerID     string                        `json:"partnerId"`
+	Overrides     map[entity.RateLimitGroup]int `json:"overrides"`
+	Effective     map[entity.RateLimitGroup]int `json:"effective"`
+	WindowSeconds int64                         `json:"windowSeconds"`
+}
+
+func NewPartnerAdminUseCase(partnerRepo repository.PartnerRepository, auditLog *AuditLog) *PartnerAdminUseCase {
+	return &PartnerAdminUseCase{
+		partnerRepo: partnerRepo,
+		auditLog:    auditLog,
+	}
+}
+
+// CreatePartnerRequest describes a new partner. ClientID is generated when
+// empty; AuthModes defaults to bearer tokens and AllowedTransactionTypes to
+// every type.
+type CreatePartnerRequest struct {
+	Name                    string
+	ClientID                string
+	WalletID                string
+	Scopes                  []string
+	AuthModes               []entity.AuthMode
+	AllowedTransactionTypes []entity.TransactionType
+	SpendingLimits          []entity.SpendingLimit
+}
+
+// UpdatePartnerRequest changes a partner's profile. Nil fields are left
+// unchanged.
+type UpdatePartnerRequest struct {
+	Name      *string
+	Scopes    *[]string
+	AuthModes *[]entity.AuthMode
+}
+
+type ListPartnersRequest struct {
+	Status entity.PartnerStatus
+	After  string
+	Limit  int
+}
+
+type PartnerResponse struct {
+	ID                      string                        `json:"id"`
+	Name                    string                        `json:"name"`
+	ClientID                string                        `json:"clientId"`
+	WalletID                string                        `json:"walletId"`
+	Status                  entity.PartnerStatus          `json:"status"`
+	Scopes                  []string                      `json:"scopes"`
+	AuthModes               []entity.AuthMode             `json:"authModes"`
+	AllowedTransactionTypes []entity.TransactionType      `json:"allowedTransactionTypes"`
+	AllowedCIDRs            []string                      `json:"allowedCidrs"`
+	RateLimits              map[entity.RateLimitGroup]int `json:"rateLimits"`
+	SpendingLimits          []entity.SpendingLimit        `json:"spendingLimits"`
+	ClientCertThumbprints   []string                      `json:"clientCertThumbprints,omitempty"`
+	ClientCertSubjects      []string                      `json:"clientCertSubjects,omitempty"`
+	ActiveClientSecrets     int                           `json:"activeClientSecrets"`
+	CreatedAt               string                        `json:"createdAt"`
+	UpdatedAt               string                        `json:"updatedAt"`
+}
+
+// PartnerCredentials carries the plaintext credentials issued when a partner
+// is created. They are only ever returned in that response.
+type PartnerCredentials struct {
+	ClientID      string `json:"clientId"`
+	SecretID      string `json:"secretId"`
+	ClientSecret  string `json:"clientSecret"`
+	SigningKeyID  string `json:"signingKeyId,omitempty"`
+	SigningSecret string `json:"signingSecret,omitempty"`
+}
+
+type CreatePartnerResponse struct {
+	Partner     *PartnerResponse   `json:"partner"`
+	Credentials PartnerCredentials `json:"credentials"`
+}
+
+type PartnerListResponse struct {
+	Partners []*PartnerResponse `json:"partners"`
+	// NextAfter is the cursor for the next page; empty on the last page.
+	NextAfter string `json:"nextAfter,omitempty"`
+}
+
+// CreatePartner registers an ACTIVE partner and issues its first client
+// secret, plus a request signing key when HMAC authentication is enabled.
+func (uc *PartnerAdminUseCase) CreatePartner(ctx context.Context, req CreatePartnerRequest) (*CreatePartnerResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxPartnerNameLength {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidPartner, maxPartnerNameLength)
+	}
+	walletID := strings.TrimSpace(req.WalletID)
+	if err := validateWalletID(walletID); err != nil {
+		return nil, err
+	}
+	scopes, err := validateScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	authModes := req.AuthModes
+	if len(authModes) == 0 {
+		authModes = []entity.AuthMode{entity.AuthModeBearer}
+	}
+	if err := validateAuthModes(authModes); err != nil {
+		return nil, err
+	}
+	transactionTypes := req.AllowedTransactionTypes
+	if len(transactionTypes) == 0 {
+		transactionTypes = append([]entity.TransactionType(nil), entity.AllTransactionTypes...)
+	}
+	if err := validateTransactionTypes(transactionTypes); err != nil {
+		return nil, err
+	}
+	spendingLimits, err := normalizeSpendingLimits(req.SpendingLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := req.ClientID
+	if clientID == "" {
+		clientID = fmt.Sprintf("client_%s", strings.ReplaceAll(uuid.New().String(), "-", "")[:16])
+	} else if !clientIDPattern.MatchString(clientID) {
+		return nil, fmt.Errorf("%w: clientId must be 3 to 64 lowercase letters, digits, '_' or '-'", ErrInvalidPartner)
+	}
+
+	partner := entity.NewPartner(fmt.Sprintf("partner_%s", uuid.New().String()[:8]), name, clientID, walletID)
+	partner.Scopes = scopes
+	partner.AuthModes = authModes
+	partner.AllowedTransactionTypes = transactionTypes
+	if len(spendingLimits) > 0 {
+		partner.SpendingLimits = spendingLimits
+	}
+
+	secretID := fmt.Sprintf("sec_%s", uuid.New().String()[:8])
+	secret, err := auth.GenerateClientSecret(secretID)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := auth.HashSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	credentials := PartnerCredentials{
+		ClientID:     clientID,
+		SecretID:     secretID,
+		ClientSecret: secret,
+	}
+	partner.AddClientSecret(entity.ClientSecret{
+		ID:        credentials.SecretID,
+		Hash:      hash,
+		CreatedAt: partner.CreatedAt,
+	})
+
+	if partner.AcceptsAuthMode(entity.AuthModeHMAC) {
+		credentials.SigningKeyID = fmt.Sprintf("sig_%s", uuid.New().String()[:8])
+		if credentials.SigningSecret, err = auth.GenerateSigningSecret(); err != nil {
+			return nil, err
+		}
+		partner.SigningKeys = []entity.RequestSigningKey{{
+			ID:        credentials.SigningKeyID,
+			Secret:    credentials.SigningSecret,
+			CreatedAt: partner.CreatedAt,
+		}}
+	}
+
+	if err := uc.partnerRepo.Create(ctx, partner); err != nil {
+		if errors.Is(err, repository.ErrPartnerExists) {
+			return nil, ErrPartnerExists
+		}
+		return nil, err
+	}
+
+	resp := toPartnerResponse(partner)
+	entry := entity.NewAuditEntry(entity.AuditActionPartnerCreated, "", entity.AuditResourcePartner, partner.ID)
+	entry.Details = map[string]string{"clientId": clientID, "secretId": credentials.SecretID}
+	entry.SetChange(nil, resp)
+	uc.auditLog.RecordCommitted(ctx, entry)
+
+	return &CreatePartnerResponse{Partner: resp, Credentials: credentials}, nil
+}
+
+func (uc *PartnerAdminUseCase) GetPartner(ctx context.Context, partnerID string) (*PartnerResponse, error) {
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+	return toPartnerResponse(partner), nil
+}
+
+// ListPartners returns partners ordered by ID, one page at a time.
+func (uc *PartnerAdminUseCase) ListPartners(ctx context.Context, req ListPartnersRequest) (*PartnerListResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultPartnerListLimit
+	}
+	if limit > MaxPartnerListLimit {
+		limit = MaxPartnerListLimit
+	}
+
+	// One extra partner tells whether another page follows.
+	partners, err := uc.partnerRepo.List(ctx, repository.PartnerFilter{
+		Status:  req.Status,
+		AfterID: req.After,
+		Limit:   limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &PartnerListResponse{Partners: []*PartnerResponse{}}
+	if len(partners) > limit {
+		partners = partners[:limit]
+		resp.NextAfter = partners[limit-1].ID
+	}
+	for _, partner := range partners {
+		resp.Partners = append(resp.Partners, toPartnerResponse(partner))
+	}
+	return resp, nil
+}
+
+// UpdatePartner changes the partner's name, scopes or authentication modes.
+// Access tokens already issued keep their scopes until they expire.
+func (uc *PartnerAdminUseCase) UpdatePartner(ctx context.Context, partnerID string, req UpdatePartnerRequest) (*PartnerResponse, error) {
+	var name string
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > maxPartnerNameLength {
+			return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidPartner, maxPartnerNameLength)
+		}
+	}
+	var scopes []string
+	if req.Scopes != nil {
+		var err error
+		if scopes, err = validateScopes(*req.Scopes); err != nil {
+			return nil, err
+		}
+	}
+	if req.AuthModes != nil {
+		if err := validateAuthModes(*req.AuthModes); err != nil {
+			return nil, err
+		}
+	}
+
+	var before partnerProfile
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		before = toPartnerProfile(partner)
+		if req.Name != nil {
+			partner.Name = name
+		}
+		if req.Scopes != nil {
+			partner.Scopes = scopes
+		}
+		if req.AuthModes != nil {
+			partner.AuthModes = *req.AuthModes
+		}
+		partner.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.recordConfigChange(ctx, partner.ID, "profile", before, toPartnerProfile(partner))
+
+	return toPartnerResponse(partner), nil
+}
+
+// SetWallet assigns the wallet the partner's purchases are funded from.
+func (uc *PartnerAdminUseCase) SetWallet(ctx context.Context, partnerID, walletID string) (*PartnerResponse, error) {
+	walletID = strings.TrimSpace(walletID)
+	if err := validateWalletID(walletID); err != nil {
+		return nil, err
+	}
+
+	var before string
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		before = partner.WalletID
+		partner.WalletID = walletID
+		partner.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.recordConfigChange(ctx, partner.ID, "walletId", before, walletID)
+
+	return toPartnerResponse(partner), nil
+}
+
+// SetAllowedTransactionTypes replaces the transaction types the partner may
+// create.
+func (uc *PartnerAdminUseCase) SetAllowedTransactionTypes(ctx context.Context, partnerID string, types []entity.TransactionType) (*PartnerResponse, error) {
+	if len(types) == 0 {
+		return nil, fmt.Errorf("%w: at least one transaction type is required", ErrInvalidPartner)
+	}
+	if err := validateTransactionTypes(types); err != nil {
+		return nil, err
+	}
+
+	var before []entity.TransactionType
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		before = toPartnerResponse(partner).AllowedTransactionTypes
+		partner.AllowedTransactionTypes = types
+		partner.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.recordConfigChange(ctx, partner.ID, "allowedTransactionTypes", before, types)
+
+	return toPartnerResponse(partner), nil
+}
+
+// SuspendPartner blocks the partner's tokens and signed requests until it is
+// reactivated. Authenticated requests check partner status, so suspension
+// takes effect immediately.
+func (uc *PartnerAdminUseCase) SuspendPartner(ctx context.Context, partnerID, reason string) (*PartnerResponse, error) {
+	return uc.changeStatus(ctx, partnerID, reason, entity.AuditActionPartnerSuspended, (*entity.Partner).Suspend)
+}
+
+// ReactivatePartner returns a suspended, inactive or pending partner to
+// ACTIVE.
+func (uc *PartnerAdminUseCase) ReactivatePartner(ctx context.Context, partnerID, reason string) (*PartnerResponse, error) {
+	return uc.changeStatus(ctx, partnerID, reason, entity.AuditActionPartnerReactivated, (*entity.Partner).Reactivate)
+}
+
+func (uc *PartnerAdminUseCase) changeStatus(ctx context.Context, partnerID, reason string, action entity.AuditAction, transition func(*entity.Partner) error) (*PartnerResponse, error) {
+	var before entity.PartnerStatus
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		before = partner.Status
+		if err := transition(partner); err != nil {
+			return fmt.Errorf("%w: partner is %s", ErrInvalidPartnerStatus, before)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entry := entity.NewAuditEntry(action, "", entity.AuditResourcePartner, partner.ID)
+	entry.Details = map[string]string{"reason": reason}
+	entry.SetChange(map[string]entity.PartnerStatus{"status": before}, map[string]entity.PartnerStatus{"status": partner.Status})
+	uc.auditLog.RecordCommitted(ctx, entry)
+
+	return toPartnerResponse(partner), nil
+}
+
+func toPartnerResponse(partner *entity.Partner) *PartnerResponse {
+	resp := &PartnerResponse{
+		ID:                      partner.ID,
+		Name:                    partner.Name,
+		ClientID:                partner.ClientID,
+		WalletID:                partner.WalletID,
+		Status:                  partner.Status,
+		Scopes:                  append([]string{}, partner.Scopes...),
+		AuthModes:               append([]entity.AuthMode{}, partner.AuthModes...),
+		AllowedTransactionTypes: append([]entity.TransactionType{}, partner.AllowedTransactionTypes...),
+		AllowedCIDRs:            toAllowedIPsResponse(partner).AllowedCIDRs,
+		RateLimits:              toRateLimitsResponse(partner).Overrides,
+		SpendingLimits:          toSpendingLimitsResponse(partner).SpendingLimits,
+		ClientCertThumbprints:   partner.ClientCertThumbprints,
+		ClientCertSubjects:      partner.ClientCertSubjects,
+		ActiveClientSecrets:     len(partner.ActiveClientSecrets(time.Now())),
+		CreatedAt:               partner.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:               partner.UpdatedAt.Format(time.RFC3339),
+	}
+	if len(resp.AllowedTransactionTypes) == 0 {
+		resp.AllowedTransactionTypes = append(resp.AllowedTransactionTypes, entity.AllTransactionTypes...)
+	}
+	return resp
+}
+
+// partnerProfile is the audited view of the fields UpdatePartner changes.
+type partnerProfile struct {
+	Name      string            `json:"name"`
+	Scopes    []string          `json:"scopes"`
+	AuthModes []entity.AuthMode `json:"authModes"`
+}
+
+func toPartnerProfile(partner *entity.Partner) partnerProfile {
+	return partnerProfile{
+		Name:      partner.Name,
+		Scopes:    append([]string{}, partner.Scopes...),
+		AuthModes: append([]entity.AuthMode{}, partner.AuthModes...),
+	}
+}
+
+func validateWalletID(walletID string) error {
+	if walletID == "" || len(walletID) > maxWalletIDLength {
+		return fmt.Errorf("%w: walletId must be 1 to %d characters", ErrInvalidPartner, maxWalletIDLength)
+	}
+	return nil
+}
+
+// validateScopes rejects unknown scopes and returns the list without
+// duplicates.
+func validateScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidPartner)
+	}
+	unique := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		if !entity.IsKnownScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidPartner, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	return unique, nil
+}
+
+func validateAuthModes(modes []entity.AuthMode) error {
+	if len(modes) == 0 {
+		return fmt.Errorf("%w: at least one auth mode is required", ErrInvalidPartner)
+	}
+	for _, mode := range modes {
+		if !entity.IsKnownAuthMode(mode) {
+			return fmt.Errorf("%w: unknown auth mode %q", ErrInvalidPartner, mode)
+		}
+	}
+	return nil
+}
+
+func validateTransactionTypes(types []entity.TransactionType) error {
+	for _, t := range types {
+		if !entity.IsKnownTransactionType(t) {
+			return fmt.Errorf("%w: unknown transaction type %q", ErrInvalidPartner, t)
+		}
+	}
+	return nil
+}
+
+func (uc *PartnerAdminUseCase) GetAllowedIPs(ctx context.Context, partnerID string) (*AllowedIPsResponse, error) {
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+	return toAllowedIPsResponse(partner), nil
+}
+
+// SetAllowedIPs replaces the partner's allowlist. Entries may be CIDR ranges
+// or single addresses and are stored in canonical form; an empty list lifts
+// the restriction.
+func (uc *PartnerAdminUseCase) SetAllowedIPs(ctx context.Context, partnerID string, cidrs []string) (*AllowedIPsResponse, error) {
+	if len(cidrs) > MaxAllowedCIDRs {
+		return nil, fmt.Errorf("%w: at most %d ranges are allowed", ErrInvalidCIDR, MaxAllowedCIDRs)
+	}
+
+	normalized := make([]string, 0, len(cidrs))
+	seen := make(map[string]bool, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := entity.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCIDR, cidr)
+		}
+		if s := prefix.String(); !seen[s] {
+			seen[s] = true
+			normalized = append(normalized, s)
+		}
+	}
+
+	var before []string
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		before = toAllowedIPsResponse(partner).AllowedCIDRs
+		partner.AllowedCIDRs = normalized
+		partner.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.recordConfigChange(ctx, partner.ID, "allowedCidrs", before, normalized)
+
+	return toAllowedIPsResponse(partner), nil
+}
+
+func toAllowedIPsResponse(partner *entity.Partner) *AllowedIPsResponse {
+	cidrs := partner.AllowedCIDRs
+	if cidrs == nil {
+		cidrs = []string{}
+	}
+	return &AllowedIPsResponse{
+		PartnerID:    partner.ID,
+		AllowedCIDRs: cidrs,
+	}
+}
+
+func (uc *PartnerAdminUseCase) GetClientCertificates(ctx context.Context, partnerID string) (*ClientCertificatesResponse, error) {
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+	return toClientCertificatesResponse(partner), nil
+}
+
+// SetClientCertificates replaces the partner's certificate binding.
+// Thumbprints are RFC 8705 x5t#S256 values; subjects are DNs as the server
+// formats them, e.g. "CN=bella,O=Bella Mobile". Empty lists remove the
+// binding, after which tokens can again be obtained without a certificate.
+func (uc *PartnerAdminUseCase) SetClientCertificates(ctx context.Context, partnerID string, thumbprints, subjects []string) (*ClientCertificatesResponse, error) {
+	if len(thumbprints)+len(subjects) > MaxClientCertificates {
+		return nil, fmt.Errorf("%w: at most %d thumbprints and subjects are allowed", ErrInvalidCertificate, MaxClientCertificates)
+	}
+
+	normalizedThumbprints, err := normalizeCertificateEntries(thumbprints, func(t string) error {
+		if raw, err := base64.RawURLEncoding.DecodeString(t); err != nil || len(raw) != sha256.Size {
+			return fmt.Errorf("%w: thumbprint %q is not a base64url SHA-256 digest", ErrInvalidCertificate, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	normalizedSubjects, err := normalizeCertificateEntries(subjects, func(s string) error {
+		if len(s) > maxCertificateSubjectLength || !strings.Contains(s, "=") {
+			return fmt.Errorf("%w: subject %q is not a distinguished name", ErrInvalidCertificate, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var before *ClientCertificatesResponse
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		before = toClientCertificatesResponse(partner)
+		partner.ClientCertThumbprints = normalizedThumbprints
+		partner.ClientCertSubjects = normalizedSubjects
+		partner.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toClientCertificatesResponse(partner)
+	uc.recordConfigChange(ctx, partner.ID, "clientCertificates",
+		clientCertificateBinding{Thumbprints: before.Thumbprints, Subjects: before.Subjects},
+		clientCertificateBinding{Thumbprints: resp.Thumbprints, Subjects: resp.Subjects})
+	return resp, nil
+}
+
+// clientCertificateBinding is the audited view of a certificate binding.
+type clientCertificateBinding struct {
+	Thumbprints []string `json:"clientCertThumbprints"`
+	Subjects    []string `json:"clientCertSubjects"`
+}
+
+// normalizeCertificateEntries trims entries, drops duplicates and checks each
+// with validate. It returns nil for an empty list so the binding is removed.
+func normalizeCertificateEntries(entries []string, validate func(string) error) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if err := validate(entry); err != nil {
+			return nil, err
+		}
+		if !seen[entry] {
+			seen[entry] = true
+			normalized = append(normalized, entry)
+		}
+	}
+	return normalized, nil
+}
+
+func toClientCertificatesResponse(partner *entity.Partner) *ClientCertificatesResponse {
+	return &ClientCertificatesResponse{
+		PartnerID:   partner.ID,
+		Thumbprints: append([]string{}, partner.ClientCertThumbprints...),
+		Subjects:    append([]string{}, partner.ClientCertSubjects...),
+	}
+}
+
+func (uc *PartnerAdminUseCase) GetRateLimits(ctx context.Context, partnerID string) (*RateLimitsResponse, error) {
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+	return toRateLimitsResponse(partner), nil
+}
+
+// SetRateLimits replaces the partner's rate limit overrides. Groups without
+// an override use the defaults.
+func (uc *PartnerAdminUseCase) SetRateLimits(ctx context.Context, partnerID string, overrides map[entity.RateLimitGroup]int) (*RateLimitsResponse, error) {
+	for group, limit := range overrides {
+		if !entity.IsKnownRateLimitGroup(group) {
+			return nil, fmt.Errorf("%w: unknown group %q", ErrInvalidRateLimit, group)
+		}
+		if limit < 1 || limit > MaxRateLimitOverride {
+			return nil, fmt.Errorf("%w: %s must be between 1 and %d", ErrInvalidRateLimit, group, MaxRateLimitOverride)
+		}
+	}
+
+	var before map[entity.RateLimitGroup]int
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		before = toRateLimitsResponse(partner).Overrides
+		partner.RateLimits = nil
+		if len(overrides) > 0 {
+			partner.RateLimits = overrides
+		}
+		partner.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toRateLimitsResponse(partner)
+	uc.recordConfigChange(ctx, partner.ID, "rateLimits", before, resp.Overrides)
+	return resp, nil
+}
+
+func toRateLimitsResponse(partner *entity.Partner) *RateLimitsResponse {
+	resp := &RateLimitsResponse{
+		PartnerID:     partner.ID,
+		Overrides:     make(map[entity.RateLimitGroup]int, len(partner.RateLimits)),
+		Effective:     make(map[entity.RateLimitGroup]int, len(entity.DefaultRateLimits)),
+		WindowSeconds: int64(entity.RateLimitWindow / time.Second),
+	}
+	for group, limit := range partner.RateLimits {
+		resp.Overrides[group] = limit
+	}
+	for group := range entity.DefaultRateLimits {
+		resp.Effective[group] = partner.RateLimit(group)
+	}
+	return resp
+}
+
+type SpendingLimitsResponse struct {
+	PartnerID      string                 `json:"partnerId"`
+	SpendingLimits []entity.SpendingLimit `json:"spendingLimits"`
+}
+
+func (uc *PartnerAdminUseCase) GetSpendingLimits(ctx context.Context, partnerID string) (*SpendingLimitsResponse, error) {
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+	return toSpendingLimitsResponse(partner), nil
+}
+
+// SetSpendingLimits replaces the partner's spending limits, one entry per
+// currency. Usage already recorded in the current periods still counts.
+func (uc *PartnerAdminUseCase) SetSpendingLimits(ctx context.Context, partnerID string, limits []entity.SpendingLimit) (*SpendingLimitsResponse, error) {
+	normalized, err := normalizeSpendingLimits(limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var before []entity.SpendingLimit
+	partner, err := updatePartner(ctx, uc.partnerRepo, partnerID, func(partner *entity.Partner) error {
+		before = toSpendingLimitsResponse(partner).SpendingLimits
+		partner.SpendingLimits = normalized
+		partner.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.recordConfigChange(ctx, partner.ID, "spendingLimits", before, normalized)
+
+	return toSpendingLimitsResponse(partner), nil
+}
+
+// normalizeSpendingLimits upper-cases currencies and rejects duplicates and
+// negative limits.
+func normalizeSpendingLimits(limits []entity.SpendingLimit) ([]entity.SpendingLimit, error) {
+	normalized := make([]entity.SpendingLimit, 0, len(limits))
+	seen := make(map[string]bool, len(limits))
+	for _, limit := range limits {
+		limit.Currency = strings.ToUpper(strings.TrimSpace(limit.Currency))
+		if len(limit.Currency) != 3 {
+			return nil, fmt.Errorf("%w: currency must be a 3-letter code", ErrInvalidSpendingLimit)
+		}
+		if seen[limit.Currency] {
+			return nil, fmt.Errorf("%w: duplicate currency %s", ErrInvalidSpendingLimit, limit.Currency)
+		}
+		if limit.DailyMax < 0 || limit.MonthlyMax < 0 || limit.PerUserDailyMax < 0 {
+			return nil, fmt.Errorf("%w: limits must not be negative", ErrInvalidSpendingLimit)
+		}
+		seen[limit.Currency] = true
+		normalized = append(normalized, limit)
+	}
+	return normalized, nil
+}
+
+func toSpendingLimitsResponse(partner *entity.Partner) *SpendingLimitsResponse {
+	limits := partner.SpendingLimits
+	if limits == nil {
+		limits = []entity.SpendingLimit{}
+	}
+	return &SpendingLimitsResponse{
+		PartnerID:      partner.ID,
+		SpendingLimits: limits,
+	}
+}
+
+// recordConfigChange audits a change to one partner setting.
+func (uc *PartnerAdminUseCase) recordConfigChange(ctx context.Context, partnerID, setting string, before, after any) {
+	entry := entity.NewAuditEntry(entity.AuditActionPartnerConfigChanged, "", entity.AuditResourcePartner, partnerID)
+	entry.Details = map[string]string{"setting": setting}
+	entry.SetChange(before, after)
+	uc.auditLog.RecordCommitted(ctx, entry)
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+const testThumbprint = "bwcK0esc3ACC3DB2Y5_lESsXE8o9ltc05O89jdN-dg2"
+
+func TestSetClientCertificates(t *testing.T) {
+	tests := []struct {
+		name            string
+		thumbprints     []string
+		subjects        []string
+		wantErr         error
+		wantThumbprints []string
+		wantSubjects    []string
+	}{
+		{
+			name:            "thumbprint and subject",
+			thumbprints:     []string{testThumbprint},
+			subjects:        []string{"CN=bella,O=Bella Mobile"},
+			wantThumbprints: []string{testThumbprint},
+			wantSubjects:    []string{"CN=bella,O=Bella Mobile"},
+		},
+		{
+			name:            "trimmed and deduplicated",
+			thumbprints:     []string{" " + testThumbprint, testThumbprint},
+			subjects:        []string{"CN=bella ", "CN=bella"},
+			wantThumbprints: []string{testThumbprint},
+			wantSubjects:    []string{"CN=bella"},
+		},
+		{
+			name:            "empty lists remove the binding",
+			wantThumbprints: []string{},
+			wantSubjects:    []string{},
+		},
+		{name: "hex thumbprint", thumbprints: []string{strings.Repeat("ab", 32)}, wantErr: ErrInvalidCertificate},
+		{name: "SHA-1 thumbprint", thumbprints: []string{"2fd4e1c67a2d28fced849ee1bb76e7391b93eb12"}, wantErr: ErrInvalidCertificate},
+		{name: "padded thumbprint", thumbprints: []string{testThumbprint + "="}, wantErr: ErrInvalidCertificate},
+		{name: "subject without attributes", subjects: []string{"bella"}, wantErr: ErrInvalidCertificate},
+		{name: "empty subject", subjects: []string{""}, wantErr: ErrInvalidCertificate},
+		{name: "too many entries", subjects: make([]string, MaxClientCertificates+1), wantErr: ErrInvalidCertificate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			partners, audit := newPartnerAdminTestRepos(t)
+			admin := NewPartnerAdminUseCase(partners, NewAuditLog(audit))
+
+			resp, err := admin.SetClientCertificates(ctx, "partner_acme", tt.thumbprints, tt.subjects)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				stored, _ := partners.FindByID(ctx, "partner_acme")
+				if len(stored.ClientCertSubjects) != 1 {
+					t.Fatalf("binding changed by rejected request: %v", stored.ClientCertSubjects)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp.Thumbprints, tt.wantThumbprints) || !reflect.DeepEqual(resp.Subjects, tt.wantSubjects) {
+				t.Fatalf("response %+v", resp)
+			}
+
+			stored, _ := partners.FindByID(ctx, "partner_acme")
+			if got := stored.RequiresClientCertificate(); got != (len(tt.wantThumbprints)+len(tt.wantSubjects) > 0) {
+				t.Fatalf("RequiresClientCertificate = %v after setting %+v", got, resp)
+			}
+		})
+	}
+}
+
+func TestSetClientCertificatesAudited(t *testing.T) {
+	ctx := context.Background()
+	partners, audit := newPartnerAdminTestRepos(t)
+	admin := NewPartnerAdminUseCase(partners, NewAuditLog(audit))
+
+	if _, err := admin.SetClientCertificates(ctx, "partner_acme", []string{testThumbprint}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := audit.Query(ctx, repository.AuditFilter{Action: entity.AuditActionPartnerConfigChanged, ResourceID: "partner_acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Details["setting"] != "clientCertificates" {
+		t.Fatalf("audit entries = %+v, want one clientCertificates change", entries)
+	}
+
+	var before, after clientCertificateBinding
+	if err := json.Unmarshal(entries[0].Before, &before); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(entries[0].After, &after); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before.Subjects, []string{"CN=acme"}) || len(before.Thumbprints) != 0 {
+		t.Fatalf("audited before = %+v", before)
+	}
+	if !reflect.DeepEqual(after.Thumbprints, []string{testThumbprint}) || len(after.Subjects) != 0 {
+		t.Fatalf("audited after = %+v", after)
+	}
+}
+
+func TestSetClientCertificatesUnknownPartner(t *testing.T) {
+	partners, audit := newPartnerAdminTestRepos(t)
+	admin := NewPartnerAdminUseCase(partners, NewAuditLog(audit))
+
+	if _, err := admin.SetClientCertificates(context.Background(), "partner_missing", nil, nil); !errors.Is(err, ErrPartnerNotFound) {
+		t.Fatalf("err = %v, want ErrPartnerNotFound", err)
+	}
+}
+
+// newPartnerAdminTestRepos stores partner_acme, bound to subject CN=acme.
+func newPartnerAdminTestRepos(t *testing.T) (repository.PartnerRepository, repository.AuditRepository) {
+	t.Helper()
+	ctx := context.Background()
+	partners, err := inmemory.NewInMemoryPartnerRepository(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partner := entity.NewPartner("partner_acme", "Acme", "acme", "wlt_acme")
+	partner.ClientCertSubjects = []string{"CN=acme"}
+	if err := partners.Create(ctx, partner); err != nil {
+		t.Fatal(err)
+	}
+	return partners, inmemory.NewInMemoryAuditRepository()
+}
@@ -0,0 +1,41 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+// maxPartnerUpdateAttempts bounds how often a partner change is retried after
+// losing a race with another writer.
+const maxPartnerUpdateAttempts = 3
+
+var ErrPartnerConflict = errors.New("partner is being changed by another request")
+
+// updatePartner reads the stored partner, applies change and saves it. When
+// another writer saved the partner in between, it starts again from a fresh
+// read, so change may run more than once and must only modify the partner.
+// Errors from change are returned as they are.
+func updatePartner(ctx context.Context, partners repository.PartnerRepository, partnerID string, change func(*entity.Partner) error) (*entity.Partner, error) {
+	for attempt := 1; ; attempt++ {
+		partner, err := partners.FindByIDForUpdate(ctx, partnerID)
+		if err != nil {
+			return nil, ErrPartnerNotFound
+		}
+		if err := change(partner); err != nil {
+			return nil, err
+		}
+
+		err = partners.Update(ctx, partner)
+		switch {
+		case err == nil:
+			return partner, nil
+		case !errors.Is(err, repository.ErrPartnerConflict):
+			return nil, err
+		case attempt == maxPartnerUpdateAttempts:
+			return nil, ErrPartnerConflict
+		}
+	}
+}
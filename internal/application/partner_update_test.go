@@ -0,0 +1,122 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+// racingPartnerRepository runs interleave after each read for update, before
+// the caller gets to write, standing in for a concurrent request.
+type racingPartnerRepository struct {
+	repository.PartnerRepository
+	interleave func()
+}
+
+func (r *racingPartnerRepository) FindByIDForUpdate(ctx context.Context, id string) (*entity.Partner, error) {
+	partner, err := r.PartnerRepository.FindByIDForUpdate(ctx, id)
+	if r.interleave != nil {
+		r.interleave()
+	}
+	return partner, err
+}
+
+func TestPartnerChangesDoNotRevertConcurrentSuspension(t *testing.T) {
+	ctx := context.Background()
+	names := []string{"Renamed"}
+
+	tests := []struct {
+		name   string
+		change func(admin *PartnerAdminUseCase, credentials *CredentialUseCase) error
+	}{
+		{"rotate secret", func(_ *PartnerAdminUseCase, c *CredentialUseCase) error {
+			_, err := c.RotateSecret(ctx, "partner_acme", RotateSecretRequest{})
+			return err
+		}},
+		{"revoke secret", func(_ *PartnerAdminUseCase, c *CredentialUseCase) error {
+			return c.RevokeSecret(ctx, "partner_acme", "sec_old")
+		}},
+		{"update profile", func(a *PartnerAdminUseCase, _ *CredentialUseCase) error {
+			_, err := a.UpdatePartner(ctx, "partner_acme", UpdatePartnerRequest{Name: &names[0]})
+			return err
+		}},
+		{"set allowed IPs", func(a *PartnerAdminUseCase, _ *CredentialUseCase) error {
+			_, err := a.SetAllowedIPs(ctx, "partner_acme", []string{"203.0.113.0/24"})
+			return err
+		}},
+		{"set client certificates", func(a *PartnerAdminUseCase, _ *CredentialUseCase) error {
+			_, err := a.SetClientCertificates(ctx, "partner_acme", nil, []string{"CN=acme"})
+			return err
+		}},
+		{"set spending limits", func(a *PartnerAdminUseCase, _ *CredentialUseCase) error {
+			_, err := a.SetSpendingLimits(ctx, "partner_acme", []entity.SpendingLimit{{Currency: "USD", DailyMax: 1000}})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := inmemory.NewInMemoryPartnerRepository(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			partner := entity.NewPartner("partner_acme", "Acme", "acme", "wlt_acme")
+			partner.AddClientSecret(entity.ClientSecret{ID: "sec_old"})
+			partner.AddClientSecret(entity.ClientSecret{ID: "sec_new"})
+			if err := stored.Create(ctx, partner); err != nil {
+				t.Fatal(err)
+			}
+
+			auditLog := NewAuditLog(inmemory.NewInMemoryAuditRepository())
+			suspender := NewPartnerAdminUseCase(stored, auditLog)
+			racing := &racingPartnerRepository{PartnerRepository: stored}
+			racing.interleave = func() {
+				racing.interleave = nil
+				if _, err := suspender.SuspendPartner(ctx, "partner_acme", "fraud"); err != nil {
+					t.Errorf("suspend: %v", err)
+				}
+			}
+
+			err = tt.change(NewPartnerAdminUseCase(racing, auditLog), NewCredentialUseCase(racing, auditLog))
+			if err != nil {
+				t.Fatalf("change racing a suspension: %v", err)
+			}
+			got, _ := stored.FindByID(ctx, "partner_acme")
+			if got.Status != entity.PartnerStatusSuspended {
+				t.Fatalf("status = %s after the change, want the concurrent suspension to stand", got.Status)
+			}
+		})
+	}
+}
+
+func TestPartnerChangeGivesUpAfterRepeatedConflicts(t *testing.T) {
+	ctx := context.Background()
+	stored, err := inmemory.NewInMemoryPartnerRepository(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stored.Create(ctx, entity.NewPartner("partner_acme", "Acme", "acme", "wlt_acme")); err != nil {
+		t.Fatal(err)
+	}
+
+	auditLog := NewAuditLog(inmemory.NewInMemoryAuditRepository())
+	other := NewPartnerAdminUseCase(stored, auditLog)
+	attempts := 0
+	racing := &racingPartnerRepository{PartnerRepository: stored, interleave: func() {
+		attempts++
+		if _, err := other.SetWallet(ctx, "partner_acme", "wlt_other"); err != nil {
+			t.Errorf("concurrent change: %v", err)
+		}
+	}}
+
+	_, err = NewPartnerAdminUseCase(racing, auditLog).SetWallet(ctx, "partner_acme", "wlt_mine")
+	if !errors.Is(err, ErrPartnerConflict) {
+		t.Fatalf("err = %v, want ErrPartnerConflict", err)
+	}
+	if attempts != maxPartnerUpdateAttempts {
+		t.Fatalf("%d attempts, want %d", attempts, maxPartnerUpdateAttempts)
+	}
+}
@@ -0,0 +1,36 @@
+package application
+
+import (
+	"context"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+// ProvisioningOutcome is what the provisioning gateway did with a purchase.
+type ProvisioningOutcome string
+
+const (
+	// ProvisioningDelivered means the credit reached the customer.
+	ProvisioningDelivered ProvisioningOutcome = "DELIVERED"
+	// ProvisioningFailed means the gateway gave up; nothing was delivered.
+	ProvisioningFailed ProvisioningOutcome = "FAILED"
+	// ProvisioningInProgress means the gateway is still working on it.
+	ProvisioningInProgress ProvisioningOutcome = "IN_PROGRESS"
+	// ProvisioningNotFound means the gateway never received the purchase.
+	ProvisioningNotFound ProvisioningOutcome = "NOT_FOUND"
+)
+
+type ProvisioningResult struct {
+	Outcome ProvisioningOutcome
+	// Reference is the gateway's identifier for the delivery, when known.
+	Reference string
+	// Reason explains a failure, as reported by the gateway.
+	Reason string
+}
+
+// ProvisioningGateway is the downstream service that delivers purchased
+// credit to the customer.
+type ProvisioningGateway interface {
+	// Status reports the gateway's outcome for the transaction.
+	Status(ctx context.Context, txn *entity.Transaction) (*ProvisioningResult, error)
+}
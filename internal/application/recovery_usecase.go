@@ -0,0 +1,369 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	DefaultRecoveryPendingThreshold = 10 * time.Minute
+	DefaultRecoveryMaxAttempts      = 5
+	DefaultRecoveryBatchSize        = 100
+
+	recoveryLeaseName = "transaction-recovery"
+
+	// transactionLockTTL bounds how long a crashed holder can keep others
+	// away from a transaction.
+	transactionLockTTL = time.Minute
+)
+
+// Sweep outcomes, as counted by RecoveryMetrics.
+const (
+	RecoveryCompleted = "completed"
+	RecoveryFailed    = "failed"
+	RecoveryDeferred  = "deferred"
+	RecoveryEscalated = "escalated"
+
+	recoverySkipped = "skipped"
+)
+
+var ErrTransactionBusy = errors.New("transaction is being changed by another process")
+
+// RecoveryMetrics is notified of status changes and sweep outcomes.
+type RecoveryMetrics interface {
+	TransactionMetrics
+	TransactionRecovered(outcome string)
+}
+
+// RecoveryConfig tunes the sweep. Zero values select the defaults.
+type RecoveryConfig struct {
+	PendingThreshold time.Duration
+	MaxAttempts      int
+	BatchSize        int
+	// InstanceID names this instance as the sweep lease holder; a random
+	// ID is used when empty.
+	InstanceID string
+}
+
+// RecoverySummary counts what one sweep did.
+type RecoverySummary struct {
+	Checked   int
+	Completed int
+	Failed    int
+	Deferred  int
+	Escalated int
+}
+
+// TransactionRecoveryUseCase settles transactions left PENDING, for example
+// because the process died after storing a purchase. It asks the
+// provisioning gateway what happened: delivered purchases are completed,
+// failed or unknown ones are failed and their spending allowance released,
+// and ones the gateway cannot settle after several checks are escalated to
+// operators. Only the instance holding the sweep lease sweeps, and each
+// transaction is locked while it is checked.
+type TransactionRecoveryUseCase struct {
+	transactionRepo repository.TransactionRepository
+	spendingRepo    repository.SpendingRepository
+	leases          repository.LeaseRepository
+	gateway         ProvisioningGateway
+	auditLog        *AuditLog
+	metrics         RecoveryMetrics
+	config          RecoveryConfig
+}
+
+func NewTransactionRecoveryUseCase(
+	transactionRepo repository.TransactionRepository,
+	spendingRepo repository.SpendingRepository,
+	leases repository.LeaseRepository,
+	gateway ProvisioningGateway,
+	auditLog *AuditLog,
+	metrics RecoveryMetrics,
+	config RecoveryConfig,
+) *TransactionRecoveryUseCase {
+	if config.PendingThreshold <= 0 {
+		config.PendingThreshold = DefaultRecoveryPendingThreshold
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultRecoveryMaxAttempts
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultRecoveryBatchSize
+	}
+	if config.InstanceID == "" {
+		config.InstanceID = uuid.New().String()
+	}
+	return &TransactionRecoveryUseCase{
+		transactionRepo: transactionRepo,
+		spendingRepo:    spendingRepo,
+		leases:          leases,
+		gateway:         gateway,
+		auditLog:        auditLog,
+		metrics:         metrics,
+		config:          config,
+	}
+}
+
+// EscalatedCount returns the number of transactions waiting for an operator.
+func (uc *TransactionRecoveryUseCase) EscalatedCount(ctx context.Context) (int, error) {
+	pending, err := uc.transactionRepo.FindByStatus(ctx, entity.TransactionStatusPending)
+	if err != nil {
+		return 0, err
+	}
+
+	escalated := 0
+	for _, txn := range pending {
+		if txn.IsEscalated() {
+			escalated++
+		}
+	}
+	return escalated, nil
+}
+
+// Run sweeps every interval while this instance holds the sweep lease, until
+// ctx is cancelled. The lease lasts a few intervals and is renewed before
+// each transaction, so a slow sweep keeps it and a crashed leader is
+// replaced once it expires. It is released on shutdown so another instance
+// can take over at its next tick.
+func (uc *TransactionRecoveryUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	leaseTTL := 3 * interval
+	leader := false
+	defer func() {
+		if leader {
+			uc.leases.Release(context.WithoutCancel(ctx), recoveryLeaseName, uc.config.InstanceID)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			acquired, err := uc.leases.Acquire(ctx, recoveryLeaseName, uc.config.InstanceID, leaseTTL)
+			if err != nil {
+				slog.Error("recovery lease check failed", "error", err)
+				continue
+			}
+			if acquired != leader {
+				leader = acquired
+				slog.Info("recovery sweep leadership changed", "leader", leader, "instance", uc.config.InstanceID)
+			}
+			if !leader {
+				continue
+			}
+
+			summary, err := uc.sweep(ctx, leaseTTL)
+			if err != nil {
+				slog.Error("recovery sweep failed", "error", err)
+			}
+			if summary.Checked > 0 {
+				slog.Info("recovery sweep checked stuck transactions",
+					"checked", summary.Checked,
+					"completed", summary.Completed,
+					"failed", summary.Failed,
+					"deferred", summary.Deferred,
+					"escalated", summary.Escalated,
+				)
+			}
+		}
+	}
+}
+
+// sweep checks up to one batch of stuck transactions, oldest first. It stops
+// early if the sweep lease is lost.
+func (uc *TransactionRecoveryUseCase) sweep(ctx context.Context, leaseTTL time.Duration) (RecoverySummary, error) {
+	var summary RecoverySummary
+
+	pending, err := uc.transactionRepo.FindByStatus(ctx, entity.TransactionStatusPending)
+	if err != nil {
+		return summary, err
+	}
+
+	// Timestamp is when the transaction entered PENDING, which for an
+	// approved review is later than its creation.
+	cutoff := time.Now().Add(-uc.config.PendingThreshold)
+	for _, txn := range pending {
+		if summary.Checked >= uc.config.BatchSize || ctx.Err() != nil {
+			break
+		}
+		if txn.IsEscalated() || txn.Timestamp.After(cutoff) {
+			continue
+		}
+
+		held, err := uc.leases.Acquire(ctx, recoveryLeaseName, uc.config.InstanceID, leaseTTL)
+		if err != nil {
+			return summary, err
+		}
+		if !held {
+			break
+		}
+
+		outcome, err := uc.recover(ctx, txn.ID)
+		if errors.Is(err, ErrTransactionBusy) {
+			continue
+		}
+		if err != nil {
+			slog.Error("transaction recovery failed", "transaction_id", txn.ID, "error", err)
+			continue
+		}
+
+		switch outcome {
+		case RecoveryCompleted:
+			summary.Completed++
+		case RecoveryFailed:
+			summary.Failed++
+		case RecoveryDeferred:
+			summary.Deferred++
+		case RecoveryEscalated:
+			summary.Escalated++
+		default:
+			continue
+		}
+		summary.Checked++
+	}
+	return summary, nil
+}
+
+func (uc *TransactionRecoveryUseCase) recover(ctx context.Context, transactionID string) (outcome string, err error) {
+	ctx = logging.WithTransactionID(ctx, transactionID)
+	ctx, span := startSpan(ctx, "TransactionRecoveryUseCase.Recover",
+		attribute.String("transaction.id", transactionID),
+	)
+	defer func() {
+		span.SetAttributes(attribute.String("recovery.outcome", outcome))
+		endSpan(span, err)
+	}()
+
+	unlock, err := lockTransaction(ctx, uc.leases, transactionID)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	// Re-read under the lock: an operator may have settled it meanwhile.
+	txn, err := uc.transactionRepo.FindByID(ctx, transactionID)
+	if err != nil {
+		return "", ErrTransactionNotFound
+	}
+	if txn.Status != entity.TransactionStatusPending || txn.IsEscalated() {
+		return recoverySkipped, nil
+	}
+
+	before := transactionAuditView(txn)
+	result, gatewayErr := uc.gateway.Status(ctx, txn)
+	if gatewayErr != nil {
+		logging.FromContext(ctx).Warn("provisioning gateway check failed", "error", gatewayErr)
+		return uc.postpone(ctx, txn, "ERROR")
+	}
+
+	switch result.Outcome {
+	case ProvisioningDelivered:
+		txn.RecordRecoveryAttempt(string(result.Outcome))
+		txn.MarkSuccessful()
+		return RecoveryCompleted, uc.settle(ctx, txn, before, result, RecoveryCompleted)
+	case ProvisioningFailed, ProvisioningNotFound:
+		txn.RecordRecoveryAttempt(string(result.Outcome))
+		txn.MarkFailed()
+		return RecoveryFailed, uc.settle(ctx, txn, before, result, RecoveryFailed)
+	default:
+		return uc.postpone(ctx, txn, string(result.Outcome))
+	}
+}
+
+// settle stores a completed or failed transaction. Failed purchases release
+// their spending allowance, reversing the charge made when they were
+// created.
+func (uc *TransactionRecoveryUseCase) settle(ctx context.Context, txn *entity.Transaction, before transactionAuditState, result *ProvisioningResult, outcome string) error {
+	if err := uc.transactionRepo.Update(ctx, txn); err != nil {
+		return err
+	}
+	uc.metrics.TransactionStatusChanged(txn)
+	uc.metrics.TransactionRecovered(outcome)
+
+	if txn.Status == entity.TransactionStatusFailed {
+		if err := releaseSpending(ctx, uc.spendingRepo, txn); err != nil {
+			logging.FromContext(ctx).Error("spending release failed", "error", err)
+		}
+	}
+
+	entry := entity.NewAuditEntry(entity.AuditActionTransactionRecovered, entity.AuditActorSystem, entity.AuditResourceTransaction, txn.ID)
+	entry.Details = map[string]string{
+		"gatewayOutcome": string(result.Outcome),
+		"attempts":       strconv.Itoa(txn.Recovery.Attempts),
+	}
+	if result.Reference != "" {
+		entry.Details["reference"] = result.Reference
+	}
+	if result.Reason != "" {
+		entry.Details["reason"] = result.Reason
+	}
+	entry.SetChange(before, transactionAuditView(txn))
+	uc.auditLog.RecordCommitted(ctx, entry)
+
+	logging.FromContext(ctx).Info("recovered stuck transaction", "status", txn.Status, "gateway_outcome", result.Outcome)
+	return nil
+}
+
+// postpone records an inconclusive check and escalates the transaction once
+// the attempts are used up.
+func (uc *TransactionRecoveryUseCase) postpone(ctx context.Context, txn *entity.Transaction, gatewayOutcome string) (string, error) {
+	txn.RecordRecoveryAttempt(gatewayOutcome)
+	if txn.Recovery.Attempts < uc.config.MaxAttempts {
+		if err := uc.transactionRepo.Update(ctx, txn); err != nil {
+			return "", err
+		}
+		uc.metrics.TransactionRecovered(RecoveryDeferred)
+		return RecoveryDeferred, nil
+	}
+
+	txn.Escalate()
+	if err := uc.transactionRepo.Update(ctx, txn); err != nil {
+		return "", err
+	}
+	uc.metrics.TransactionRecovered(RecoveryEscalated)
+
+	entry := entity.NewAuditEntry(entity.AuditActionTransactionEscalated, entity.AuditActorSystem, entity.AuditResourceTransaction, txn.ID)
+	entry.Details = map[string]string{
+		"gatewayOutcome": gatewayOutcome,
+		"attempts":       strconv.Itoa(txn.Recovery.Attempts),
+	}
+	uc.auditLog.RecordCommitted(ctx, entry)
+
+	logging.FromContext(ctx).Error("stuck transaction escalated to operators",
+		"attempts", txn.Recovery.Attempts,
+		"gateway_outcome", gatewayOutcome,
+		"pending_since", txn.Timestamp,
+	)
+	return RecoveryEscalated, nil
+}
+
+// lockTransaction takes the lease that keeps the sweeper and operators from
+// changing the same transaction at once. It returns ErrTransactionBusy if
+// the lease is held elsewhere.
+func lockTransaction(ctx context.Context, leases repository.LeaseRepository, transactionID string) (unlock func(), err error) {
+	name := "transaction/" + transactionID
+	owner := uuid.New().String()
+
+	acquired, err := leases.Acquire(ctx, name, owner, transactionLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrTransactionBusy
+	}
+	return func() {
+		// Released even if ctx was cancelled mid-change.
+		leases.Release(context.WithoutCancel(ctx), name, owner)
+	}, nil
+}
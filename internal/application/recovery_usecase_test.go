@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+type nopRecoveryMetrics struct {
+	nopTransactionMetrics
+}
+
+func (nopRecoveryMetrics) TransactionRecovered(string) {}
+
+// stubGateway answers every status check the same way.
+type stubGateway struct {
+	result *ProvisioningResult
+	err    error
+	calls  int
+}
+
+func (g *stubGateway) Status(ctx context.Context, txn *entity.Transaction) (*ProvisioningResult, error) {
+	g.calls++
+	return g.result, g.err
+}
+
+func TestRecoverySweep(t *testing.T) {
+	inProgress := []RecoverySummary{
+		{Checked: 1, Deferred: 1},
+		{Checked: 1, Escalated: 1},
+		{},
+	}
+	tests := []struct {
+		name          string
+		result        *ProvisioningResult
+		err           error
+		sweeps        []RecoverySummary
+		wantStatus    entity.TransactionStatus
+		wantEscalated bool
+		wantReleased  bool
+	}{
+		{
+			name:       "delivered",
+			result:     &ProvisioningResult{Outcome: ProvisioningDelivered, Reference: "ref_1"},
+			sweeps:     []RecoverySummary{{Checked: 1, Completed: 1}, {}},
+			wantStatus: entity.TransactionStatusSuccessful,
+		},
+		{
+			name:         "failed",
+			result:       &ProvisioningResult{Outcome: ProvisioningFailed, Reason: "number barred"},
+			sweeps:       []RecoverySummary{{Checked: 1, Failed: 1}, {}},
+			wantStatus:   entity.TransactionStatusFailed,
+			wantReleased: true,
+		},
+		{
+			name:         "unknown to the gateway",
+			result:       &ProvisioningResult{Outcome: ProvisioningNotFound},
+			sweeps:       []RecoverySummary{{Checked: 1, Failed: 1}, {}},
+			wantStatus:   entity.TransactionStatusFailed,
+			wantReleased: true,
+		},
+		{
+			name:          "escalated after max attempts in progress",
+			result:        &ProvisioningResult{Outcome: ProvisioningInProgress},
+			sweeps:        inProgress,
+			wantStatus:    entity.TransactionStatusPending,
+			wantEscalated: true,
+		},
+		{
+			name:          "escalated after max attempts unreachable",
+			err:           errors.New("connection refused"),
+			sweeps:        inProgress,
+			wantStatus:    entity.TransactionStatusPending,
+			wantEscalated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTransactionTestEnv(t, nil)
+			created, err := env.purchase("u1", "+254700000001", 150)
+			if err != nil {
+				t.Fatalf("purchase: %v", err)
+			}
+
+			gateway := &stubGateway{result: tt.result, err: tt.err}
+			uc := NewTransactionRecoveryUseCase(env.transactions, env.spending, inmemory.NewInMemoryLeaseRepository(),
+				gateway, NewAuditLog(env.audit), nopRecoveryMetrics{}, RecoveryConfig{
+					PendingThreshold: time.Nanosecond,
+					MaxAttempts:      2,
+				})
+
+			wantCalls := 0
+			for i, want := range tt.sweeps {
+				got, err := uc.sweep(ctx, time.Minute)
+				if err != nil {
+					t.Fatalf("sweep %d: %v", i+1, err)
+				}
+				if got != want {
+					t.Fatalf("sweep %d = %+v, want %+v", i+1, got, want)
+				}
+				wantCalls += want.Checked
+			}
+			if gateway.calls != wantCalls {
+				t.Fatalf("%d gateway checks, want %d", gateway.calls, wantCalls)
+			}
+
+			stored, err := env.transactions.FindByID(ctx, created.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != tt.wantStatus || stored.IsEscalated() != tt.wantEscalated {
+				t.Fatalf("transaction %s, escalated %v; want %s, escalated %v", stored.Status, stored.IsEscalated(), tt.wantStatus, tt.wantEscalated)
+			}
+			escalated, err := uc.EscalatedCount(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantCount := 0
+			if tt.wantEscalated {
+				wantCount = 1
+			}
+			if escalated != wantCount {
+				t.Fatalf("EscalatedCount = %d, want %d", escalated, wantCount)
+			}
+
+			// The 250 USD per-user limit only fits another 150 USD purchase
+			// if the first one's allowance was released.
+			_, err = env.purchase("u1", "+254700000001", 150)
+			var limitErr *LimitExceededError
+			if released := !errors.As(err, &limitErr); released != tt.wantReleased {
+				t.Fatalf("allowance released = %v (err %v), want %v", released, err, tt.wantReleased)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultReviewSLA is how long a transaction may wait for manual review
+// before it is rejected automatically.
+const DefaultReviewSLA = 4 * time.Hour
+
+var ErrNotHeldForReview = errors.New("transaction is not held for review")
+
+// ReviewUseCase lets operators resolve transactions the risk check held for
+// manual review.
+type ReviewUseCase struct {
+	transactionRepo repository.TransactionRepository
+	spendingRepo    repository.SpendingRepository
+	leases          repository.LeaseRepository
+	auditLog        *AuditLog
+	metrics         TransactionMetrics
+	sla             time.Duration
+}
+
+type ReviewItemResponse struct {
+	TransactionID  string                     `json:"transactionId"`
+	PartnerID      string                     `json:"partnerId"`
+	UserID         string                     `json:"userId"`
+	WalletID       string                     `json:"walletId,omitempty"`
+	Amount         float64                    `json:"amount"`
+	Currency       string                     `json:"currency"`
+	Status         string                     `json:"status"`
+	Metadata       entity.TransactionMetadata `json:"metadata"`
+	Risk           *entity.RiskAssessment     `json:"risk,omitempty"`
+	Review         *entity.ReviewDecision     `json:"review,omitempty"`
+	CreatedAt      time.Time                  `json:"createdAt"`
+	ReviewDeadline time.Time                  `json:"reviewDeadline"`
+	AuditTrail     []*entity.AuditEntry       `json:"auditTrail,omitempty"`
+}
+
+func NewReviewUseCase(
+	transactionRepo repository.TransactionRepository,
+	spendingRepo repository.SpendingRepository,
+	leases repository.LeaseRepository,
+	auditLog *AuditLog,
+	metrics TransactionMetrics,
+	sla time.Duration,
+) *ReviewUseCase {
+	return &ReviewUseCase{
+		transactionRepo: transactionRepo,
+		spendingRepo:    spendingRepo,
+		leases:          leases,
+		auditLog:        auditLog,
+		metrics:         metrics,
+		sla:             sla,
+	}
+}
+
+// HeldCount returns the number of transactions awaiting review.
+func (uc *ReviewUseCase) HeldCount(ctx context.Context) (int, error) {
+	held, err := uc.transactionRepo.FindByStatus(ctx, entity.TransactionStatusHeldForReview)
+	if err != nil {
+		return 0, err
+	}
+	return len(held), nil
+}
+
+// ListHeld returns transactions awaiting review, oldest first.
+func (uc *ReviewUseCase) ListHeld(ctx context.Context) ([]*ReviewItemResponse, error) {
+	held, err := uc.transactionRepo.FindByStatus(ctx, entity.TransactionStatusHeldForReview)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*ReviewItemResponse, 0, len(held))
+	for _, txn := range held {
+		items = append(items, uc.toReviewItem(txn))
+	}
+	return items, nil
+}
+
+// GetReview returns a transaction with its review audit trail.
+func (uc *ReviewUseCase) GetReview(ctx context.Context, transactionID string) (*ReviewItemResponse, error) {
+	txn, err := uc.transactionRepo.FindByID(ctx, transactionID)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	item := uc.toReviewItem(txn)
+	item.AuditTrail, err = uc.auditLog.FindByResource(ctx, entity.AuditResourceTransaction, txn.ID)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Approve returns a held transaction to processing.
+func (uc *ReviewUseCase) Approve(ctx context.Context, transactionID, reviewer, reason string) (*ReviewItemResponse, error) {
+	return uc.decide(ctx, transactionID, reviewer, reason, entity.ReviewOutcomeApproved, false)
+}
+
+// Reject fails a held transaction and releases its spending allowance.
+func (uc *ReviewUseCase) Reject(ctx context.Context, transactionID, reviewer, reason string) (*ReviewItemResponse, error) {
+	return uc.decide(ctx, transactionID, reviewer, reason, entity.ReviewOutcomeRejected, false)
+}
+
+// RejectOverdue rejects every held transaction past its review deadline and
+// returns how many were rejected. Transactions being decided elsewhere are
+// left to that decision.
+func (uc *ReviewUseCase) RejectOverdue(ctx context.Context) (int, error) {
+	held, err := uc.transactionRepo.FindByStatus(ctx, entity.TransactionStatusHeldForReview)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	rejected := 0
+	for _, txn := range held {
+		if now.Before(txn.CreatedAt.Add(uc.sla)) {
+			continue
+		}
+		_, err := uc.decide(ctx, txn.ID, entity.AuditActorSystem, "review SLA of "+uc.sla.String()+" expired", entity.ReviewOutcomeRejected, true)
+		if errors.Is(err, ErrNotHeldForReview) || errors.Is(err, ErrTransactionBusy) {
+			continue
+		}
+		if err != nil {
+			return rejected, err
+		}
+		rejected++
+	}
+	return rejected, nil
+}
+
+// Run rejects overdue transactions every interval until ctx is cancelled.
+func (uc *ReviewUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rejected, err := uc.RejectOverdue(ctx)
+			if err != nil {
+				slog.Error("review SLA sweep failed", "error", err)
+			}
+			if rejected > 0 {
+				slog.Info("review SLA sweep rejected overdue transactions", "count", rejected)
+			}
+		}
+	}
+}
+
+func (uc *ReviewUseCase) decide(
+	ctx context.Context,
+	transactionID, reviewer, reason string,
+	outcome entity.ReviewOutcome,
+	automatic bool,
+) (_ *ReviewItemResponse, err error) {
+	ctx = logging.WithTransactionID(ctx, transactionID)
+	ctx, span := startSpan(ctx, "ReviewUseCase.Decide",
+		attribute.String("transaction.id", transactionID),
+		attribute.String("review.outcome", string(outcome)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	unlock, err := lockTransaction(ctx, uc.leases, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Re-read under the lock: another reviewer or the SLA sweep may have
+	// decided it meanwhile.
+	txn, err := uc.transactionRepo.FindByID(ctx, transactionID)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	if txn.Status != entity.TransactionStatusHeldForReview {
+		return nil, ErrNotHeldForReview
+	}
+
+	before := transactionAuditView(txn)
+	action := entity.AuditActionReviewApproved
+	if outcome == entity.ReviewOutcomeApproved {
+		txn.Approve(reviewer, reason, automatic)
+	} else {
+		action = entity.AuditActionReviewRejected
+		txn.Reject(reviewer, reason, automatic)
+	}
+
+	if err := uc.transactionRepo.Update(ctx, txn); err != nil {
+		return nil, err
+	}
+	uc.metrics.TransactionStatusChanged(txn)
+
+	if outcome == entity.ReviewOutcomeRejected {
+		if err := releaseSpending(ctx, uc.spendingRepo, txn); err != nil {
+			logging.FromContext(ctx).Error("spending release failed", "error", err)
+		}
+	}
+
+	// Manual decisions are attributed to the admin making the request.
+	actor := ""
+	if automatic {
+		actor = entity.AuditActorSystem
+	}
+	entry := entity.NewAuditEntry(action, actor, entity.AuditResourceTransaction, txn.ID)
+	entry.Details = map[string]string{
+		"reason":    reason,
+		"newStatus": string(txn.Status),
+	}
+	if automatic {
+		entry.Details["automatic"] = "true"
+	}
+	entry.SetChange(before, transactionAuditView(txn))
+	uc.auditLog.RecordCommitted(ctx, entry)
+
+	return uc.toReviewItem(txn), nil
+}
+
+func (uc *ReviewUseCase) toReviewItem(txn *entity.Transaction) *ReviewItemResponse {
+	return &ReviewItemResponse{
+		TransactionID:  txn.ID,
+		PartnerID:      txn.PartnerID,
+		UserID:         txn.UserID,
+		WalletID:       txn.WalletID,
+		Amount:         txn.Amount,
+		Currency:       txn.Currency,
+		Status:         string(txn.Status),
+		Metadata:       txn.Metadata,
+		Risk:           txn.Risk,
+		Review:         txn.Review,
+		CreatedAt:      txn.CreatedAt,
+		ReviewDeadline: txn.CreatedAt.Add(uc.sla),
+	}
+}
@@ -0,0 +1,170 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+// holdPurchase creates a 150 USD purchase without a phone number, which the
+// default risk rules hold for review.
+func (env *transactionTestEnv) holdPurchase(t *testing.T) string {
+	t.Helper()
+	created, err := env.purchase("u1", "", 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != entity.TransactionStatusHeldForReview {
+		t.Fatalf("purchase status %s, want %s", created.Status, entity.TransactionStatusHeldForReview)
+	}
+	return created.ID
+}
+
+func newReviewTestUseCase(env *transactionTestEnv, leases repository.LeaseRepository, sla time.Duration) *ReviewUseCase {
+	return NewReviewUseCase(env.transactions, env.spending, leases, NewAuditLog(env.audit), nopTransactionMetrics{}, sla)
+}
+
+func TestReviewDecisions(t *testing.T) {
+	tests := []struct {
+		name         string
+		reject       bool
+		wantStatus   entity.TransactionStatus
+		wantAction   entity.AuditAction
+		wantReleased bool
+	}{
+		{
+			name:       "approve",
+			wantStatus: entity.TransactionStatusPending,
+			wantAction: entity.AuditActionReviewApproved,
+		},
+		{
+			name:         "reject",
+			reject:       true,
+			wantStatus:   entity.TransactionStatusFailed,
+			wantAction:   entity.AuditActionReviewRejected,
+			wantReleased: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newTransactionTestEnv(t, nil)
+			uc := newReviewTestUseCase(env, inmemory.NewInMemoryLeaseRepository(), DefaultReviewSLA)
+			id := env.holdPurchase(t)
+
+			decide := uc.Approve
+			if tt.reject {
+				decide = uc.Reject
+			}
+			item, err := decide(ctx, id, "alice", "checked with the partner")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.Status != string(tt.wantStatus) || item.Review == nil || item.Review.Reviewer != "alice" || item.Review.Automatic {
+				t.Fatalf("decision %+v, review %+v", item, item.Review)
+			}
+			stored, err := env.transactions.FindByID(ctx, id)
+			if err != nil || stored.Status != tt.wantStatus {
+				t.Fatalf("stored transaction = %+v, %v; want %s", stored, err, tt.wantStatus)
+			}
+
+			entries, err := env.audit.Query(ctx, repository.AuditFilter{Action: tt.wantAction, ResourceID: id})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Details["reason"] != "checked with the partner" {
+				t.Fatalf("audit entries = %+v, want one %s", entries, tt.wantAction)
+			}
+
+			// The 250 USD per-user limit only fits another 150 USD purchase
+			// if the held one's allowance was released.
+			_, err = env.purchase("u1", "+254700000001", 150)
+			var limitErr *LimitExceededError
+			if released := !errors.As(err, &limitErr); released != tt.wantReleased {
+				t.Fatalf("allowance released = %v (err %v), want %v", released, err, tt.wantReleased)
+			}
+		})
+	}
+}
+
+func TestReviewDecidedOnce(t *testing.T) {
+	ctx := context.Background()
+	env := newTransactionTestEnv(t, nil)
+	uc := newReviewTestUseCase(env, inmemory.NewInMemoryLeaseRepository(), DefaultReviewSLA)
+	id := env.holdPurchase(t)
+
+	if _, err := uc.Approve(ctx, id, "alice", "ok"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Reject(ctx, id, "bob", "not ok"); !errors.Is(err, ErrNotHeldForReview) {
+		t.Fatalf("second decision: err = %v, want ErrNotHeldForReview", err)
+	}
+	if _, err := uc.Approve(ctx, "txn_missing", "alice", "ok"); !errors.Is(err, ErrTransactionNotFound) {
+		t.Fatalf("unknown transaction: err = %v, want ErrTransactionNotFound", err)
+	}
+
+	stored, _ := env.transactions.FindByID(ctx, id)
+	if stored.Status != entity.TransactionStatusPending || stored.Review.Reviewer != "alice" {
+		t.Fatalf("stored transaction %+v, review %+v; want alice's approval", stored, stored.Review)
+	}
+}
+
+func TestReviewWaitsForLease(t *testing.T) {
+	ctx := context.Background()
+	env := newTransactionTestEnv(t, nil)
+	leases := inmemory.NewInMemoryLeaseRepository()
+	uc := newReviewTestUseCase(env, leases, time.Nanosecond)
+	id := env.holdPurchase(t)
+
+	// Another instance is deciding the transaction.
+	unlock, err := lockTransaction(ctx, leases, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Approve(ctx, id, "alice", "ok"); !errors.Is(err, ErrTransactionBusy) {
+		t.Fatalf("err = %v, want ErrTransactionBusy", err)
+	}
+	if rejected, err := uc.RejectOverdue(ctx); err != nil || rejected != 0 {
+		t.Fatalf("RejectOverdue = %d, %v; want the locked transaction skipped", rejected, err)
+	}
+	unlock()
+
+	if _, err := uc.Approve(ctx, id, "alice", "ok"); err != nil {
+		t.Fatalf("after release: %v", err)
+	}
+}
+
+func TestRejectOverdue(t *testing.T) {
+	ctx := context.Background()
+	env := newTransactionTestEnv(t, nil)
+	id := env.holdPurchase(t)
+
+	if rejected, err := newReviewTestUseCase(env, inmemory.NewInMemoryLeaseRepository(), time.Hour).RejectOverdue(ctx); err != nil || rejected != 0 {
+		t.Fatalf("within SLA: RejectOverdue = %d, %v; want 0", rejected, err)
+	}
+
+	uc := newReviewTestUseCase(env, inmemory.NewInMemoryLeaseRepository(), time.Nanosecond)
+	if rejected, err := uc.RejectOverdue(ctx); err != nil || rejected != 1 {
+		t.Fatalf("past SLA: RejectOverdue = %d, %v; want 1", rejected, err)
+	}
+	if rejected, err := uc.RejectOverdue(ctx); err != nil || rejected != 0 {
+		t.Fatalf("second sweep: RejectOverdue = %d, %v; want 0", rejected, err)
+	}
+
+	stored, _ := env.transactions.FindByID(ctx, id)
+	if stored.Status != entity.TransactionStatusFailed || stored.Review == nil || !stored.Review.Automatic {
+		t.Fatalf("stored transaction %+v, review %+v; want automatic rejection", stored, stored.Review)
+	}
+	entries, err := env.audit.Query(ctx, repository.AuditFilter{Action: entity.AuditActionReviewRejected, ResourceID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Actor != entity.AuditActorSystem || entries[0].Details["automatic"] != "true" {
+		t.Fatalf("audit entries = %+v, want one automatic rejection by the system", entries)
+	}
+}
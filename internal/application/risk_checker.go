@@ -0,0 +1,20 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+var ErrTransactionDenied = errors.New("transaction denied by risk check")
+
+// RiskChecker is the fraud and velocity stage of the purchase pipeline.
+type RiskChecker interface {
+	// Assess decides whether the purchase may go ahead. Unless it is denied,
+	// the purchase is recorded for future velocity checks atomically with
+	// the decision.
+	Assess(ctx context.Context, txn *entity.Transaction) (*entity.RiskAssessment, error)
+	// Forget removes a purchase Assess recorded that did not go ahead.
+	Forget(ctx context.Context, txn *entity.Transaction) error
+}
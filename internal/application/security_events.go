@@ -0,0 +1,13 @@
+package application
+
+import (
+	"context"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+// SecurityEventPublisher delivers security events to monitoring. Publishing
+// must not fail the operation that produced the event.
+type SecurityEventPublisher interface {
+	Publish(ctx context.Context, event *entity.SecurityEvent)
+}
@@ -0,0 +1,219 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var ErrLimitExceeded = errors.New("spending limit exceeded")
+
+// spendingCounterRetention keeps counters a little past their period so that
+// late releases for failed purchases still find them.
+const spendingCounterRetention = 24 * time.Hour
+
+// LimitExceededError reports which spending quota a purchase would exceed and
+// how much of it is left.
+type LimitExceededError struct {
+	Type      entity.SpendingLimitType
+	Currency  string
+	Limit     float64
+	Used      float64
+	Remaining float64
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s spending limit exceeded for %s: %.2f of %.2f remaining", strings.ToLower(string(e.Type)), e.Currency, e.Remaining, e.Limit)
+}
+
+func (e *LimitExceededError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// LimitUsage is the current state of one spending quota.
+type LimitUsage struct {
+	Limit     float64   `json:"limit"`
+	Used      float64   `json:"used"`
+	Remaining float64   `json:"remaining"`
+	ResetsAt  time.Time `json:"resetsAt"`
+}
+
+type CurrencyLimitsResponse struct {
+	Currency string      `json:"currency"`
+	Daily    *LimitUsage `json:"daily,omitempty"`
+	Monthly  *LimitUsage `json:"monthly,omitempty"`
+	// PerUserDailyMax is always reported; UserDaily is filled in when usage
+	// for a specific end user was requested.
+	PerUserDailyMax float64     `json:"perUserDailyMax,omitempty"`
+	UserDaily       *LimitUsage `json:"userDaily,omitempty"`
+}
+
+type LimitsResponse struct {
+	PartnerID string                   `json:"partnerId"`
+	UserID    string                   `json:"userId,omitempty"`
+	Limits    []CurrencyLimitsResponse `json:"limits"`
+}
+
+// spendingQuota pairs a quota type with the counter that tracks it.
+type spendingQuota struct {
+	limitType entity.SpendingLimitType
+	resetsAt  time.Time
+	counter   repository.SpendingCounter
+}
+
+// spendingQuotas returns the counters a purchase at time at is charged to.
+// Every purchase is counted whether or not a limit is configured, so that a
+// newly configured limit takes earlier spending in the period into account.
+func spendingQuotas(partnerID, userID, currency string, limit entity.SpendingLimit, at time.Time) []spendingQuota {
+	currency = strings.ToUpper(currency)
+	base := "spend:" + partnerID + ":" + currency
+
+	types := []entity.SpendingLimitType{entity.SpendingLimitDaily, entity.SpendingLimitMonthly}
+	if userID != "" {
+		types = append(types, entity.SpendingLimitUserDaily)
+	}
+
+	quotas := make([]spendingQuota, 0, len(types))
+	for _, limitType := range types {
+		start, end := entity.SpendingPeriod(limitType, at)
+
+		var key string
+		switch limitType {
+		case entity.SpendingLimitDaily:
+			key = base + ":day:" + start.Format("2006-01-02")
+		case entity.SpendingLimitMonthly:
+			key = base + ":month:" + start.Format("2006-01")
+		case entity.SpendingLimitUserDaily:
+			key = base + ":user:" + userID + ":day:" + start.Format("2006-01-02")
+		}
+
+		quotas = append(quotas, spendingQuota{
+			limitType: limitType,
+			resetsAt:  end,
+			counter: repository.SpendingCounter{
+				Key:       key,
+				Limit:     limit.Max(limitType),
+				ExpiresAt: end.Add(spendingCounterRetention),
+			},
+		})
+	}
+	return quotas
+}
+
+func spendingCounters(quotas []spendingQuota) []repository.SpendingCounter {
+	counters := make([]repository.SpendingCounter, len(quotas))
+	for i, q := range quotas {
+		counters[i] = q.counter
+	}
+	return counters
+}
+
+func spendingKeys(quotas []spendingQuota) []string {
+	keys := make([]string, len(quotas))
+	for i, q := range quotas {
+		keys[i] = q.counter.Key
+	}
+	return keys
+}
+
+// limitExceeded builds the error for the quota with the least allowance left,
+// which is the one that rejected the purchase.
+func limitExceeded(quotas []spendingQuota, used []float64, currency string) error {
+	var tightest *LimitExceededError
+	for i, q := range quotas {
+		if q.counter.Limit <= 0 {
+			continue
+		}
+		left := remaining(q.counter.Limit, used[i])
+		if tightest == nil || left < tightest.Remaining {
+			tightest = &LimitExceededError{
+				Type:      q.limitType,
+				Currency:  strings.ToUpper(currency),
+				Limit:     q.counter.Limit,
+				Used:      used[i],
+				Remaining: left,
+			}
+		}
+	}
+	if tightest == nil {
+		return ErrLimitExceeded
+	}
+	return tightest
+}
+
+// releaseSpending returns a failed purchase's amount to the quotas it was
+// charged against.
+func releaseSpending(ctx context.Context, spendingRepo repository.SpendingRepository, txn *entity.Transaction) error {
+	quotas := spendingQuotas(txn.PartnerID, txn.UserID, txn.Currency, entity.SpendingLimit{}, txn.CreatedAt)
+	return spendingRepo.Release(ctx, spendingKeys(quotas), txn.Amount)
+}
+
+func limitUsage(q spendingQuota, used float64) *LimitUsage {
+	if q.counter.Limit <= 0 {
+		return nil
+	}
+	return &LimitUsage{
+		Limit:     q.counter.Limit,
+		Used:      used,
+		Remaining: remaining(q.counter.Limit, used),
+		ResetsAt:  q.resetsAt,
+	}
+}
+
+func remaining(limit, used float64) float64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// GetLimits reports the partner's spending limits and current usage. When
+// userID is set, that end user's daily usage is included.
+func (uc *TransactionUseCase) GetLimits(ctx context.Context, partnerID, userID string) (_ *LimitsResponse, err error) {
+	ctx, span := startSpan(ctx, "TransactionUseCase.GetLimits", attribute.String("partner.id", partnerID))
+	defer func() { endSpan(span, err) }()
+
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+
+	resp := &LimitsResponse{
+		PartnerID: partner.ID,
+		UserID:    userID,
+		Limits:    make([]CurrencyLimitsResponse, 0, len(partner.SpendingLimits)),
+	}
+
+	now := time.Now()
+	for _, limit := range partner.SpendingLimits {
+		quotas := spendingQuotas(partner.ID, userID, limit.Currency, limit, now)
+		used, err := uc.spendingRepo.Usage(ctx, spendingKeys(quotas))
+		if err != nil {
+			return nil, err
+		}
+
+		currencyLimits := CurrencyLimitsResponse{
+			Currency:        strings.ToUpper(limit.Currency),
+			PerUserDailyMax: limit.PerUserDailyMax,
+		}
+		for i, q := range quotas {
+			switch q.limitType {
+			case entity.SpendingLimitDaily:
+				currencyLimits.Daily = limitUsage(q, used[i])
+			case entity.SpendingLimitMonthly:
+				currencyLimits.Monthly = limitUsage(q, used[i])
+			case entity.SpendingLimitUserDaily:
+				currencyLimits.UserDaily = limitUsage(q, used[i])
+			}
+		}
+		resp.Limits = append(resp.Limits, currencyLimits)
+	}
+
+	return resp, nil
+}
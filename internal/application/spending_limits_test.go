@@ -0,0 +1,266 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+func (env *transactionTestEnv) setSpendingLimits(t *testing.T, limits ...entity.SpendingLimit) {
+	t.Helper()
+	ctx := context.Background()
+	partner, err := env.partners.FindByID(ctx, "partner_acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partner.SpendingLimits = limits
+	if err := env.partners.Update(ctx, partner); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// spend is one purchase and the quota expected to reject it, if any.
+type spend struct {
+	user          string
+	currency      string
+	amount        float64
+	wantLimit     entity.SpendingLimitType
+	wantRemaining float64
+}
+
+func TestSpendingLimitsEnforced(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  entity.SpendingLimit
+		spends []spend
+	}{
+		{
+			name:  "daily",
+			limit: entity.SpendingLimit{Currency: "USD", DailyMax: 100},
+			spends: []spend{
+				{amount: 60},
+				{amount: 50, wantLimit: entity.SpendingLimitDaily, wantRemaining: 40},
+				{amount: 40},
+				{amount: 0.01, wantLimit: entity.SpendingLimitDaily},
+			},
+		},
+		{
+			name:  "monthly tighter than daily",
+			limit: entity.SpendingLimit{Currency: "USD", DailyMax: 100, MonthlyMax: 80},
+			spends: []spend{
+				{amount: 60},
+				{amount: 30, wantLimit: entity.SpendingLimitMonthly, wantRemaining: 20},
+			},
+		},
+		{
+			name:  "per user",
+			limit: entity.SpendingLimit{Currency: "USD", PerUserDailyMax: 50},
+			spends: []spend{
+				{user: "u1", amount: 40},
+				{user: "u1", amount: 20, wantLimit: entity.SpendingLimitUserDaily, wantRemaining: 10},
+				{user: "u2", amount: 40},
+			},
+		},
+		{
+			name:  "currency without a limit",
+			limit: entity.SpendingLimit{Currency: "USD", DailyMax: 10},
+			spends: []spend{
+				{currency: "EUR", amount: 190},
+				{amount: 20, wantLimit: entity.SpendingLimitDaily, wantRemaining: 10},
+			},
+		},
+		{
+			name:  "currency matched case-insensitively",
+			limit: entity.SpendingLimit{Currency: "USD", DailyMax: 10},
+			spends: []spend{
+				{currency: "usd", amount: 20, wantLimit: entity.SpendingLimitDaily, wantRemaining: 10},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTransactionTestEnv(t, nil)
+			env.setSpendingLimits(t, tt.limit)
+
+			for i, s := range tt.spends {
+				user, currency := s.user, s.currency
+				if user == "" {
+					user = "u1"
+				}
+				if currency == "" {
+					currency = "USD"
+				}
+				_, err := env.uc.CreateTransaction(context.Background(), "partner_acme", CreateTransactionRequest{
+					UserID:   user,
+					WalletID: "wlt_" + user,
+					Amount:   s.amount,
+					Currency: currency,
+					Metadata: TransactionMetadata{PhoneNumber: fmt.Sprintf("+2547000000%02d", i)},
+				})
+
+				if s.wantLimit == "" {
+					if err != nil {
+						t.Fatalf("spend %d: %v", i+1, err)
+					}
+					continue
+				}
+				var limitErr *LimitExceededError
+				if !errors.As(err, &limitErr) || !errors.Is(err, ErrLimitExceeded) {
+					t.Fatalf("spend %d: err = %v, want LimitExceededError", i+1, err)
+				}
+				if limitErr.Type != s.wantLimit || limitErr.Remaining != s.wantRemaining || limitErr.Currency != "USD" {
+					t.Fatalf("spend %d: %+v, want %s with %.2f remaining", i+1, limitErr, s.wantLimit, s.wantRemaining)
+				}
+			}
+		})
+	}
+}
+
+func TestSpendingWindowRollover(t *testing.T) {
+	limit := entity.SpendingLimit{Currency: "USD", DailyMax: 100, MonthlyMax: 150, PerUserDailyMax: 100}
+	at := func(s string) time.Time {
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		name     string
+		first    time.Time
+		second   time.Time
+		amount   float64
+		wantType entity.SpendingLimitType
+	}{
+		{
+			name:     "same day",
+			first:    at("2099-01-15T08:00:00Z"),
+			second:   at("2099-01-15T23:59:59Z"),
+			amount:   1,
+			wantType: entity.SpendingLimitDaily,
+		},
+		{
+			name:   "next UTC day",
+			first:  at("2099-01-15T23:59:59Z"),
+			second: at("2099-01-16T00:00:00Z"),
+			amount: 40,
+		},
+		{
+			name:     "next day, same month",
+			first:    at("2099-01-15T12:00:00Z"),
+			second:   at("2099-01-16T12:00:00Z"),
+			amount:   60,
+			wantType: entity.SpendingLimitMonthly,
+		},
+		{
+			name:   "next month",
+			first:  at("2099-01-31T23:59:59Z"),
+			second: at("2099-02-01T00:00:00Z"),
+			amount: 100,
+		},
+		{
+			name:     "local midnight is not a UTC day boundary",
+			first:    at("2099-01-15T20:00:00Z"),
+			second:   at("2099-01-16T00:30:00+03:00"),
+			amount:   1,
+			wantType: entity.SpendingLimitDaily,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			spending := inmemory.NewInMemorySpendingRepository()
+			reserve := func(when time.Time, amount float64) error {
+				quotas := spendingQuotas("partner_acme", "u1", "USD", limit, when)
+				used, err := spending.Reserve(ctx, spendingCounters(quotas), amount)
+				if err != nil {
+					return limitExceeded(quotas, used, "USD")
+				}
+				return nil
+			}
+
+			if err := reserve(tt.first, 100); err != nil {
+				t.Fatalf("first purchase: %v", err)
+			}
+			err := reserve(tt.second, tt.amount)
+			if tt.wantType == "" {
+				if err != nil {
+					t.Fatalf("second purchase: %v", err)
+				}
+				return
+			}
+			var limitErr *LimitExceededError
+			if !errors.As(err, &limitErr) || limitErr.Type != tt.wantType {
+				t.Fatalf("second purchase: err = %v, want %s limit", err, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestGetLimits(t *testing.T) {
+	env := newTransactionTestEnv(t, nil)
+	env.setSpendingLimits(t,
+		entity.SpendingLimit{Currency: "USD", DailyMax: 100, MonthlyMax: 1000, PerUserDailyMax: 50},
+		entity.SpendingLimit{Currency: "EUR", PerUserDailyMax: 20},
+	)
+	for i, p := range []struct {
+		user   string
+		amount float64
+	}{{"u1", 30}, {"u2", 15}} {
+		_, err := env.uc.CreateTransaction(context.Background(), "partner_acme", CreateTransactionRequest{
+			UserID: p.user, WalletID: "wlt_" + p.user, Amount: p.amount, Currency: "USD",
+			Metadata: TransactionMetadata{PhoneNumber: fmt.Sprintf("+2547000000%02d", i)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := env.uc.GetLimits(context.Background(), "partner_acme", "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.PartnerID != "partner_acme" || resp.UserID != "u1" || len(resp.Limits) != 2 {
+		t.Fatalf("response %+v", resp)
+	}
+
+	_, nextDay := entity.SpendingPeriod(entity.SpendingLimitDaily, time.Now())
+	_, nextMonth := entity.SpendingPeriod(entity.SpendingLimitMonthly, time.Now())
+
+	usd := resp.Limits[0]
+	want := map[string]struct {
+		got  *LimitUsage
+		want LimitUsage
+	}{
+		"daily":     {usd.Daily, LimitUsage{Limit: 100, Used: 45, Remaining: 55, ResetsAt: nextDay}},
+		"monthly":   {usd.Monthly, LimitUsage{Limit: 1000, Used: 45, Remaining: 955, ResetsAt: nextMonth}},
+		"userDaily": {usd.UserDaily, LimitUsage{Limit: 50, Used: 30, Remaining: 20, ResetsAt: nextDay}},
+	}
+	for name, w := range want {
+		if w.got == nil || *w.got != w.want {
+			t.Errorf("USD %s = %+v, want %+v", name, w.got, w.want)
+		}
+	}
+
+	eur := resp.Limits[1]
+	if eur.Currency != "EUR" || eur.Daily != nil || eur.Monthly != nil || eur.PerUserDailyMax != 20 {
+		t.Fatalf("EUR limits %+v", eur)
+	}
+	if eur.UserDaily == nil || eur.UserDaily.Used != 0 || eur.UserDaily.Remaining != 20 {
+		t.Fatalf("EUR user usage %+v", eur.UserDaily)
+	}
+
+	withoutUser, err := env.uc.GetLimits(context.Background(), "partner_acme", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withoutUser.Limits[0].UserDaily != nil || withoutUser.Limits[0].PerUserDailyMax != 50 {
+		t.Fatalf("limits without a user %+v", withoutUser.Limits[0])
+	}
+}
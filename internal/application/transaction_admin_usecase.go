@@ -0,0 +1,223 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	DefaultTransactionListLimit = 100
+	MaxTransactionListLimit     = 500
+
+	maxForceFailReasonLength = 500
+)
+
+var (
+	ErrInvalidTransactionFilter = errors.New("invalid transaction filter")
+	ErrTransactionNotPending    = errors.New("transaction is not pending")
+	ErrReasonRequired           = errors.New("reason is required")
+)
+
+// TransactionAdminUseCase lets operators look up transactions and resolve
+// ones stuck in processing.
+type TransactionAdminUseCase struct {
+	transactionRepo repository.TransactionRepository
+	spendingRepo    repository.SpendingRepository
+	leases          repository.LeaseRepository
+	auditLog        *AuditLog
+	metrics         TransactionMetrics
+}
+
+type ListTransactionsRequest struct {
+	PartnerID string
+	Status    entity.TransactionStatus
+	Since     time.Time
+	Until     time.Time
+	After     string
+	Limit     int
+}
+
+type TransactionDetailResponse struct {
+	TransactionID string                     `json:"transactionId"`
+	PartnerID     string                     `json:"partnerId"`
+	UserID        string                     `json:"userId"`
+	WalletID      string                     `json:"walletId,omitempty"`
+	Amount        float64                    `json:"amount"`
+	Currency      string                     `json:"currency"`
+	Status        entity.TransactionStatus   `json:"status"`
+	Metadata      entity.TransactionMetadata `json:"metadata"`
+	Risk          *entity.RiskAssessment     `json:"risk,omitempty"`
+	Review        *entity.ReviewDecision     `json:"review,omitempty"`
+	Recovery      *entity.RecoveryState      `json:"recovery,omitempty"`
+	CreatedAt     time.Time                  `json:"createdAt"`
+	UpdatedAt     time.Time                  `json:"updatedAt"`
+	AuditTrail    []*entity.AuditEntry       `json:"auditTrail,omitempty"`
+}
+
+type TransactionListResponse struct {
+	Transactions []*TransactionDetailResponse `json:"transactions"`
+	// NextAfter is the cursor for the next page; empty on the last page.
+	NextAfter string `json:"nextAfter,omitempty"`
+}
+
+func NewTransactionAdminUseCase(
+	transactionRepo repository.TransactionRepository,
+	spendingRepo repository.SpendingRepository,
+	leases repository.LeaseRepository,
+	auditLog *AuditLog,
+	metrics TransactionMetrics,
+) *TransactionAdminUseCase {
+	return &TransactionAdminUseCase{
+		transactionRepo: transactionRepo,
+		spendingRepo:    spendingRepo,
+		leases:          leases,
+		auditLog:        auditLog,
+		metrics:         metrics,
+	}
+}
+
+// ListTransactions returns matching transactions, oldest first.
+func (uc *TransactionAdminUseCase) ListTransactions(ctx context.Context, req ListTransactionsRequest) (*TransactionListResponse, error) {
+	if req.Status != "" && !isKnownTransactionStatus(req.Status) {
+		return nil, fmt.Errorf("%w: unknown status %s", ErrInvalidTransactionFilter, req.Status)
+	}
+	if !req.Since.IsZero() && !req.Until.IsZero() && !req.Since.Before(req.Until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidTransactionFilter)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultTransactionListLimit
+	}
+	if limit > MaxTransactionListLimit {
+		limit = MaxTransactionListLimit
+	}
+	if req.After != "" {
+		if _, err := uc.transactionRepo.FindByID(ctx, req.After); err != nil {
+			return nil, fmt.Errorf("%w: unknown cursor", ErrInvalidTransactionFilter)
+		}
+	}
+
+	// One extra transaction tells whether another page follows.
+	transactions, err := uc.transactionRepo.List(ctx, repository.TransactionFilter{
+		PartnerID: req.PartnerID,
+		Status:    req.Status,
+		Since:     req.Since,
+		Until:     req.Until,
+		AfterID:   req.After,
+		Limit:     limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TransactionListResponse{Transactions: []*TransactionDetailResponse{}}
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		resp.NextAfter = transactions[limit-1].ID
+	}
+	for _, txn := range transactions {
+		resp.Transactions = append(resp.Transactions, toTransactionDetail(txn))
+	}
+	return resp, nil
+}
+
+// GetTransaction returns a transaction with its audit trail.
+func (uc *TransactionAdminUseCase) GetTransaction(ctx context.Context, transactionID string) (*TransactionDetailResponse, error) {
+	txn, err := uc.transactionRepo.FindByID(ctx, transactionID)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	detail := toTransactionDetail(txn)
+	detail.AuditTrail, err = uc.auditLog.FindByResource(ctx, entity.AuditResourceTransaction, txn.ID)
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// ForceFail fails a transaction stuck in PENDING and releases its spending
+// allowance. Held transactions are resolved through review instead, and
+// finished ones cannot be changed. It returns ErrTransactionBusy while the
+// recovery sweep is checking the transaction.
+func (uc *TransactionAdminUseCase) ForceFail(ctx context.Context, transactionID, reason string) (_ *TransactionDetailResponse, err error) {
+	ctx = logging.WithTransactionID(ctx, transactionID)
+	ctx, span := startSpan(ctx, "TransactionAdminUseCase.ForceFail",
+		attribute.String("transaction.id", transactionID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxForceFailReasonLength {
+		return nil, ErrReasonRequired
+	}
+
+	unlock, err := lockTransaction(ctx, uc.leases, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	txn, err := uc.transactionRepo.FindByID(ctx, transactionID)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	if txn.Status != entity.TransactionStatusPending {
+		return nil, fmt.Errorf("%w: status is %s", ErrTransactionNotPending, txn.Status)
+	}
+
+	before := transactionAuditView(txn)
+	txn.MarkFailed()
+	if err := uc.transactionRepo.Update(ctx, txn); err != nil {
+		return nil, err
+	}
+	uc.metrics.TransactionStatusChanged(txn)
+
+	if err := releaseSpending(ctx, uc.spendingRepo, txn); err != nil {
+		logging.FromContext(ctx).Error("spending release failed", "error", err)
+	}
+
+	entry := entity.NewAuditEntry(entity.AuditActionTransactionForceFailed, "", entity.AuditResourceTransaction, txn.ID)
+	entry.Details = map[string]string{"reason": reason}
+	entry.SetChange(before, transactionAuditView(txn))
+	uc.auditLog.RecordCommitted(ctx, entry)
+
+	return toTransactionDetail(txn), nil
+}
+
+func isKnownTransactionStatus(status entity.TransactionStatus) bool {
+	switch status {
+	case entity.TransactionStatusPending,
+		entity.TransactionStatusSuccessful,
+		entity.TransactionStatusFailed,
+		entity.TransactionStatusHeldForReview:
+		return true
+	}
+	return false
+}
+
+func toTransactionDetail(txn *entity.Transaction) *TransactionDetailResponse {
+	return &TransactionDetailResponse{
+		TransactionID: txn.ID,
+		PartnerID:     txn.PartnerID,
+		UserID:        txn.UserID,
+		WalletID:      txn.WalletID,
+		Amount:        txn.Amount,
+		Currency:      txn.Currency,
+		Status:        txn.Status,
+		Metadata:      txn.Metadata,
+		Risk:          txn.Risk,
+		Review:        txn.Review,
+		Recovery:      txn.Recovery,
+		CreatedAt:     txn.CreatedAt,
+		UpdatedAt:     txn.Timestamp,
+	}
+}
@@ -0,0 +1,8 @@
+package application
+
+import "github.com/sample-provider/buy-credit-api/internal/domain/entity"
+
+// TransactionMetrics is notified whenever a transaction enters a new status.
+type TransactionMetrics interface {
+	TransactionStatusChanged(txn *entity.Transaction)
+}
@@ -8,16 +8,37 @@ import (
 	"github.com/google/uuid"
 	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
 	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrTransactionNotFound       = errors.New("transaction not found")
+	ErrInvalidAmount             = errors.New("invalid amount")
+	ErrTransactionTypeNotAllowed = errors.New("transaction type not allowed for partner")
 )
 
 type TransactionUseCase struct {
 	transactionRepo repository.TransactionRepository
+	partnerRepo     repository.PartnerRepository
+	spendingRepo    repository.SpendingRepository
+	riskChecker     RiskChecker
+	metrics         TransactionMetrics
+	auditLog        *AuditLog
 }
 
 type CreateTransactionRequest struct {
-	UserID   string  `json:"userId"`
-	Amount   float64 `json:"amount"`
-	Currency string  `json:"currency"`
+	UserID   string              `json:"userId" validate:"required,max=64"`
+	WalletID string              `json:"walletId" validate:"max=64"`
+	Amount   float64             `json:"amount" validate:"required,gt=0"`
+	Currency string              `json:"currency" validate:"required,len=3"`
+	Metadata TransactionMetadata `json:"metadata"`
+}
+
+type TransactionMetadata struct {
+	PhoneNumber string `json:"phoneNumber" validate:"max=20"`
+	Provider    string `json:"provider" validate:"max=64"`
+	ProductID   string `json:"productId" validate:"max=64"`
 }
 
 type TransactionResponse struct {
@@ -31,30 +52,101 @@ type TransactionResponse struct {
 
 func NewTransactionUseCase(
 	transactionRepo repository.TransactionRepository,
+	partnerRepo repository.PartnerRepository,
+	spendingRepo repository.SpendingRepository,
+	riskChecker RiskChecker,
+	metrics TransactionMetrics,
+	auditLog *AuditLog,
 ) *TransactionUseCase {
 	return &TransactionUseCase{
 		transactionRepo: transactionRepo,
+		partnerRepo:     partnerRepo,
+		spendingRepo:    spendingRepo,
+		riskChecker:     riskChecker,
+		metrics:         metrics,
+		auditLog:        auditLog,
 	}
 }
 
-func (uc *TransactionUseCase) CreateTransaction(ctx context.Context, req CreateTransactionRequest) (*TransactionResponse, error) {
+// CreateTransaction records a purchase for partnerID. The purchase first
+// passes the risk check: denied purchases are stored as FAILED and return
+// ErrTransactionDenied. The amount is then charged against the partner's
+// spending quotas atomically before the transaction is created; a purchase
+// that would exceed any quota fails with a *LimitExceededError.
+func (uc *TransactionUseCase) CreateTransaction(ctx context.Context, partnerID string, req CreateTransactionRequest) (_ *TransactionResponse, err error) {
+	ctx, span := startSpan(ctx, "TransactionUseCase.CreateTransaction",
+		attribute.String("partner.id", partnerID),
+		attribute.String("transaction.currency", req.Currency),
+	)
+	defer func() { endSpan(span, err) }()
+
 	// Validate amount
 	if req.Amount <= 0 {
-		return nil, errors.New("invalid amount")
+		return nil, ErrInvalidAmount
+	}
+
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, ErrPartnerNotFound
+	}
+	if !partner.AllowsTransactionType(entity.TransactionTypeCreditPurchase) {
+		return nil, ErrTransactionTypeNotAllowed
 	}
 
 	// Create transaction
 	txnID := fmt.Sprintf("txn_%s", uuid.New().String()[:8])
+	ctx = logging.WithTransactionID(ctx, txnID)
 	transaction := entity.NewTransaction(
 		txnID,
+		partnerID,
 		req.UserID,
 		req.Currency,
 		req.Amount,
 	)
+	transaction.WalletID = req.WalletID
+	transaction.Metadata = entity.TransactionMetadata{
+		PhoneNumber: req.Metadata.PhoneNumber,
+		Provider:    req.Metadata.Provider,
+		ProductID:   req.Metadata.ProductID,
+	}
+
+	assessment, err := uc.riskChecker.Assess(ctx, transaction)
+	if err != nil {
+		return nil, err
+	}
+	transaction.Risk = assessment
+
+	if assessment.Decision == entity.RiskDecisionDeny {
+		transaction.MarkFailed()
+		if err := uc.transactionRepo.Create(ctx, transaction); err != nil {
+			return nil, err
+		}
+		uc.metrics.TransactionStatusChanged(transaction)
+		uc.auditCreated(ctx, transaction)
+		return nil, ErrTransactionDenied
+	}
+	if assessment.Decision == entity.RiskDecisionReview {
+		transaction.HoldForReview()
+	}
+
+	limit, _ := partner.SpendingLimit(req.Currency)
+	quotas := spendingQuotas(partnerID, req.UserID, req.Currency, limit, transaction.CreatedAt)
+	used, err := uc.spendingRepo.Reserve(ctx, spendingCounters(quotas), req.Amount)
+	if err != nil {
+		uc.forgetRisk(ctx, transaction)
+		if errors.Is(err, repository.ErrSpendingLimitReached) {
+			return nil, limitExceeded(quotas, used, req.Currency)
+		}
+		return nil, err
+	}
 
 	if err := uc.transactionRepo.Create(ctx, transaction); err != nil {
+		uc.spendingRepo.Release(ctx, spendingKeys(quotas), req.Amount)
+		uc.forgetRisk(ctx, transaction)
 		return nil, err
 	}
+	uc.metrics.TransactionStatusChanged(transaction)
+	uc.auditCreated(ctx, transaction)
 
 	return &TransactionResponse{
 		ID:        transaction.ID,
@@ -66,10 +158,19 @@ func (uc *TransactionUseCase) CreateTransaction(ctx context.Context, req CreateT
 	}, nil
 }
 
-func (uc *TransactionUseCase) GetTransaction(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+// GetTransaction returns one of partnerID's transactions. Transactions of
+// other partners are reported as not found, so their IDs cannot be probed.
+func (uc *TransactionUseCase) GetTransaction(ctx context.Context, partnerID, transactionID string) (_ *TransactionResponse, err error) {
+	ctx = logging.WithTransactionID(ctx, transactionID)
+	ctx, span := startSpan(ctx, "TransactionUseCase.GetTransaction",
+		attribute.String("partner.id", partnerID),
+		attribute.String("transaction.id", transactionID),
+	)
+	defer func() { endSpan(span, err) }()
+
 	transaction, err := uc.transactionRepo.FindByID(ctx, transactionID)
-	if err != nil {
-		return nil, errors.New("transaction not found")
+	if err != nil || transaction.PartnerID != partnerID {
+		return nil, ErrTransactionNotFound
 	}
 
 	return &TransactionResponse{
@@ -82,12 +183,22 @@ func (uc *TransactionUseCase) GetTransaction(ctx context.Context, transactionID
 	}, nil
 }
 
-func (uc *TransactionUseCase) UpdateTransactionStatus(ctx context.Context, transactionID string, status entity.TransactionStatus) error {
+func (uc *TransactionUseCase) UpdateTransactionStatus(ctx context.Context, transactionID string, status entity.TransactionStatus) (err error) {
+	ctx = logging.WithTransactionID(ctx, transactionID)
+	ctx, span := startSpan(ctx, "TransactionUseCase.UpdateTransactionStatus",
+		attribute.String("transaction.id", transactionID),
+		attribute.String("transaction.status", string(status)),
+	)
+	defer func() { endSpan(span, err) }()
+
 	transaction, err := uc.transactionRepo.FindByID(ctx, transactionID)
 	if err != nil {
-		return errors.New("transaction not found")
+		return ErrTransactionNotFound
 	}
 
+	before := transactionAuditView(transaction)
+	wasFailed := transaction.Status == entity.TransactionStatusFailed
+
 	switch status {
 	case entity.TransactionStatusSuccessful:
 		transaction.MarkSuccessful()
@@ -97,5 +208,58 @@ func (uc *TransactionUseCase) UpdateTransactionStatus(ctx context.Context, trans
 		return errors.New("invalid status")
 	}
 
-	return uc.transactionRepo.Update(ctx, transaction)
+	if err := uc.transactionRepo.Update(ctx, transaction); err != nil {
+		return err
+	}
+	uc.metrics.TransactionStatusChanged(transaction)
+
+	entry := entity.NewAuditEntry(entity.AuditActionTransactionStatusChanged, "", entity.AuditResourceTransaction, transaction.ID)
+	entry.SetChange(before, transactionAuditView(transaction))
+	uc.auditLog.RecordCommitted(ctx, entry)
+
+	if status == entity.TransactionStatusFailed && !wasFailed {
+		return releaseSpending(ctx, uc.spendingRepo, transaction)
+	}
+	return nil
+}
+
+// forgetRisk drops a purchase that will not be created from the risk
+// history, so it does not count towards later velocity checks.
+func (uc *TransactionUseCase) forgetRisk(ctx context.Context, txn *entity.Transaction) {
+	if err := uc.riskChecker.Forget(context.WithoutCancel(ctx), txn); err != nil {
+		logging.FromContext(ctx).Error("risk history rollback failed", "error", err)
+	}
+}
+
+func (uc *TransactionUseCase) auditCreated(ctx context.Context, txn *entity.Transaction) {
+	entry := entity.NewAuditEntry(entity.AuditActionTransactionCreated, "", entity.AuditResourceTransaction, txn.ID)
+	entry.SetChange(nil, transactionAuditView(txn))
+	uc.auditLog.RecordCommitted(ctx, entry)
+}
+
+// transactionAuditState is the transaction state kept in audit entries. The
+// phone number is left out so the audit log holds no customer contact data.
+type transactionAuditState struct {
+	PartnerID    string                   `json:"partnerId"`
+	UserID       string                   `json:"userId"`
+	WalletID     string                   `json:"walletId,omitempty"`
+	Amount       float64                  `json:"amount"`
+	Currency     string                   `json:"currency"`
+	Status       entity.TransactionStatus `json:"status"`
+	RiskDecision entity.RiskDecision      `json:"riskDecision,omitempty"`
+}
+
+func transactionAuditView(txn *entity.Transaction) transactionAuditState {
+	state := transactionAuditState{
+		PartnerID: txn.PartnerID,
+		UserID:    txn.UserID,
+		WalletID:  txn.WalletID,
+		Amount:    txn.Amount,
+		Currency:  txn.Currency,
+		Status:    txn.Status,
+	}
+	if txn.Risk != nil {
+		state.RiskDecision = txn.Risk.Decision
+	}
+	return state
 }
@@ -0,0 +1,195 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/risk"
+)
+
+type nopTransactionMetrics struct{}
+
+func (nopTransactionMetrics) TransactionStatusChanged(*entity.Transaction) {}
+
+type transactionTestEnv struct {
+	uc           *TransactionUseCase
+	partners     repository.PartnerRepository
+	transactions repository.TransactionRepository
+	spending     repository.SpendingRepository
+	history      repository.PurchaseHistoryRepository
+	audit        repository.AuditRepository
+}
+
+// newTransactionTestEnv builds a use case for partner_acme, which may spend
+// at most 250 USD per end user per day, checked by the default risk rules.
+// Audit entries go to audit, or to an in-memory store if it is nil.
+func newTransactionTestEnv(t *testing.T, audit repository.AuditRepository) *transactionTestEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	partners, err := inmemory.NewInMemoryPartnerRepository(ctx, nil)
+	if err != nil {
+		t.Fatalf("partner repository: %v", err)
+	}
+	partner := entity.NewPartner("partner_acme", "Acme", "acme", "wlt_acme")
+	partner.SpendingLimits = []entity.SpendingLimit{{Currency: "USD", PerUserDailyMax: 250}}
+	if err := partners.Create(ctx, partner); err != nil {
+		t.Fatalf("create partner: %v", err)
+	}
+
+	env := &transactionTestEnv{
+		partners:     partners,
+		transactions: inmemory.NewInMemoryTransactionRepository(),
+		spending:     inmemory.NewInMemorySpendingRepository(),
+		history:      inmemory.NewInMemoryPurchaseHistoryRepository(),
+		audit:        audit,
+	}
+	if env.audit == nil {
+		env.audit = inmemory.NewInMemoryAuditRepository()
+	}
+	engine, err := risk.NewEngine(env.history, "")
+	if err != nil {
+		t.Fatalf("risk engine: %v", err)
+	}
+	env.uc = NewTransactionUseCase(
+		env.transactions,
+		partners,
+		env.spending,
+		engine,
+		nopTransactionMetrics{},
+		NewAuditLog(env.audit),
+	)
+	return env
+}
+
+func (env *transactionTestEnv) purchase(userID, phone string, amount float64) (*TransactionResponse, error) {
+	return env.uc.CreateTransaction(context.Background(), "partner_acme", CreateTransactionRequest{
+		UserID:   userID,
+		Amount:   amount,
+		Currency: "USD",
+		Metadata: TransactionMetadata{PhoneNumber: phone},
+	})
+}
+
+func TestGetTransactionIsScopedToPartner(t *testing.T) {
+	env := newTransactionTestEnv(t, nil)
+	created, err := env.purchase("u1", "+254700000001", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := env.uc.GetTransaction(context.Background(), "partner_acme", created.ID)
+	if err != nil || got.ID != created.ID {
+		t.Fatalf("owner: got %+v, err %v", got, err)
+	}
+	if _, err := env.uc.GetTransaction(context.Background(), "partner_other", created.ID); !errors.Is(err, ErrTransactionNotFound) {
+		t.Fatalf("other partner: err = %v, want ErrTransactionNotFound", err)
+	}
+	if _, err := env.uc.GetTransaction(context.Background(), "partner_acme", "txn_missing"); !errors.Is(err, ErrTransactionNotFound) {
+		t.Fatalf("missing transaction: err = %v, want ErrTransactionNotFound", err)
+	}
+}
+
+// purchaseStep is one purchase in a test sequence, made by u1 unless user is
+// set, and the outcome expected for it.
+type purchaseStep struct {
+	user    string
+	amount  float64
+	wantErr error
+	limited bool
+}
+
+func TestCreateTransactionRiskHistory(t *testing.T) {
+	tests := []struct {
+		name         string
+		purchases    []purchaseStep
+		wantRecorded int
+	}{
+		{
+			name: "purchases over the spending limit are not recorded",
+			purchases: []purchaseStep{
+				{amount: 100},
+				{amount: 100},
+				{amount: 100, limited: true},
+			},
+			wantRecorded: 2,
+		},
+		{
+			name: "denied purchases are not recorded",
+			purchases: []purchaseStep{
+				{user: "u2", amount: 10},
+				{user: "u3", amount: 10},
+				{user: "u4", amount: 10},
+				{amount: 10, wantErr: ErrTransactionDenied},
+			},
+			wantRecorded: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTransactionTestEnv(t, nil)
+			for i, p := range tt.purchases {
+				user := p.user
+				if user == "" {
+					user = "u1"
+				}
+				_, err := env.purchase(user, "+254700000001", p.amount)
+				var limitErr *LimitExceededError
+				if p.limited && !errors.As(err, &limitErr) {
+					t.Fatalf("purchase %d: err = %v, want LimitExceededError", i+1, err)
+				}
+				if !p.limited && !errors.Is(err, p.wantErr) {
+					t.Fatalf("purchase %d: err = %v, want %v", i+1, err, p.wantErr)
+				}
+			}
+
+			recorded, err := env.history.FindByUser(context.Background(), "partner_acme", "u1", time.Now().Add(-time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(recorded) != tt.wantRecorded {
+				t.Fatalf("%d purchases by u1 in risk history, want %d", len(recorded), tt.wantRecorded)
+			}
+		})
+	}
+}
+
+type failingAuditRepository struct {
+	repository.AuditRepository
+}
+
+func (failingAuditRepository) Append(context.Context, *entity.AuditEntry) error {
+	return errors.New("audit store down")
+}
+
+func TestTransactionChangesStandWhenAuditFails(t *testing.T) {
+	env := newTransactionTestEnv(t, failingAuditRepository{})
+	ctx := context.Background()
+
+	created, err := env.purchase("u1", "+254700000001", 150)
+	if err != nil {
+		t.Fatalf("create with audit down: %v", err)
+	}
+	if _, err := env.transactions.FindByID(ctx, created.ID); err != nil {
+		t.Fatalf("created transaction not stored: %v", err)
+	}
+
+	if err := env.uc.UpdateTransactionStatus(ctx, created.ID, entity.TransactionStatusFailed); err != nil {
+		t.Fatalf("fail with audit down: %v", err)
+	}
+	stored, err := env.transactions.FindByID(ctx, created.ID)
+	if err != nil || stored.Status != entity.TransactionStatusFailed {
+		t.Fatalf("stored transaction = %+v, %v; want FAILED", stored, err)
+	}
+
+	// Failing released the 150 USD, so another purchase fits the 250 USD
+	// daily limit.
+	if _, err := env.purchase("u1", "+254700000001", 150); err != nil {
+		t.Fatalf("purchase after release: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction names an auditable operation.
+type AuditAction string
+
+const (
+	AuditActionTokenIssued              AuditAction = "TOKEN_ISSUED"
+	AuditActionTokenRevoked             AuditAction = "TOKEN_REVOKED"
+	AuditActionAuthFailed               AuditAction = "AUTH_FAILED"
+	AuditActionTransactionCreated       AuditAction = "TRANSACTION_CREATED"
+	AuditActionTransactionStatusChanged AuditAction = "TRANSACTION_STATUS_CHANGED"
+	AuditActionTransactionForceFailed   AuditAction = "TRANSACTION_FORCE_FAILED"
+	AuditActionTransactionRecovered     AuditAction = "TRANSACTION_RECOVERED"
+	AuditActionTransactionEscalated     AuditAction = "TRANSACTION_ESCALATED"
+	AuditActionReviewApproved           AuditAction = "REVIEW_APPROVED"
+	AuditActionReviewRejected           AuditAction = "REVIEW_REJECTED"
+	AuditActionPartnerCreated           AuditAction = "PARTNER_CREATED"
+	AuditActionPartnerConfigChanged     AuditAction = "PARTNER_CONFIG_CHANGED"
+	AuditActionPartnerSuspended         AuditAction = "PARTNER_SUSPENDED"
+	AuditActionPartnerReactivated       AuditAction = "PARTNER_REACTIVATED"
+	AuditActionCredentialRotated        AuditAction = "CREDENTIAL_ROTATED"
+	AuditActionCredentialRevoked        AuditAction = "CREDENTIAL_REVOKED"
+	AuditActionRiskRulesReloaded        AuditAction = "RISK_RULES_RELOADED"
+)
+
+// AuditActorSystem is the actor recorded for automatic actions.
+const AuditActorSystem = "system"
+
+// Resource types referenced by audit entries.
+const (
+	AuditResourceTransaction = "transaction"
+	AuditResourcePartner     = "partner"
+	AuditResourceClient      = "client"
+	AuditResourceRiskRules   = "risk_rules"
+)
+
+// AuditEntry records who did what to which resource. Entries form a hash
+// chain: each one's Hash covers its content and the previous entry's Hash,
+// so altering, removing or reordering an entry breaks every later link.
+type AuditEntry struct {
+	ID           string            `json:"id"`
+	Sequence     int64             `json:"sequence"`
+	Action       AuditAction       `json:"action"`
+	Actor        string            `json:"actor"`
+	IP           string            `json:"ip,omitempty"`
+	ResourceType string            `json:"resourceType"`
+	ResourceID   string            `json:"resourceId"`
+	Details      map[string]string `json:"details,omitempty"`
+	Before       json.RawMessage   `json:"before,omitempty"`
+	After        json.RawMessage   `json:"after,omitempty"`
+	Timestamp    time.Time         `json:"timestamp"`
+	PrevHash     string            `json:"prevHash"`
+	Hash         string            `json:"hash"`
+}
+
+func NewAuditEntry(action AuditAction, actor, resourceType, resourceID string) *AuditEntry {
+	return &AuditEntry{
+		ID:           "aud_" + uuid.New().String(),
+		Action:       action,
+		Actor:        actor,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Timestamp:    time.Now().UTC(),
+	}
+}
+
+// SetChange records the resource state before and after the action. Either
+// may be nil, e.g. when a resource is created.
+func (e *AuditEntry) SetChange(before, after any) {
+	e.Before = auditSnapshot(before)
+	e.After = auditSnapshot(after)
+}
+
+// Seal places the entry in the chain after the entry with prevHash.
+func (e *AuditEntry) Seal(sequence int64, prevHash string) {
+	e.Sequence = sequence
+	e.PrevHash = prevHash
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash returns the SHA-256 of the entry's content and PrevHash,
+// excluding Hash itself.
+func (e *AuditEntry) ComputeHash() string {
+	payload, _ := json.Marshal(struct {
+		ID           string            `json:"id"`
+		Sequence     int64             `json:"sequence"`
+		Action       AuditAction       `json:"action"`
+		Actor        string            `json:"actor"`
+		IP           string            `json:"ip"`
+		ResourceType string            `json:"resourceType"`
+		ResourceID   string            `json:"resourceId"`
+		Details      map[string]string `json:"details"`
+		Before       json.RawMessage   `json:"before"`
+		After        json.RawMessage   `json:"after"`
+		Timestamp    time.Time         `json:"timestamp"`
+		PrevHash     string            `json:"prevHash"`
+	}{
+		e.ID, e.Sequence, e.Action, e.Actor, e.IP, e.ResourceType, e.ResourceID,
+		e.Details, e.Before, e.After, e.Timestamp, e.PrevHash,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Clone returns a deep copy, so stored entries cannot be changed through
+// values handed to callers.
+func (e *AuditEntry) Clone() *AuditEntry {
+	c := *e
+	if e.Details != nil {
+		c.Details = make(map[string]string, len(e.Details))
+		for k, v := range e.Details {
+			c.Details[k] = v
+		}
+	}
+	c.Before = append(json.RawMessage(nil), e.Before...)
+	c.After = append(json.RawMessage(nil), e.After...)
+	return &c
+}
+
+func auditSnapshot(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -1,35 +1,318 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"time"
+)
 
 type PartnerStatus string
 
 const (
-	PartnerStatusActive   PartnerStatus = "ACTIVE"
-	PartnerStatusInactive PartnerStatus = "INACTIVE"
+	PartnerStatusActive          PartnerStatus = "ACTIVE"
+	PartnerStatusInactive        PartnerStatus = "INACTIVE"
+	PartnerStatusSuspended       PartnerStatus = "SUSPENDED"
+	PartnerStatusPendingApproval PartnerStatus = "PENDING_APPROVAL"
+)
+
+// AuthMode is a way a partner may authenticate API requests.
+type AuthMode string
+
+const (
+	AuthModeBearer AuthMode = "BEARER" // OAuth2 access token
+	AuthModeHMAC   AuthMode = "HMAC"   // per-request HMAC signature
 )
 
+var (
+	ErrPartnerInactive        = errors.New("partner is inactive")
+	ErrPartnerSuspended       = errors.New("partner is suspended")
+	ErrPartnerPendingApproval = errors.New("partner is pending approval")
+
+	ErrInvalidStatusTransition = errors.New("invalid partner status transition")
+)
+
+func IsKnownAuthMode(mode AuthMode) bool {
+	return mode == AuthModeBearer || mode == AuthModeHMAC
+}
+
+// ClientSecret is one of a partner's credentials. Only a slow salted hash of
+// the secret is stored; several secrets may be valid at once during rotation.
+type ClientSecret struct {
+	ID        string     `json:"id"`
+	Hash      string     `json:"-"` // Never expose in JSON
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// IsActive reports whether the secret may still be used to authenticate.
+func (s ClientSecret) IsActive(now time.Time) bool {
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+// RequestSigningKey is a shared secret for HMAC request signatures. Unlike
+// client secrets it must be stored recoverably, since the server recomputes
+// each signature. The secret is held either inline or, when SecretRef is
+// set, in the secret store under that name, where it can be rotated without
+// touching the partner record.
+type RequestSigningKey struct {
+	ID        string     `json:"id"`
+	Secret    string     `json:"-"` // Never expose in JSON
+	SecretRef string     `json:"-"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func (k RequestSigningKey) IsActive(now time.Time) bool {
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
 type Partner struct {
-	ID           string        `json:"id"`
-	Name         string        `json:"name"`
-	ClientID     string        `json:"clientId"`
-	ClientSecret string        `json:"-"` // Never expose in JSON
-	WalletID     string        `json:"walletId"`
-	Status       PartnerStatus `json:"status"`
-	CreatedAt    time.Time     `json:"createdAt"`
-	UpdatedAt    time.Time     `json:"updatedAt"`
+	ID            string              `json:"id"`
+	Name          string              `json:"name"`
+	ClientID      string              `json:"clientId"`
+	ClientSecrets []ClientSecret      `json:"-"` // Never expose in JSON
+	WalletID      string              `json:"walletId"`
+	Scopes        []string            `json:"scopes"`
+	AuthModes     []AuthMode          `json:"authModes"`
+	SigningKeys   []RequestSigningKey `json:"-"` // Never expose in JSON
+
+	// Optional mutual TLS binding: when set, the partner must present a client
+	// certificate with one of these RFC 8705 x5t#S256 thumbprints or subject
+	// distinguished names.
+	ClientCertThumbprints []string `json:"clientCertThumbprints,omitempty"`
+	ClientCertSubjects    []string `json:"clientCertSubjects,omitempty"`
+
+	// AllowedCIDRs restricts the source addresses the partner may call from.
+	// An empty list allows any address.
+	AllowedCIDRs []string `json:"allowedCidrs,omitempty"`
+
+	// RateLimits overrides the default per-minute limit for route groups.
+	RateLimits map[RateLimitGroup]int `json:"rateLimits,omitempty"`
+
+	// SpendingLimits caps purchase volume per currency.
+	SpendingLimits []SpendingLimit `json:"spendingLimits,omitempty"`
+
+	// AllowedTransactionTypes restricts the transactions the partner may
+	// create. An empty list allows every type.
+	AllowedTransactionTypes []TransactionType `json:"allowedTransactionTypes,omitempty"`
+
+	Status    PartnerStatus `json:"status"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+
+	// Version counts stored updates, so that concurrent read-modify-write
+	// cycles cannot overwrite each other.
+	Version int64 `json:"-"`
 }
 
-func NewPartner(id, name, clientID, clientSecret, walletID string) *Partner {
+func NewPartner(id, name, clientID, walletID string) *Partner {
 	now := time.Now()
 	return &Partner{
-		ID:           id,
-		Name:         name,
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		WalletID:     walletID,
-		Status:       PartnerStatusActive,
-		CreatedAt:    now,
-		UpdatedAt:    now,
+		ID:        id,
+		Name:      name,
+		ClientID:  clientID,
+		WalletID:  walletID,
+		Status:    PartnerStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// CheckActive returns an error describing why the partner may not use the
+// API, or nil if its status is ACTIVE.
+func (p *Partner) CheckActive() error {
+	switch p.Status {
+	case PartnerStatusActive:
+		return nil
+	case PartnerStatusSuspended:
+		return ErrPartnerSuspended
+	case PartnerStatusPendingApproval:
+		return ErrPartnerPendingApproval
+	default:
+		return ErrPartnerInactive
+	}
+}
+
+// Suspend blocks the partner from the API until it is reactivated.
+func (p *Partner) Suspend() error {
+	if p.Status != PartnerStatusActive && p.Status != PartnerStatusPendingApproval {
+		return ErrInvalidStatusTransition
+	}
+	p.Status = PartnerStatusSuspended
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// Reactivate returns a suspended, inactive or pending partner to ACTIVE.
+func (p *Partner) Reactivate() error {
+	if p.Status == PartnerStatusActive {
+		return ErrInvalidStatusTransition
+	}
+	p.Status = PartnerStatusActive
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// AllowsTransactionType reports whether the partner may create transactions
+// of type t.
+func (p *Partner) AllowsTransactionType(t TransactionType) bool {
+	if len(p.AllowedTransactionTypes) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedTransactionTypes {
+		if allowed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the partner has been granted scope.
+func (p *Partner) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
 	}
+	return false
+}
+
+// AcceptsAuthMode reports whether the partner may authenticate with mode.
+// Partners without explicit configuration accept bearer tokens only.
+func (p *Partner) AcceptsAuthMode(mode AuthMode) bool {
+	if len(p.AuthModes) == 0 {
+		return mode == AuthModeBearer
+	}
+	for _, m := range p.AuthModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresClientCertificate reports whether the partner is bound to a client certificate.
+func (p *Partner) RequiresClientCertificate() bool {
+	return len(p.ClientCertThumbprints) > 0 || len(p.ClientCertSubjects) > 0
+}
+
+// MatchesClientCertificate reports whether a presented certificate satisfies
+// the partner's binding. Partners without a binding match any certificate.
+func (p *Partner) MatchesClientCertificate(thumbprint, subject string) bool {
+	if !p.RequiresClientCertificate() {
+		return true
+	}
+	if thumbprint == "" {
+		return false
+	}
+	for _, t := range p.ClientCertThumbprints {
+		if t == thumbprint {
+			return true
+		}
+	}
+	for _, s := range p.ClientCertSubjects {
+		if s == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimit returns the partner's per-minute limit for group, applying any
+// override over the default.
+func (p *Partner) RateLimit(group RateLimitGroup) int {
+	if limit, ok := p.RateLimits[group]; ok {
+		return limit
+	}
+	return DefaultRateLimits[group]
+}
+
+// AllowsIP reports whether ip falls within the partner's allowed ranges.
+// Unparseable addresses are rejected whenever an allowlist is configured.
+func (p *Partner) AllowsIP(ip string) bool {
+	if len(p.AllowedCIDRs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, cidr := range p.AllowedCIDRs {
+		prefix, err := ParseCIDR(cidr)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCIDR parses a CIDR range or a single address, which is treated as a
+// full-length prefix. The result is masked to its network address.
+func ParseCIDR(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// ActiveSigningKeys returns the request signing keys currently valid.
+func (p *Partner) ActiveSigningKeys(now time.Time) []RequestSigningKey {
+	var active []RequestSigningKey
+	for _, k := range p.SigningKeys {
+		if k.IsActive(now) {
+			active = append(active, k)
+		}
+	}
+	return active
+}
+
+// AddClientSecret registers a new secret and drops secrets that have expired.
+func (p *Partner) AddClientSecret(secret ClientSecret) {
+	p.PruneClientSecrets(time.Now())
+	p.ClientSecrets = append(p.ClientSecrets, secret)
+	p.UpdatedAt = time.Now()
+}
+
+// ActiveClientSecrets returns the secrets currently valid for authentication.
+func (p *Partner) ActiveClientSecrets(now time.Time) []ClientSecret {
+	var active []ClientSecret
+	for _, s := range p.ClientSecrets {
+		if s.IsActive(now) {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// ExpireClientSecret sets the expiry of the secret with the given ID,
+// returning false if no such secret exists.
+func (p *Partner) ExpireClientSecret(id string, at time.Time) bool {
+	for i := range p.ClientSecrets {
+		if p.ClientSecrets[i].ID == id {
+			if p.ClientSecrets[i].ExpiresAt == nil || at.Before(*p.ClientSecrets[i].ExpiresAt) {
+				p.ClientSecrets[i].ExpiresAt = &at
+			}
+			p.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Partner) PruneClientSecrets(now time.Time) {
+	p.ClientSecrets = p.ActiveClientSecrets(now)
 }
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// RateLimitGroup identifies a set of routes that share a rate limit.
+type RateLimitGroup string
+
+const (
+	RateLimitGroupAuth         RateLimitGroup = "auth"
+	RateLimitGroupWallets      RateLimitGroup = "wallets"
+	RateLimitGroupTransactions RateLimitGroup = "transactions"
+	RateLimitGroupWebhooks     RateLimitGroup = "webhooks"
+	RateLimitGroupCredentials  RateLimitGroup = "credentials"
+)
+
+// RateLimitWindow is the period over which request limits are counted.
+const RateLimitWindow = time.Minute
+
+// SourceIPRateLimit is the per-minute limit per source IP on authenticated
+// and admin routes. It is checked before authentication, so floods of
+// unauthenticated or badly authenticated requests are limited too.
+const SourceIPRateLimit = 300
+
+// DefaultRateLimits holds the documented per-minute limits for each group.
+var DefaultRateLimits = map[RateLimitGroup]int{
+	RateLimitGroupAuth:         10,
+	RateLimitGroupWallets:      100,
+	RateLimitGroupTransactions: 100,
+	RateLimitGroupWebhooks:     20,
+	RateLimitGroupCredentials:  10,
+}
+
+func IsKnownRateLimitGroup(group RateLimitGroup) bool {
+	_, ok := DefaultRateLimits[group]
+	return ok
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+// RefreshToken is an opaque, single-use token that can be exchanged for a new
+// access token. Tokens issued by rotation share a FamilyID so that reuse of a
+// rotated token can revoke the whole chain. Only the token hash is stored.
+type RefreshToken struct {
+	ID                   string     `json:"id"`
+	FamilyID             string     `json:"familyId"`
+	TokenHash            string     `json:"-"`
+	PartnerID            string     `json:"partnerId"`
+	ClientID             string     `json:"clientId"`
+	Scope                string     `json:"scope,omitempty"`
+	AccessTokenID        string     `json:"accessTokenId"`
+	AccessTokenExpiresAt time.Time  `json:"accessTokenExpiresAt"`
+	ExpiresAt            time.Time  `json:"expiresAt"`
+	CreatedAt            time.Time  `json:"createdAt"`
+	UsedAt               *time.Time `json:"usedAt,omitempty"`
+	RevokedAt            *time.Time `json:"revokedAt,omitempty"`
+}
+
+func NewRefreshToken(id, familyID, tokenHash, partnerID, clientID, scope string, expiresIn time.Duration) *RefreshToken {
+	now := time.Now()
+	return &RefreshToken{
+		ID:        id,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		PartnerID: partnerID,
+		ClientID:  clientID,
+		Scope:     scope,
+		ExpiresAt: now.Add(expiresIn),
+		CreatedAt: now,
+	}
+}
+
+// IsActive reports whether the token can still be exchanged.
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+func (t *RefreshToken) Revoke() {
+	if t.RevokedAt == nil {
+		now := time.Now()
+		t.RevokedAt = &now
+	}
+}
@@ -0,0 +1,54 @@
+package entity
+
+// RiskDecision is the outcome of the risk check on a purchase.
+type RiskDecision string
+
+const (
+	RiskDecisionAllow  RiskDecision = "ALLOW"
+	RiskDecisionReview RiskDecision = "REVIEW"
+	RiskDecisionDeny   RiskDecision = "DENY"
+)
+
+// Severity orders decisions so the strictest triggered rule wins.
+func (d RiskDecision) Severity() int {
+	switch d {
+	case RiskDecisionReview:
+		return 1
+	case RiskDecisionDeny:
+		return 2
+	}
+	return 0
+}
+
+func IsKnownRiskDecision(d RiskDecision) bool {
+	return d == RiskDecisionAllow || d == RiskDecisionReview || d == RiskDecisionDeny
+}
+
+// TriggeredRule records a risk rule that matched a purchase.
+type TriggeredRule struct {
+	Rule     string       `json:"rule"`
+	Decision RiskDecision `json:"decision"`
+	Reason   string       `json:"reason"`
+}
+
+// RiskAssessment is the risk check result stored on a transaction.
+type RiskAssessment struct {
+	Decision       RiskDecision    `json:"decision"`
+	TriggeredRules []TriggeredRule `json:"triggeredRules,omitempty"`
+	RulesVersion   string          `json:"rulesVersion,omitempty"`
+}
+
+// NewRiskAssessment combines triggered rules into a single decision.
+func NewRiskAssessment(triggered []TriggeredRule, rulesVersion string) *RiskAssessment {
+	decision := RiskDecisionAllow
+	for _, t := range triggered {
+		if t.Decision.Severity() > decision.Severity() {
+			decision = t.Decision
+		}
+	}
+	return &RiskAssessment{
+		Decision:       decision,
+		TriggeredRules: triggered,
+		RulesVersion:   rulesVersion,
+	}
+}
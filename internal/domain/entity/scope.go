@@ -0,0 +1,48 @@
+package entity
+
+import "strings"
+
+// OAuth scopes a partner can be granted and request in access tokens.
+const (
+	ScopeTransactionsWrite = "transactions:write"
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeWalletsRead       = "wallets:read"
+	ScopeWebhooksManage    = "webhooks:manage"
+	ScopeCredentialsManage = "credentials:manage"
+)
+
+// AllScopes lists every scope the API understands.
+var AllScopes = []string{
+	ScopeTransactionsWrite,
+	ScopeTransactionsRead,
+	ScopeWalletsRead,
+	ScopeWebhooksManage,
+	ScopeCredentialsManage,
+}
+
+func IsKnownScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScope splits a space-delimited scope string (RFC 6749 section 3.3),
+// dropping duplicates while preserving order.
+func ParseScope(scope string) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, s := range strings.Fields(scope) {
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+type SecurityEventType string
+
+const (
+	SecurityEventLoginLockout SecurityEventType = "AUTH_LOCKOUT"
+	SecurityEventIPNotAllowed SecurityEventType = "IP_NOT_ALLOWED"
+)
+
+// SecurityEvent records a security-relevant occurrence for monitoring.
+type SecurityEvent struct {
+	Type      SecurityEventType `json:"type"`
+	PartnerID string            `json:"partnerId,omitempty"`
+	ClientID  string            `json:"clientId,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	Reason    string            `json:"reason,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+func NewSecurityEvent(eventType SecurityEventType, reason string) *SecurityEvent {
+	return &SecurityEvent{
+		Type:      eventType,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// SpendingLimitType identifies which spending quota a transaction counts against.
+type SpendingLimitType string
+
+const (
+	SpendingLimitDaily     SpendingLimitType = "DAILY"
+	SpendingLimitMonthly   SpendingLimitType = "MONTHLY"
+	SpendingLimitUserDaily SpendingLimitType = "USER_DAILY"
+)
+
+// SpendingLimit caps a partner's purchase volume in one currency. Zero values
+// mean no limit. Days and months are calendar periods in UTC.
+type SpendingLimit struct {
+	Currency        string  `json:"currency"`
+	DailyMax        float64 `json:"dailyMax,omitempty"`
+	MonthlyMax      float64 `json:"monthlyMax,omitempty"`
+	PerUserDailyMax float64 `json:"perUserDailyMax,omitempty"`
+}
+
+// Max returns the cap for the given quota type, or zero if unlimited.
+func (l SpendingLimit) Max(limitType SpendingLimitType) float64 {
+	switch limitType {
+	case SpendingLimitDaily:
+		return l.DailyMax
+	case SpendingLimitMonthly:
+		return l.MonthlyMax
+	case SpendingLimitUserDaily:
+		return l.PerUserDailyMax
+	}
+	return 0
+}
+
+// SpendingPeriod returns the start and end of the UTC day or month containing t.
+func SpendingPeriod(limitType SpendingLimitType, t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	if limitType == SpendingLimitMonthly {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// SpendingLimit returns the partner's limit for currency, if one is configured.
+func (p *Partner) SpendingLimit(currency string) (SpendingLimit, bool) {
+	for _, limit := range p.SpendingLimits {
+		if strings.EqualFold(limit.Currency, currency) {
+			return limit, true
+		}
+	}
+	return SpendingLimit{}, false
+}
@@ -9,27 +9,89 @@ const (
 	TransactionStatusPending    TransactionStatus = "PENDING"
 	TransactionStatusSuccessful TransactionStatus = "SUCCESSFUL"
 	TransactionStatusFailed     TransactionStatus = "FAILED"
+	// TransactionStatusHeldForReview marks a purchase the risk check flagged
+	// for manual review. Approval returns it to PENDING; rejection fails it.
+	TransactionStatusHeldForReview TransactionStatus = "HELD_FOR_REVIEW"
 
 	TransactionTypeCreditPurchase TransactionType = "CREDIT_PURCHASE"
 )
 
+// AllTransactionTypes lists every transaction type the API supports.
+var AllTransactionTypes = []TransactionType{
+	TransactionTypeCreditPurchase,
+}
+
+func IsKnownTransactionType(t TransactionType) bool {
+	for _, known := range AllTransactionTypes {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionMetadata carries purchase details supplied by the partner.
+type TransactionMetadata struct {
+	PhoneNumber string `json:"phoneNumber,omitempty"`
+	Provider    string `json:"provider,omitempty"`
+	ProductID   string `json:"productId,omitempty"`
+}
+
+// ReviewOutcome is a reviewer's decision on a held transaction.
+type ReviewOutcome string
+
+const (
+	ReviewOutcomeApproved ReviewOutcome = "APPROVED"
+	ReviewOutcomeRejected ReviewOutcome = "REJECTED"
+)
+
+// ReviewDecision records how a held transaction was resolved.
+type ReviewDecision struct {
+	Outcome   ReviewOutcome `json:"outcome"`
+	Reviewer  string        `json:"reviewer"`
+	Reason    string        `json:"reason"`
+	Automatic bool          `json:"automatic,omitempty"`
+	DecidedAt time.Time     `json:"decidedAt"`
+}
+
+// RecoveryState records the stuck-transaction sweeper's attempts to settle
+// a transaction with the provisioning gateway.
+type RecoveryState struct {
+	Attempts      int       `json:"attempts"`
+	LastAttemptAt time.Time `json:"lastAttemptAt"`
+	LastOutcome   string    `json:"lastOutcome"`
+	// EscalatedAt is set once the sweeper gives up and hands the
+	// transaction to operators; it is not checked again after that.
+	EscalatedAt *time.Time `json:"escalatedAt,omitempty"`
+}
+
 type Transaction struct {
-	ID        string            `json:"transactionId"`
-	UserID    string            `json:"userId"`
-	Amount    float64           `json:"amount"`
-	Currency  string            `json:"currency"`
-	Status    TransactionStatus `json:"status"`
-	Timestamp time.Time         `json:"timestamp"`
+	ID        string              `json:"transactionId"`
+	PartnerID string              `json:"partnerId"`
+	UserID    string              `json:"userId"`
+	WalletID  string              `json:"walletId,omitempty"`
+	Amount    float64             `json:"amount"`
+	Currency  string              `json:"currency"`
+	Status    TransactionStatus   `json:"status"`
+	Metadata  TransactionMetadata `json:"metadata"`
+	Risk      *RiskAssessment     `json:"risk,omitempty"`
+	Review    *ReviewDecision     `json:"review,omitempty"`
+	Recovery  *RecoveryState      `json:"recovery,omitempty"`
+	Timestamp time.Time           `json:"timestamp"`
+	CreatedAt time.Time           `json:"createdAt"`
 }
 
-func NewTransaction(id, userID, currency string, amount float64) *Transaction {
+func NewTransaction(id, partnerID, userID, currency string, amount float64) *Transaction {
+	now := time.Now()
 	return &Transaction{
 		ID:        id,
+		PartnerID: partnerID,
 		UserID:    userID,
 		Amount:    amount,
 		Currency:  currency,
 		Status:    TransactionStatusPending,
-		Timestamp: time.Now(),
+		Timestamp: now,
+		CreatedAt: now,
 	}
 }
 
@@ -42,3 +104,58 @@ func (t *Transaction) MarkFailed() {
 	t.Status = TransactionStatusFailed
 	t.Timestamp = time.Now()
 }
+
+func (t *Transaction) HoldForReview() {
+	t.Status = TransactionStatusHeldForReview
+	t.Timestamp = time.Now()
+}
+
+// Approve releases a held transaction back into processing.
+func (t *Transaction) Approve(reviewer, reason string, automatic bool) {
+	t.Review = &ReviewDecision{
+		Outcome:   ReviewOutcomeApproved,
+		Reviewer:  reviewer,
+		Reason:    reason,
+		Automatic: automatic,
+		DecidedAt: time.Now(),
+	}
+	t.Status = TransactionStatusPending
+	t.Timestamp = t.Review.DecidedAt
+}
+
+// Reject fails a held transaction.
+func (t *Transaction) Reject(reviewer, reason string, automatic bool) {
+	t.Review = &ReviewDecision{
+		Outcome:   ReviewOutcomeRejected,
+		Reviewer:  reviewer,
+		Reason:    reason,
+		Automatic: automatic,
+		DecidedAt: time.Now(),
+	}
+	t.Status = TransactionStatusFailed
+	t.Timestamp = t.Review.DecidedAt
+}
+
+// RecordRecoveryAttempt notes a gateway check and its outcome. The status
+// timestamp is left alone so the time spent PENDING stays measurable.
+func (t *Transaction) RecordRecoveryAttempt(outcome string) {
+	if t.Recovery == nil {
+		t.Recovery = &RecoveryState{}
+	}
+	t.Recovery.Attempts++
+	t.Recovery.LastAttemptAt = time.Now()
+	t.Recovery.LastOutcome = outcome
+}
+
+// Escalate marks a transaction the sweeper could not settle.
+func (t *Transaction) Escalate() {
+	if t.Recovery == nil {
+		t.Recovery = &RecoveryState{}
+	}
+	now := time.Now()
+	t.Recovery.EscalatedAt = &now
+}
+
+func (t *Transaction) IsEscalated() bool {
+	return t.Recovery != nil && t.Recovery.EscalatedAt != nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+// AuditFilter selects audit entries. Zero fields match everything.
+type AuditFilter struct {
+	Action       entity.AuditAction
+	Actor        string
+	ResourceType string
+	ResourceID   string
+	Since        time.Time
+	Until        time.Time
+	// AfterSequence returns only entries with a higher sequence number, for
+	// paging through results.
+	AfterSequence int64
+	// Limit caps the number of entries returned; zero means no limit.
+	Limit int
+}
+
+// AuditRepository stores audit entries. Entries are never modified or deleted.
+type AuditRepository interface {
+	// Append seals entry onto the end of the hash chain, assigning its
+	// sequence number, previous hash and hash.
+	Append(ctx context.Context, entry *entity.AuditEntry) error
+	FindByResource(ctx context.Context, resourceType, resourceID string) ([]*entity.AuditEntry, error)
+	// Query returns matching entries in sequence order.
+	Query(ctx context.Context, filter AuditFilter) ([]*entity.AuditEntry, error)
+}
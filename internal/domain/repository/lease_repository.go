@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// LeaseRepository grants named, expiring leases so that work meant for one
+// instance at a time, such as a background sweep or a change to a single
+// transaction, is not done twice. It maps onto SET NX PX in Redis or a lease
+// row in a database; the expiry frees leases held by a crashed instance.
+type LeaseRepository interface {
+	// Acquire takes the lease for owner, or extends it if owner already holds
+	// it. It reports false while another owner's lease is unexpired.
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Release gives the lease up if owner holds it.
+	Release(ctx context.Context, name, owner string) error
+}
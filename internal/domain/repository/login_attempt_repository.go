@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// LoginAttemptRepository tracks failed authentication attempts and lockouts
+// per key, where a key identifies a client ID or a source IP.
+type LoginAttemptRepository interface {
+	// RecordFailure adds a failure and returns how many failures the key has
+	// accumulated within window.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Reset(ctx context.Context, key string) error
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil returns the end of the key's lockout, or the zero time.
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// NonceRepository remembers request nonces to reject replays.
+type NonceRepository interface {
+	// Remember stores nonce until expiresAt and reports whether it was new.
+	Remember(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+}
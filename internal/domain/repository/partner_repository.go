@@ -2,11 +2,36 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
 )
 
+// ErrPartnerExists is returned by Create when the partner ID or client ID is
+// already taken.
+var ErrPartnerExists = errors.New("partner already exists")
+
+// ErrPartnerConflict is returned by Update when the partner has been updated
+// since it was read.
+var ErrPartnerConflict = errors.New("partner was updated concurrently")
+
+// PartnerFilter selects partners for List. Zero fields match everything.
+// Results are ordered by ID; AfterID continues from a previous page.
+type PartnerFilter struct {
+	Status  entity.PartnerStatus
+	AfterID string
+	Limit   int
+}
+
 type PartnerRepository interface {
 	FindByClientID(ctx context.Context, clientID string) (*entity.Partner, error)
 	FindByID(ctx context.Context, id string) (*entity.Partner, error)
+	// FindByIDForUpdate reads the stored partner, bypassing any cache, for a
+	// caller that is about to pass it to Update.
+	FindByIDForUpdate(ctx context.Context, id string) (*entity.Partner, error)
+	Create(ctx context.Context, partner *entity.Partner) error
+	// Update stores the partner if its Version still matches the stored
+	// one, and increments Version; otherwise it returns ErrPartnerConflict.
+	Update(ctx context.Context, partner *entity.Partner) error
+	List(ctx context.Context, filter PartnerFilter) ([]*entity.Partner, error)
 }
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// PurchaseRecord is the slice of a purchase that velocity rules look at.
+type PurchaseRecord struct {
+	TransactionID string
+	PartnerID     string
+	UserID        string
+	WalletID      string
+	PhoneNumber   string
+	Amount        float64
+	Currency      string
+	At            time.Time
+}
+
+// PurchaseHistoryRepository keeps recent purchases for velocity checks.
+// Purchases are recorded before they are checked, so concurrent purchases
+// always see each other, and removed again if they do not go ahead.
+type PurchaseHistoryRepository interface {
+	Record(ctx context.Context, record PurchaseRecord) error
+	// Remove deletes the record of transactionID, if there is one.
+	Remove(ctx context.Context, transactionID string) error
+	FindByPhoneNumber(ctx context.Context, phoneNumber string, since time.Time) ([]PurchaseRecord, error)
+	// FindByUser returns partnerID's purchases for userID; end-user IDs are
+	// only unique within a partner.
+	FindByUser(ctx context.Context, partnerID, userID string, since time.Time) ([]PurchaseRecord, error)
+	// WalletFirstSeen returns when a purchase from walletID was first recorded,
+	// or the zero time if it never was or the wallet has been inactive for
+	// longer than the store remembers wallets.
+	WalletFirstSeen(ctx context.Context, walletID string) (time.Time, error)
+}
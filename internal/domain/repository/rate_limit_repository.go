@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRateLimitCapacity is returned by a size-bounded store that is tracking
+// as many keys as it may.
+var ErrRateLimitCapacity = errors.New("rate limit store is full")
+
+// RateLimitRepository counts requests per key in fixed windows. It maps
+// directly onto an atomic INCR with expiry in stores such as Redis.
+type RateLimitRepository interface {
+	// Increment counts a request against key and returns the count within the
+	// current window and when that window ends.
+	Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
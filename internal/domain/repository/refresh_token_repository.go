@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+// ErrRefreshTokenUsed is returned by MarkUsed when the token was already exchanged.
+var ErrRefreshTokenUsed = errors.New("refresh token already used")
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *entity.RefreshToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	FindByFamily(ctx context.Context, familyID string) ([]*entity.RefreshToken, error)
+	Update(ctx context.Context, token *entity.RefreshToken) error
+	// MarkUsed atomically flags the token as exchanged, returning
+	// ErrRefreshTokenUsed if another request already did so.
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// RevokedTokenRepository is a deny-list of access token IDs (jti). Entries
+// only need to be kept until the token would have expired anyway.
+type RevokedTokenRepository interface {
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
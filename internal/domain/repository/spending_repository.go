@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSpendingLimitReached is returned by Reserve when a counter would exceed
+// its limit.
+var ErrSpendingLimitReached = errors.New("spending limit reached")
+
+// SpendingCounter is a running total of purchase volume for one quota period.
+type SpendingCounter struct {
+	Key string
+	// Limit is the maximum total; zero means unlimited.
+	Limit float64
+	// ExpiresAt is when the counter may be discarded.
+	ExpiresAt time.Time
+}
+
+// SpendingRepository tracks purchase volume against spending quotas.
+type SpendingRepository interface {
+	// Reserve atomically adds amount to every counter, or to none of them if
+	// any would exceed its limit. It returns the counters' totals: after the
+	// addition on success, or unchanged alongside ErrSpendingLimitReached.
+	Reserve(ctx context.Context, counters []SpendingCounter, amount float64) ([]float64, error)
+	// Release subtracts amount from the counters, e.g. when a purchase fails.
+	Release(ctx context.Context, keys []string, amount float64) error
+	// Usage returns the current totals for keys; unknown keys are zero.
+	Usage(ctx context.Context, keys []string) ([]float64, error)
+}
@@ -2,14 +2,29 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
 )
 
+// TransactionFilter selects transactions for List. Zero fields match
+// everything. Results are ordered oldest first; AfterID continues from the
+// last transaction of a previous page.
+type TransactionFilter struct {
+	PartnerID string
+	Status    entity.TransactionStatus
+	Since     time.Time // inclusive
+	Until     time.Time // exclusive
+	AfterID   string
+	Limit     int
+}
+
 type TransactionRepository interface {
 	Create(ctx context.Context, transaction *entity.Transaction) error
 	FindByID(ctx context.Context, id string) (*entity.Transaction, error)
+	FindByStatus(ctx context.Context, status entity.TransactionStatus) ([]*entity.Transaction, error)
 	FindByIdempotencyKey(ctx context.Context, key string) (*entity.Transaction, error)
 	Update(ctx context.Context, transaction *entity.Transaction) error
 	StoreIdempotencyKey(ctx context.Context, key, transactionID string) error
+	List(ctx context.Context, filter TransactionFilter) ([]*entity.Transaction, error)
 }
@@ -5,45 +5,93 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// JWTService issues and verifies access tokens signed with the asymmetric keys
+// held by a KeyManager. Tokens carry the signing key's kid in their header.
 type JWTService struct {
-	secretKey []byte
+	keys *KeyManager
 }
 
 type Claims struct {
-	PartnerID string `json:"partnerId"`
-	ClientID  string `json:"clientId"`
+	PartnerID    string        `json:"partnerId"`
+	ClientID     string        `json:"clientId"`
+	Scope        string        `json:"scope,omitempty"` // space-delimited, as in RFC 9068
+	Confirmation *Confirmation `json:"cnf,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(secretKey string) *JWTService {
+// Confirmation binds a token to the client certificate it was issued over
+// (RFC 8705 section 3.1).
+type Confirmation struct {
+	X5tS256 string `json:"x5t#S256"`
+}
+
+// CertificateThumbprint returns the bound certificate thumbprint, if any.
+func (c *Claims) CertificateThumbprint() string {
+	if c.Confirmation == nil {
+		return ""
+	}
+	return c.Confirmation.X5tS256
+}
+
+func NewJWTService(keys *KeyManager) *JWTService {
 	return &JWTService{
-		secretKey: []byte(secretKey),
+		keys: keys,
 	}
 }
 
-func (s *JWTService) GenerateToken(partnerID, clientID string, expiresIn time.Duration) (string, error) {
+// GenerateToken issues a signed access token with a unique ID (jti) and
+// returns the claims so callers can track the token for revocation. A
+// non-empty certThumbprint binds the token to that client certificate.
+func (s *JWTService) GenerateToken(partnerID, clientID, scope, certThumbprint string, expiresIn time.Duration) (string, *Claims, error) {
+	now := time.Now()
 	claims := &Claims{
 		PartnerID: partnerID,
 		ClientID:  clientID,
+		Scope:     scope,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secretKey)
+	if certThumbprint != "" {
+		claims.Confirmation = &Confirmation{X5tS256: certThumbprint}
+	}
+
+	key := s.keys.SigningKey()
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.KID
+
+	signed, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, claims, nil
 }
 
 func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("missing key id")
+		}
+
+		key, ok := s.keys.VerificationKey(kid)
+		if !ok {
+			return nil, errors.New("unknown key id")
+		}
+
+		if token.Method.Alg() != string(key.Algorithm) {
 			return nil, errors.New("invalid signing method")
 		}
-		return s.secretKey, nil
-	})
+
+		return key.Private.Public(), nil
+	}, jwt.WithValidMethods(s.keys.Algorithms()))
 
 	if err != nil {
 		return nil, err
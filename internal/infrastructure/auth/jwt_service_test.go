@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestJWTService(t *testing.T, alg Algorithm, grace time.Duration) (*JWTService, *KeyManager) {
+	t.Helper()
+	keys, err := NewKeyManager(KeyManagerConfig{Algorithm: alg, GracePeriod: grace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewJWTService(keys), keys
+}
+
+func TestJWTServiceRoundTrip(t *testing.T) {
+	for _, alg := range []Algorithm{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		t.Run(string(alg), func(t *testing.T) {
+			svc, keys := newTestJWTService(t, alg, time.Hour)
+
+			token, issued, err := svc.GenerateToken("partner-1", "client-1", "credit:write", "thumb", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := svc.ValidateToken(token)
+			if err != nil {
+				t.Fatalf("ValidateToken: %v", err)
+			}
+			if claims.PartnerID != "partner-1" || claims.ClientID != "client-1" || claims.Scope != "credit:write" {
+				t.Fatalf("claims = %+v", claims)
+			}
+			if claims.ID == "" || claims.ID != issued.ID {
+				t.Fatalf("jti %q, issued %q", claims.ID, issued.ID)
+			}
+			if got := claims.CertificateThumbprint(); got != "thumb" {
+				t.Fatalf("certificate thumbprint %q, want thumb", got)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kid := parsed.Header["kid"]; kid != keys.SigningKey().KID {
+				t.Fatalf("kid %v, want %s", kid, keys.SigningKey().KID)
+			}
+		})
+	}
+}
+
+func TestJWTServiceRejects(t *testing.T) {
+	svc, keys := newTestJWTService(t, AlgorithmES256, time.Hour)
+	other, _ := newTestJWTService(t, AlgorithmES256, time.Hour)
+	current := keys.SigningKey()
+
+	valid, _, err := svc.GenerateToken("partner-1", "client-1", "", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(method jwt.SigningMethod, header map[string]any, key any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, &Claims{
+			PartnerID: "partner-1",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		for k, v := range header {
+			token.Header[k] = v
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(current.Private.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(valid, ".")
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"partnerId":"partner-2"}`)) + "." + parts[2]
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: func() string {
+			token, _, err := svc.GenerateToken("partner-1", "client-1", "", "", -time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}()},
+		{name: "missing kid", token: sign(jwt.SigningMethodES256, nil, current.Private)},
+		{name: "unknown kid", token: func() string {
+			token, _, err := other.GenerateToken("partner-1", "client-1", "", "", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}()},
+		{name: "alg none", token: sign(jwt.SigningMethodNone, map[string]any{"kid": current.KID}, jwt.UnsafeAllowNoneSignatureType)},
+		{name: "HMAC keyed with the public key", token: sign(jwt.SigningMethodHS256, map[string]any{"kid": current.KID}, publicDER)},
+		{name: "tampered claims", token: tampered},
+		{name: "garbage", token: "not.a.token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := svc.ValidateToken(tt.token); err == nil {
+				t.Fatalf("ValidateToken accepted the token: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestJWTServiceRejectsAlgorithmMismatch(t *testing.T) {
+	// An RS256 key still in its grace period is published alongside the
+	// ES256 one, so RS256 is an accepted method overall; a token naming the
+	// ES256 key must still be refused when it claims RS256.
+	svc, keys := newTestJWTService(t, AlgorithmES256, time.Hour)
+	ecKID := keys.SigningKey().KID
+
+	rsa, err := GenerateSigningKey(AlgorithmRS256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsa.RetireAt = time.Now().Add(time.Hour)
+	keys.retired = append(keys.retired, rsa)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &Claims{
+		PartnerID: "partner-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	token.Header["kid"] = ecKID
+	signed, err := token.SignedString(rsa.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ValidateToken(signed); err == nil {
+		t.Fatal("accepted an RS256 token naming an ES256 key")
+	}
+}
+
+func TestJWTServiceVerifiesAcrossRotation(t *testing.T) {
+	tests := []struct {
+		name  string
+		grace time.Duration
+		valid bool
+	}{
+		{name: "inside grace period", grace: time.Hour, valid: true},
+		{name: "grace period over", grace: 0, valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, keys := newTestJWTService(t, AlgorithmES256, tt.grace)
+
+			token, _, err := svc.GenerateToken("partner-1", "client-1", "", "", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := keys.Rotate(); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = svc.ValidateToken(token)
+			if valid := err == nil; valid != tt.valid {
+				t.Fatalf("token from the rotated-out key valid = %v (err %v), want %v", valid, err, tt.valid)
+			}
+
+			fresh, _, err := svc.GenerateToken("partner-1", "client-1", "", "", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.ValidateToken(fresh); err != nil {
+				t.Fatalf("token from the new key rejected: %v", err)
+			}
+		})
+	}
+}
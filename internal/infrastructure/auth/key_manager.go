@@ -0,0 +1,495 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithm identifies a supported asymmetric JWS signing algorithm.
+type Algorithm string
+
+const (
+	AlgorithmRS256 Algorithm = "RS256"
+	AlgorithmES256 Algorithm = "ES256"
+	AlgorithmEdDSA Algorithm = "EdDSA"
+)
+
+// JWKSCacheTTL is how long clients may cache the JWKS. Keys loaded from a
+// secret provider are published at least this long before tokens are signed
+// with them, so verifiers that cached the JWKS still find the key.
+const JWKSCacheTTL = 5 * time.Minute
+
+// SigningKey is a private key used to sign access tokens. Keys stay
+// published in the JWKS until RetireAt so tokens they signed still verify.
+type SigningKey struct {
+	KID        string
+	Algorithm  Algorithm
+	Private    crypto.Signer
+	CreatedAt  time.Time
+	ActivateAt time.Time // set while a loaded key is published ahead of use
+	RetireAt   time.Time // zero while the key is current or upcoming
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	return signingMethod(k.Algorithm)
+}
+
+// KeyManagerConfig controls key generation and rotation.
+type KeyManagerConfig struct {
+	Algorithm Algorithm
+	// RotationInterval is how often Run rotates the signing key. Zero disables
+	// scheduled rotation.
+	RotationInterval time.Duration
+	// GracePeriod keeps a rotated-out key available for verification. It must
+	// be at least the access token lifetime.
+	GracePeriod time.Duration
+
+	// Secrets, when set, supplies the signing key as a PEM private key named
+	// KeySecretName instead of generating keys locally. Run then re-reads it
+	// every RefreshInterval, so a key rotated in the secret store is picked
+	// up without a redeploy.
+	Secrets         SecretProvider
+	KeySecretName   string
+	RefreshInterval time.Duration
+	// ActivationDelay is how long a changed key from the secret provider is
+	// published before it signs tokens. Zero means JWKSCacheTTL.
+	ActivationDelay time.Duration
+}
+
+// KeyManager holds the current signing key, the next key (published ahead of
+// use so JWKS caches pick it up before tokens appear), and retired keys that
+// are still inside their grace period.
+type KeyManager struct {
+	mu      sync.RWMutex
+	config  KeyManagerConfig
+	current *SigningKey
+	next    *SigningKey
+	retired []*SigningKey
+}
+
+func NewKeyManager(config KeyManagerConfig) (*KeyManager, error) {
+	if signingMethod(config.Algorithm) == nil {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", config.Algorithm)
+	}
+
+	if config.Secrets != nil {
+		m := &KeyManager{config: config}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := m.Refresh(ctx); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+
+	current, err := GenerateSigningKey(config.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	next, err := GenerateSigningKey(config.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyManager{
+		config:  config,
+		current: current,
+		next:    next,
+	}, nil
+}
+
+// Rotate promotes the next key to current, retires the current key for the
+// grace period and generates a new next key. Keys supplied by a secret
+// provider are rotated in the secret store and picked up by Refresh.
+func (m *KeyManager) Rotate() error {
+	if m.config.Secrets != nil {
+		return errors.New("signing keys are managed by the secret provider")
+	}
+
+	next, err := GenerateSigningKey(m.config.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.retire(m.current, now)
+	m.current = m.next
+	m.next = next
+	m.prune(now)
+	return nil
+}
+
+// Refresh loads the signing key from the secret provider. The first key
+// loaded is used at once; a changed key is published as the next key and
+// becomes current after ActivationDelay, when the previous key is retired
+// for the grace period.
+func (m *KeyManager) Refresh(ctx context.Context) error {
+	if m.config.Secrets == nil {
+		return errors.New("no secret provider configured")
+	}
+
+	pemKey, err := m.config.Secrets.Secret(ctx, m.config.KeySecretName)
+	if err != nil {
+		return fmt.Errorf("load signing key: %w", err)
+	}
+	key, err := ParsePrivateKeyPEM([]byte(pemKey))
+	if err != nil {
+		return fmt.Errorf("parse signing key: %w", err)
+	}
+	if key.Algorithm != m.config.Algorithm {
+		return fmt.Errorf("signing key is %s, configured algorithm is %s", key.Algorithm, m.config.Algorithm)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	switch {
+	case m.current == nil:
+		// Nothing has been signed or published yet.
+		m.current = key
+	case m.current.KID == key.KID:
+		// A pending key that has been withdrawn from the store never signed
+		// anything, so it can be unpublished at once.
+		m.next = nil
+	case m.next != nil && m.next.KID == key.KID:
+	default:
+		key.ActivateAt = now.Add(m.activationDelay())
+		m.next = key
+	}
+	m.promote(now)
+	m.prune(now)
+	return nil
+}
+
+func (m *KeyManager) activationDelay() time.Duration {
+	if m.config.ActivationDelay > 0 {
+		return m.config.ActivationDelay
+	}
+	return JWKSCacheTTL
+}
+
+// promotionDue reports whether a pending loaded key should now be current.
+func (m *KeyManager) promotionDue(now time.Time) bool {
+	return m.next != nil && !m.next.ActivateAt.IsZero() && !now.Before(m.next.ActivateAt)
+}
+
+// promote makes a pending loaded key current once it is due.
+func (m *KeyManager) promote(now time.Time) {
+	if !m.promotionDue(now) {
+		return
+	}
+	m.retire(m.current, now)
+	m.current = m.next
+	m.current.ActivateAt = time.Time{}
+	m.next = nil
+}
+
+// Run rotates keys on the configured interval until ctx is cancelled. Keys
+// supplied by a secret provider are refreshed on RefreshInterval instead.
+func (m *KeyManager) Run(ctx context.Context) {
+	interval, step := m.config.RotationInterval, func() error { return m.Rotate() }
+	if m.config.Secrets != nil {
+		interval, step = m.config.RefreshInterval, func() error { return m.Refresh(ctx) }
+	}
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A failed rotation or refresh keeps the current key; the next
+			// tick retries.
+			_ = step()
+		}
+	}
+}
+
+// SigningKey returns the key new tokens should be signed with.
+func (m *KeyManager) SigningKey() *SigningKey {
+	m.mu.RLock()
+	key, due := m.current, m.promotionDue(time.Now())
+	m.mu.RUnlock()
+	if !due {
+		return key
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.promote(time.Now())
+	return m.current
+}
+
+// Check reports whether a signing key is available and scheduled rotation is
+// keeping up. A current key is promoted from the pre-published next key, so
+// it may legitimately be up to two rotation intervals old.
+func (m *KeyManager) Check(ctx context.Context) error {
+	key := m.SigningKey()
+	if key == nil || key.Private == nil {
+		return errors.New("no signing key")
+	}
+	if m.config.Secrets == nil && m.config.RotationInterval > 0 && time.Since(key.CreatedAt) > 3*m.config.RotationInterval {
+		return fmt.Errorf("signing key %s is overdue for rotation", key.KID)
+	}
+	return nil
+}
+
+// VerificationKey returns the published key with the given kid.
+func (m *KeyManager) VerificationKey(kid string) (*SigningKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range m.published() {
+		if key.KID == kid && (key.RetireAt.IsZero() || now.Before(key.RetireAt)) {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// Algorithms lists the algorithms of all published keys.
+func (m *KeyManager) Algorithms() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var algs []string
+	for _, key := range m.published() {
+		if alg := string(key.Algorithm); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
+
+// JWKS returns the public JSON Web Key Set for all published keys.
+func (m *KeyManager) JWKS() JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range m.published() {
+		if !key.RetireAt.IsZero() && now.After(key.RetireAt) {
+			continue
+		}
+		if jwk, err := publicJWK(key); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+func (m *KeyManager) published() []*SigningKey {
+	keys := []*SigningKey{m.current}
+	if m.next != nil {
+		keys = append(keys, m.next)
+	}
+	return append(keys, m.retired...)
+}
+
+func (m *KeyManager) retire(key *SigningKey, now time.Time) {
+	if key == nil {
+		return
+	}
+	key.RetireAt = now.Add(m.config.GracePeriod)
+	m.retired = append(m.retired, key)
+}
+
+func (m *KeyManager) prune(now time.Time) {
+	kept := m.retired[:0]
+	for _, key := range m.retired {
+		if now.Before(key.RetireAt) {
+			kept = append(kept, key)
+		}
+	}
+	m.retired = kept
+}
+
+// GenerateSigningKey creates a new key for alg with a kid derived from its
+// RFC 7638 thumbprint.
+func GenerateSigningKey(alg Algorithm) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch alg {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigningKey(alg, private)
+}
+
+// NewSigningKey wraps an existing private key.
+func NewSigningKey(alg Algorithm, private crypto.Signer) (*SigningKey, error) {
+	key := &SigningKey{
+		Algorithm: alg,
+		Private:   private,
+		CreatedAt: time.Now(),
+	}
+
+	jwk, err := publicJWK(key)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	key.KID = kid
+	return key, nil
+}
+
+// ParsePrivateKeyPEM parses a PKCS#8, PKCS#1 or SEC 1 PEM private key and
+// infers the signing algorithm from the key type.
+func ParsePrivateKeyPEM(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return NewSigningKey(AlgorithmRS256, k)
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 EC keys are supported")
+		}
+		return NewSigningKey(AlgorithmES256, k)
+	case ed25519.PrivateKey:
+		return NewSigningKey(AlgorithmEdDSA, k)
+	default:
+		return nil, errors.New("unsupported private key type")
+	}
+}
+
+// JWK is a public JSON Web Key (RFC 7517).
+type JWK struct {
+	KTY string `json:"kty"`
+	KID string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Thumbprint computes the RFC 7638 SHA-256 thumbprint of the key.
+func (k JWK) Thumbprint() (string, error) {
+	var members map[string]string
+	switch k.KTY {
+	case "RSA":
+		members = map[string]string{"e": k.E, "kty": k.KTY, "n": k.N}
+	case "EC":
+		members = map[string]string{"crv": k.Crv, "kty": k.KTY, "x": k.X, "y": k.Y}
+	case "OKP":
+		members = map[string]string{"crv": k.Crv, "kty": k.KTY, "x": k.X}
+	default:
+		return "", fmt.Errorf("unsupported key type %q", k.KTY)
+	}
+
+	// encoding/json sorts map keys, giving the lexicographic member order
+	// RFC 7638 requires.
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func publicJWK(key *SigningKey) (JWK, error) {
+	jwk := JWK{KID: key.KID, Use: "sig", Alg: string(key.Algorithm)}
+
+	switch pub := key.Private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KTY = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.KTY = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KTY = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, errors.New("unsupported public key type")
+	}
+
+	return jwk, nil
+}
+
+func signingMethod(alg Algorithm) jwt.SigningMethod {
+	switch alg {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case AlgorithmES256:
+		return jwt.SigningMethodES256
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
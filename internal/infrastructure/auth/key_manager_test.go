@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+	"testing"
+	"time"
+)
+
+// staticSecrets is a SecretProvider whose values tests change in place.
+type staticSecrets struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *staticSecrets) Secret(ctx context.Context, name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (s *staticSecrets) set(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[name] = value
+}
+
+func newPEMKey(t *testing.T) (*SigningKey, string) {
+	t.Helper()
+	key, err := GenerateSigningKey(AlgorithmES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func publishedKIDs(m *KeyManager) map[string]bool {
+	kids := make(map[string]bool)
+	for _, jwk := range m.JWKS().Keys {
+		kids[jwk.KID] = true
+	}
+	return kids
+}
+
+func TestKeyManagerRefreshPublishesBeforeSigning(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	ctx := context.Background()
+
+	first, firstPEM := newPEMKey(t)
+	second, secondPEM := newPEMKey(t)
+	secrets := &staticSecrets{values: map[string]string{"jwt/signing_key": firstPEM}}
+
+	m, err := NewKeyManager(KeyManagerConfig{
+		Algorithm:       AlgorithmES256,
+		GracePeriod:     time.Hour,
+		Secrets:         secrets,
+		KeySecretName:   "jwt/signing_key",
+		ActivationDelay: delay,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.SigningKey().KID; got != first.KID {
+		t.Fatalf("initial signing key %s, want %s", got, first.KID)
+	}
+
+	secrets.set("jwt/signing_key", secondPEM)
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.SigningKey().KID; got != first.KID {
+		t.Fatalf("signing with %s straight after refresh, want %s until the new key is published", got, first.KID)
+	}
+	if kids := publishedKIDs(m); !kids[first.KID] || !kids[second.KID] {
+		t.Fatalf("JWKS = %v, want both keys", kids)
+	}
+
+	time.Sleep(2 * delay)
+	if got := m.SigningKey().KID; got != second.KID {
+		t.Fatalf("signing with %s after the activation delay, want %s", got, second.KID)
+	}
+	if _, ok := m.VerificationKey(first.KID); !ok {
+		t.Fatal("previous key no longer verifies inside the grace period")
+	}
+}
+
+func TestKeyManagerRefreshWithdrawnKey(t *testing.T) {
+	ctx := context.Background()
+
+	first, firstPEM := newPEMKey(t)
+	second, secondPEM := newPEMKey(t)
+	secrets := &staticSecrets{values: map[string]string{"jwt/signing_key": firstPEM}}
+
+	m, err := NewKeyManager(KeyManagerConfig{
+		Algorithm:     AlgorithmES256,
+		GracePeriod:   time.Hour,
+		Secrets:       secrets,
+		KeySecretName: "jwt/signing_key",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets.set("jwt/signing_key", secondPEM)
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	secrets.set("jwt/signing_key", firstPEM)
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.SigningKey().KID; got != first.KID {
+		t.Fatalf("signing with %s, want %s", got, first.KID)
+	}
+	if kids := publishedKIDs(m); kids[second.KID] {
+		t.Fatalf("withdrawn key %s still published", second.KID)
+	}
+}
+
+func TestKeyManagerRefreshRejectsOtherAlgorithm(t *testing.T) {
+	_, pemKey := newPEMKey(t)
+	_, err := NewKeyManager(KeyManagerConfig{
+		Algorithm:     AlgorithmEdDSA,
+		Secrets:       &staticSecrets{values: map[string]string{"jwt/signing_key": pemKey}},
+		KeySecretName: "jwt/signing_key",
+	})
+	if err == nil {
+		t.Fatal("ES256 key accepted for EdDSA signing")
+	}
+}
+
+func TestKeyManagerRotate(t *testing.T) {
+	m, err := NewKeyManager(KeyManagerConfig{Algorithm: AlgorithmES256, GracePeriod: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := m.SigningKey()
+	upcoming := m.next.KID
+	if kids := publishedKIDs(m); !kids[upcoming] {
+		t.Fatalf("next key %s not published ahead of use", upcoming)
+	}
+
+	if err := m.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.SigningKey().KID; got != upcoming {
+		t.Fatalf("signing with %s after rotation, want the published next key %s", got, upcoming)
+	}
+	if _, ok := m.VerificationKey(before.KID); !ok {
+		t.Fatal("rotated-out key no longer verifies inside the grace period")
+	}
+}
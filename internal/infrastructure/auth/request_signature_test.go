@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyRequestSignature(t *testing.T) {
+	const secret = "ss_test_secret"
+	body := []byte(`{"amount":100}`)
+	canonical := CanonicalRequest("post", "/v1/transactions?dry_run=1", "1700000000", "nonce-0123456789", body)
+	signature := SignRequest(secret, canonical)
+
+	if !strings.HasPrefix(signature, RequestSignatureVersion+"=") {
+		t.Fatalf("signature %q lacks the %s= prefix", signature, RequestSignatureVersion)
+	}
+	if want := CanonicalRequest("POST", "/v1/transactions?dry_run=1", "1700000000", "nonce-0123456789", body); canonical != want {
+		t.Fatalf("method case changes the canonical request:\n%s\n%s", canonical, want)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		canonical string
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, canonical: canonical, signature: signature, want: true},
+		{name: "other secret", secret: "ss_other", canonical: canonical, signature: signature},
+		{name: "other method", secret: secret, canonical: CanonicalRequest("PUT", "/v1/transactions?dry_run=1", "1700000000", "nonce-0123456789", body), signature: signature},
+		{name: "other query", secret: secret, canonical: CanonicalRequest("POST", "/v1/transactions?dry_run=0", "1700000000", "nonce-0123456789", body), signature: signature},
+		{name: "other timestamp", secret: secret, canonical: CanonicalRequest("POST", "/v1/transactions?dry_run=1", "1700000001", "nonce-0123456789", body), signature: signature},
+		{name: "other nonce", secret: secret, canonical: CanonicalRequest("POST", "/v1/transactions?dry_run=1", "1700000000", "nonce-9876543210", body), signature: signature},
+		{name: "other body", secret: secret, canonical: CanonicalRequest("POST", "/v1/transactions?dry_run=1", "1700000000", "nonce-0123456789", []byte(`{"amount":900}`)), signature: signature},
+		{name: "missing version", secret: secret, canonical: canonical, signature: strings.TrimPrefix(signature, RequestSignatureVersion+"=")},
+		{name: "upper-case hex", secret: secret, canonical: canonical, signature: strings.ToUpper(signature)},
+		{name: "empty", secret: secret, canonical: canonical, signature: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyRequestSignature(tt.secret, tt.canonical, tt.signature); got != tt.want {
+				t.Fatalf("VerifyRequestSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters follow the second recommended option of RFC 9106.
+const (
+	argon2Time    uint32 = 3
+	argon2Memory  uint32 = 64 * 1024
+	argon2Threads uint8  = 4
+	argon2KeyLen  uint32 = 32
+	argon2SaltLen        = 16
+
+	clientSecretPrefix = "cs_"
+
+	// hashWaitTimeout bounds how long a verification queues for a hashing
+	// slot before it is turned away.
+	hashWaitTimeout = 2 * time.Second
+)
+
+var (
+	ErrInvalidHash = errors.New("invalid secret hash")
+
+	// ErrHashingBusy is returned when every hashing slot stayed taken for
+	// hashWaitTimeout.
+	ErrHashingBusy = errors.New("secret hashing capacity exhausted")
+)
+
+// hashSlots limits concurrent argon2 computations. Each one holds
+// argon2Memory KiB, so the limit also caps the memory a flood of token
+// requests can claim.
+var hashSlots = make(chan struct{}, max(2, runtime.NumCPU()))
+
+// dummyHash is verified against when no stored hash exists so that unknown
+// client IDs take as long to reject as wrong secrets.
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// GenerateClientSecret returns a new random client secret carrying secretID,
+// so verification can go straight to the matching stored hash.
+func GenerateClientSecret(secretID string) (string, error) {
+	return GenerateOpaqueToken(clientSecretPrefix + secretID + ".")
+}
+
+// ClientSecretID returns the secret ID carried by a secret from
+// GenerateClientSecret. Secrets issued before IDs were embedded have none.
+func ClientSecretID(secret string) (string, bool) {
+	rest, ok := strings.CutPrefix(secret, clientSecretPrefix)
+	if !ok {
+		return "", false
+	}
+	id, _, ok := strings.Cut(rest, ".")
+	if !ok || id == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// HashSecret returns a salted argon2id hash encoded in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+func HashSecret(secret string) (string, error) {
+	hashSlots <- struct{}{}
+	defer func() { <-hashSlots }()
+
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(secret), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifySecret reports whether secret matches encodedHash, comparing the
+// derived keys in constant time. It returns ErrHashingBusy if no hashing
+// slot frees up in time.
+func VerifySecret(ctx context.Context, secret, encodedHash string) (bool, error) {
+	timer := time.NewTimer(hashWaitTimeout)
+	defer timer.Stop()
+	select {
+	case hashSlots <- struct{}{}:
+		defer func() { <-hashSlots }()
+	case <-timer.C:
+		return false, ErrHashingBusy
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrInvalidHash
+	}
+
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+
+	actual := argon2.IDKey([]byte(secret), salt, iterations, memory, threads, uint32(len(expected)))
+	return subtle.ConstantTimeCompare(actual, expected) == 1, nil
+}
+
+// BurnVerification performs a throwaway verification to equalise timing when
+// there is no stored secret to check against. It returns ErrHashingBusy like
+// VerifySecret, so callers reject both cases the same way.
+func BurnVerification(ctx context.Context, secret string) error {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashSecret("dummy-secret-for-timing-equalisation")
+	})
+	_, err := VerifySecret(ctx, secret, dummyHash)
+	return err
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashAndVerifySecret(t *testing.T) {
+	hash, err := HashSecret("correct horse")
+	if err != nil {
+		t.Fatalf("HashSecret: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$") {
+		t.Fatalf("unexpected hash format %q", hash)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		hash   string
+		want   bool
+		err    error
+	}{
+		{name: "match", secret: "correct horse", hash: hash, want: true},
+		{name: "mismatch", secret: "correct horsf", hash: hash},
+		{name: "not argon2id", secret: "x", hash: "$argon2i$v=19$m=1,t=1,p=1$c2FsdA$a2V5", err: ErrInvalidHash},
+		{name: "truncated", secret: "x", hash: "$argon2id$v=19", err: ErrInvalidHash},
+		{name: "bad salt", secret: "x", hash: "$argon2id$v=19$m=8,t=1,p=1$!!$a2V5", err: ErrInvalidHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifySecret(context.Background(), tt.secret, tt.hash)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("VerifySecret = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientSecretID(t *testing.T) {
+	generated, err := GenerateClientSecret("sec_1a2b3c4d")
+	if err != nil {
+		t.Fatalf("GenerateClientSecret: %v", err)
+	}
+
+	tests := []struct {
+		secret string
+		id     string
+		ok     bool
+	}{
+		{secret: generated, id: "sec_1a2b3c4d", ok: true},
+		{secret: "cs_sec_x.abc", id: "sec_x", ok: true},
+		{secret: "cs_.abc"},
+		{secret: "cs_noseparator"},
+		{secret: "secret_bella_123"},
+		{secret: ""},
+	}
+	for _, tt := range tests {
+		id, ok := ClientSecretID(tt.secret)
+		if id != tt.id || ok != tt.ok {
+			t.Errorf("ClientSecretID(%q) = %q, %v; want %q, %v", tt.secret, id, ok, tt.id, tt.ok)
+		}
+	}
+}
+
+func TestVerifySecretBusy(t *testing.T) {
+	hash, err := HashSecret("secret")
+	if err != nil {
+		t.Fatalf("HashSecret: %v", err)
+	}
+
+	// Take every slot so the verification has to queue.
+	for i := 0; i < cap(hashSlots); i++ {
+		hashSlots <- struct{}{}
+	}
+	defer func() {
+		for i := 0; i < cap(hashSlots); i++ {
+			<-hashSlots
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := VerifySecret(ctx, "secret", hash); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled wait: error = %v, want context.Canceled", err)
+	}
+	if testing.Short() {
+		return
+	}
+	if _, err := VerifySecret(context.Background(), "secret", hash); !errors.Is(err, ErrHashingBusy) {
+		t.Fatalf("full slots: error = %v, want ErrHashingBusy", err)
+	}
+}
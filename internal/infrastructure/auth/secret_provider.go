@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves secrets by name, such as "jwt/signing_key".
+// Implementations return ErrSecretNotFound for names they do not hold.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// EnvSecretProvider reads secrets from environment variables. The variable
+// name is the prefix followed by the secret name in upper case with '/',
+// '-' and '.' replaced by '_', so "jwt/signing_key" with prefix "SECRET_"
+// is read from SECRET_JWT_SIGNING_KEY.
+type EnvSecretProvider struct {
+	prefix string
+}
+
+func NewEnvSecretProvider(prefix string) *EnvSecretProvider {
+	return &EnvSecretProvider{prefix: prefix}
+}
+
+func (p *EnvSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(p.envName(name))
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+func (p *EnvSecretProvider) envName(name string) string {
+	replacer := strings.NewReplacer("/", "_", "-", "_", ".", "_")
+	return p.prefix + strings.ToUpper(replacer.Replace(name))
+}
+
+// FileSecretProvider reads each secret from a file under a directory, the
+// layout used by Docker and Kubernetes secret mounts. "jwt/signing_key" is
+// read from <dir>/jwt/signing_key. Files are re-read on every call, so a
+// replaced file takes effect without a restart.
+type FileSecretProvider struct {
+	dir string
+}
+
+func NewFileSecretProvider(dir string) *FileSecretProvider {
+	return &FileSecretProvider{dir: dir}
+}
+
+func (p *FileSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	path := filepath.FromSlash(name)
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dir, path))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// CachedSecretProvider caches another provider's secrets for a TTL so hot
+// paths such as request signature checks do not call the backend every
+// time. When a refresh fails, the last value is served and the refresh is
+// retried on the next call, so a backend outage does not lock partners out.
+type CachedSecretProvider struct {
+	next SecretProvider
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+func NewCachedSecretProvider(next SecretProvider, ttl time.Duration) *CachedSecretProvider {
+	return &CachedSecretProvider{
+		next:    next,
+		ttl:     ttl,
+		entries: make(map[string]cachedSecret),
+	}
+}
+
+func (p *CachedSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	p.mu.Lock()
+	entry, cached := p.entries[name]
+	p.mu.Unlock()
+
+	if cached && time.Since(entry.fetchedAt) < p.ttl {
+		return entry.value, nil
+	}
+
+	value, err := p.next.Secret(ctx, name)
+	if err != nil {
+		if cached && !errors.Is(err, ErrSecretNotFound) {
+			return entry.value, nil
+		}
+		return "", err
+	}
+
+	p.mu.Lock()
+	p.entries[name] = cachedSecret{value: value, fetchedAt: time.Now()}
+	p.mu.Unlock()
+	return value, nil
+}
+
+// Invalidate drops a cached secret so the next call fetches it again.
+func (p *CachedSecretProvider) Invalidate(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.entries, name)
+}
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnvSecretProvider(t *testing.T) {
+	t.Setenv("SECRET_JWT_SIGNING_KEY", "pem")
+	p := NewEnvSecretProvider("SECRET_")
+
+	tests := []struct {
+		name string
+		want string
+		err  error
+	}{
+		{name: "jwt/signing_key", want: "pem"},
+		{name: "jwt-signing.key", want: "pem"},
+		{name: "jwt/other", err: ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Secret(context.Background(), tt.name)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("Secret(%q) = %q, %v; want %q, %v", tt.name, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "jwt"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "jwt", "signing_key"), []byte("pem\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dir), "outside"), []byte("leak"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := NewFileSecretProvider(dir)
+
+	tests := []struct {
+		name    string
+		want    string
+		err     error
+		wantErr bool
+	}{
+		{name: "jwt/signing_key", want: "pem"},
+		{name: "jwt/missing", err: ErrSecretNotFound, wantErr: true},
+		{name: "../outside", wantErr: true},
+		{name: "/etc/passwd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Secret(context.Background(), tt.name)
+			if (err != nil) != tt.wantErr || (tt.err != nil && !errors.Is(err, tt.err)) {
+				t.Fatalf("Secret(%q) error = %v, want %v", tt.name, err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("Secret(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestVault serves one KV v2 secret, buy-credit/jwt, to requests carrying
+// token, and counts the requests it receives.
+func newTestVault(t *testing.T, token string, status *atomic.Int32) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if code := status.Load(); code != 0 {
+			http.Error(w, `{"errors":["token `+token+` rejected"]}`, int(code))
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != token {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/buy-credit/jwt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"data":{"value":"pem","private_key":"other-pem"}}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	var status atomic.Int32
+	srv, _ := newTestVault(t, "s.token", &status)
+
+	tests := []struct {
+		name    string
+		token   string
+		secret  string
+		status  int32
+		want    string
+		err     error
+		wantErr bool
+	}{
+		{name: "default field", token: "s.token", secret: "buy-credit/jwt", want: "pem"},
+		{name: "named field", token: "s.token", secret: "/buy-credit/jwt/#private_key", want: "other-pem"},
+		{name: "missing field", token: "s.token", secret: "buy-credit/jwt#other", err: ErrSecretNotFound, wantErr: true},
+		{name: "missing secret", token: "s.token", secret: "buy-credit/other", err: ErrSecretNotFound, wantErr: true},
+		{name: "wrong token", token: "s.wrong", secret: "buy-credit/jwt", wantErr: true},
+		{name: "server error", token: "s.token", secret: "buy-credit/jwt", status: http.StatusInternalServerError, wantErr: true},
+		{name: "empty field", token: "s.token", secret: "buy-credit/jwt#", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status.Store(tt.status)
+			p, err := NewVaultSecretProvider(VaultConfig{Addr: srv.URL + "/", Token: tt.token, Mount: "/kv/"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := p.Secret(context.Background(), tt.secret)
+			if (err != nil) != tt.wantErr || (tt.err != nil && !errors.Is(err, tt.err)) {
+				t.Fatalf("Secret(%q) error = %v, want %v", tt.secret, err, tt.err)
+			}
+			if err != nil && strings.Contains(err.Error(), tt.token) {
+				t.Fatalf("error %q echoes the vault token", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Secret(%q) = %q, want %q", tt.secret, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachedSecretProvider(t *testing.T) {
+	const ttl = 50 * time.Millisecond
+	ctx := context.Background()
+
+	var status atomic.Int32
+	srv, hits := newTestVault(t, "s.token", &status)
+	vault, err := NewVaultSecretProvider(VaultConfig{Addr: srv.URL, Token: "s.token", Mount: "kv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewCachedSecretProvider(vault, ttl)
+
+	secret := func() string {
+		t.Helper()
+		got, err := p.Secret(ctx, "buy-credit/jwt")
+		if err != nil {
+			t.Fatalf("Secret: %v", err)
+		}
+		return got
+	}
+
+	secret()
+	secret()
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("%d vault requests within the TTL, want 1", n)
+	}
+
+	time.Sleep(2 * ttl)
+	secret()
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("%d vault requests after the TTL, want 2", n)
+	}
+
+	// An outage serves the last value and retries on every call.
+	status.Store(http.StatusServiceUnavailable)
+	time.Sleep(2 * ttl)
+	if got := secret(); got != "pem" {
+		t.Fatalf("Secret during outage = %q, want the cached value", got)
+	}
+	secret()
+	if n := hits.Load(); n != 4 {
+		t.Fatalf("%d vault requests during the outage, want 4", n)
+	}
+	status.Store(0)
+
+	p.Invalidate("buy-credit/jwt")
+	secret()
+	if n := hits.Load(); n != 5 {
+		t.Fatalf("%d vault requests after Invalidate, want 5", n)
+	}
+}
+
+func TestCachedSecretProviderDoesNotServeDeletedSecrets(t *testing.T) {
+	ctx := context.Background()
+	secrets := &staticSecrets{values: map[string]string{"partner/key": "v1"}}
+	p := NewCachedSecretProvider(secrets, time.Nanosecond)
+
+	if _, err := p.Secret(ctx, "partner/key"); err != nil {
+		t.Fatal(err)
+	}
+	secrets.mu.Lock()
+	delete(secrets.values, "partner/key")
+	secrets.mu.Unlock()
+	time.Sleep(time.Millisecond)
+
+	if got, err := p.Secret(ctx, "partner/key"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("Secret after deletion = %q, %v; want ErrSecretNotFound", got, err)
+	}
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultVaultField = "value"
+
+// VaultConfig locates a HashiCorp Vault (or compatible) KV version 2 engine.
+type VaultConfig struct {
+	Addr  string // e.g. https://vault.internal:8200
+	Token string
+	Mount string // KV engine mount path; defaults to "secret"
+	// Client defaults to an http.Client with a 10 second timeout.
+	Client *http.Client
+}
+
+// VaultSecretProvider reads secrets from a Vault KV v2 engine. A name is a
+// secret path with an optional "#field" suffix; without one the "value"
+// field is used, so "buy-credit/jwt#private_key" reads the private_key
+// field of the buy-credit/jwt secret.
+type VaultSecretProvider struct {
+	config VaultConfig
+}
+
+func NewVaultSecretProvider(config VaultConfig) (*VaultSecretProvider, error) {
+	if config.Addr == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if _, err := url.Parse(config.Addr); err != nil {
+		return nil, fmt.Errorf("invalid vault address: %w", err)
+	}
+	if config.Mount == "" {
+		config.Mount = "secret"
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &VaultSecretProvider{config: config}, nil
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+func (p *VaultSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	path, field, ok := strings.Cut(name, "#")
+	if !ok {
+		field = defaultVaultField
+	}
+	path = strings.Trim(path, "/")
+	if path == "" || field == "" {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	endpoint := strings.TrimRight(p.config.Addr, "/") + "/v1/" +
+		strings.Trim(p.config.Mount, "/") + "/data/" + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.config.Token)
+
+	resp, err := p.config.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	case resp.StatusCode != http.StatusOK:
+		// Vault error bodies can echo request details, so only the status
+		// is reported.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, path)
+	}
+
+	var body vaultKVResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+
+	value, ok := body.Data.Data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
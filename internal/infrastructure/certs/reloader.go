@@ -0,0 +1,211 @@
+package certs
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientAuthMode controls whether the listener asks for client certificates.
+type ClientAuthMode string
+
+const (
+	ClientAuthNone     ClientAuthMode = "none"     // plain TLS
+	ClientAuthOptional ClientAuthMode = "optional" // verify a certificate if one is sent
+	ClientAuthRequire  ClientAuthMode = "require"  // every connection must present a valid certificate
+)
+
+type Config struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	ClientAuth   ClientAuthMode
+}
+
+// Reloader serves the server certificate and client CA pool from files and
+// reloads them when they change, so certificates can be renewed without a
+// restart. New connections pick up the reloaded material immediately.
+type Reloader struct {
+	config Config
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+func NewReloader(config Config) (*Reloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("certificate and key files are required")
+	}
+
+	switch config.ClientAuth {
+	case "", ClientAuthNone:
+	case ClientAuthOptional, ClientAuthRequire:
+		if config.ClientCAFile == "" {
+			return nil, errors.New("a client CA file is required for mutual TLS")
+		}
+	default:
+		return nil, fmt.Errorf("unknown client auth mode %q", config.ClientAuth)
+	}
+
+	r := &Reloader{config: config}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate, key and client CA files. On error the
+// previously loaded material stays in use.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load server certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("client CA file contains no certificates")
+		}
+	}
+
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = pool
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// Run polls the files every interval and reloads them when any modification
+// time changes, until ctx is cancelled.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				slog.Error("TLS certificate reload failed", "error", err)
+				continue
+			}
+			slog.Info("TLS certificates reloaded")
+		}
+	}
+}
+
+// Check reports whether the loaded server certificate is currently valid.
+func (r *Reloader) Check(ctx context.Context) error {
+	r.mu.RLock()
+	cert := r.cert
+	r.mu.RUnlock()
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("parse server certificate: %w", err)
+	}
+	if now := time.Now(); now.After(leaf.NotAfter) {
+		return fmt.Errorf("server certificate expired at %s", leaf.NotAfter.Format(time.RFC3339))
+	} else if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("server certificate not valid before %s", leaf.NotBefore.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// TLSConfig returns a server configuration that always uses the most
+// recently loaded certificate and client CA pool. Each handshake gets a
+// clone of the returned configuration, so settings added to it later, such
+// as the ALPN protocols http.Server adds for HTTP/2, still apply.
+func (r *Reloader) TLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		config.Certificates = []tls.Certificate{*r.cert}
+		config.ClientCAs = r.clientCAs
+		config.ClientAuth = r.clientAuthType()
+		return config, nil
+	}
+	return base
+}
+
+func (r *Reloader) clientAuthType() tls.ClientAuthType {
+	switch r.config.ClientAuth {
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.NoClientCert
+	}
+}
+
+func (r *Reloader) changed() bool {
+	current, err := r.statFiles()
+	if err != nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for path, mod := range current {
+		if !mod.Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) statFiles() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, path := range []string{r.config.CertFile, r.config.KeyFile, r.config.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// Thumbprint returns the RFC 8705 x5t#S256 value for a certificate: the
+// base64url-encoded SHA-256 of its DER encoding.
+func Thumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
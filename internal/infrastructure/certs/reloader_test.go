@@ -0,0 +1,180 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert is a self-signed localhost certificate with its PEM encodings.
+type testCert struct {
+	leaf    *x509.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, serial int64) testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCert{
+		leaf:    leaf,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeFiles writes the certificate and key, dating them mod so the change
+// is seen even on filesystems with coarse modification times.
+func writeFiles(t *testing.T, config Config, certPEM, keyPEM []byte, mod time.Time) {
+	t.Helper()
+	for path, data := range map[string][]byte{config.CertFile: certPEM, config.KeyFile: keyPEM} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestReloader(t *testing.T, cert testCert) (*Reloader, Config) {
+	t.Helper()
+	dir := t.TempDir()
+	config := Config{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}
+	writeFiles(t, config, cert.certPEM, cert.keyPEM, time.Now().Add(-time.Minute))
+
+	r, err := NewReloader(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, config
+}
+
+// servedSerial returns the serial number of the certificate the next
+// handshake would present.
+func servedSerial(t *testing.T, r *Reloader) int64 {
+	t.Helper()
+	config, err := r.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestReloaderPicksUpRotatedCertificate(t *testing.T) {
+	r, config := newTestReloader(t, newTestCert(t, 1))
+	if got := servedSerial(t, r); got != 1 {
+		t.Fatalf("serving serial %d, want 1", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx, 10*time.Millisecond)
+
+	rotated := newTestCert(t, 2)
+	writeFiles(t, config, rotated.certPEM, rotated.keyPEM, time.Now())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for servedSerial(t, r) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("rotated certificate was not picked up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReloaderRejectsBadKeyPair(t *testing.T) {
+	original := newTestCert(t, 1)
+	r, config := newTestReloader(t, original)
+
+	// A renewed certificate written next to the old key must not replace
+	// the working pair.
+	renewed := newTestCert(t, 2)
+	writeFiles(t, config, renewed.certPEM, original.keyPEM, time.Now())
+	if err := r.Reload(); err == nil {
+		t.Fatal("Reload accepted a certificate with the wrong key")
+	}
+	if got := servedSerial(t, r); got != 1 {
+		t.Fatalf("serving serial %d after failed reload, want 1", got)
+	}
+
+	if _, err := NewReloader(config); err == nil {
+		t.Fatal("NewReloader accepted a certificate with the wrong key")
+	}
+}
+
+func TestReloaderNegotiatesHTTP2(t *testing.T) {
+	cert := newTestCert(t, 1)
+	r, _ := newTestReloader(t, cert)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}),
+		TLSConfig: r.TLSConfig(),
+	}
+	go srv.ServeTLS(ln, "", "")
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.leaf)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("negotiated %s, want HTTP/2", resp.Proto)
+	}
+	if resp.TLS.Version < tls.VersionTLS12 {
+		t.Fatalf("negotiated TLS version %x", resp.TLS.Version)
+	}
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"time"
+)
+
+// Environment selects how strictly the configuration is validated.
+type Environment string
+
+const (
+	EnvironmentDevelopment Environment = "development"
+	EnvironmentProduction  Environment = "production"
+)
+
+// Config is the complete service configuration. Values are resolved in order
+// of increasing precedence: built-in defaults, the config file, then
+// environment variables. Every field with an env tag can also be read from a
+// file named by the same variable with a _FILE suffix, e.g.
+// ADMIN_API_KEYS_FILE=/run/secrets/admin_keys.
+type Config struct {
+	Environment Environment `yaml:"environment" toml:"environment" env:"APP_ENV"`
+	// DemoData seeds the sample partner, whose credentials are published in
+	// the README. It is refused outside development.
+	DemoData bool `yaml:"demoData" toml:"demoData" env:"SEED_DEMO_DATA"`
+
+	Server  ServerConfig  `yaml:"server" toml:"server"`
+	TLS     TLSConfig     `yaml:"tls" toml:"tls"`
+	Auth    AuthConfig    `yaml:"auth" toml:"auth"`
+	Secrets SecretsConfig `yaml:"secrets" toml:"secrets"`
+	Admin   AdminConfig   `yaml:"admin" toml:"admin"`
+	Metrics MetricsConfig `yaml:"metrics" toml:"metrics"`
+	Tracing TracingConfig `yaml:"tracing" toml:"tracing"`
+	Logging LoggingConfig `yaml:"logging" toml:"logging"`
+	Risk    RiskConfig    `yaml:"risk" toml:"risk"`
+	Review  ReviewConfig  `yaml:"review" toml:"review"`
+	Health  HealthConfig  `yaml:"health" toml:"health"`
+
+	Provisioning ProvisioningConfig `yaml:"provisioning" toml:"provisioning"`
+	Recovery     RecoveryConfig     `yaml:"recovery" toml:"recovery"`
+}
+
+type ServerConfig struct {
+	Addr            string        `yaml:"addr" toml:"addr" env:"HTTP_ADDR"`
+	ReadTimeout     time.Duration `yaml:"readTimeout" toml:"readTimeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout" toml:"writeTimeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idleTimeout" toml:"idleTimeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" toml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+	DrainDelay      time.Duration `yaml:"drainDelay" toml:"drainDelay" env:"SHUTDOWN_DRAIN_DELAY"`
+	TrustedProxies  []string      `yaml:"trustedProxies" toml:"trustedProxies" env:"TRUSTED_PROXIES"`
+}
+
+type TLSConfig struct {
+	CertFile       string        `yaml:"certFile" toml:"certFile" env:"TLS_CERT_FILE"`
+	KeyFile        string        `yaml:"keyFile" toml:"keyFile" env:"TLS_KEY_FILE"`
+	ClientCAFile   string        `yaml:"clientCaFile" toml:"clientCaFile" env:"TLS_CLIENT_CA_FILE"`
+	ClientAuth     string        `yaml:"clientAuth" toml:"clientAuth" env:"TLS_CLIENT_AUTH"`
+	ReloadInterval time.Duration `yaml:"reloadInterval" toml:"reloadInterval" env:"TLS_RELOAD_INTERVAL"`
+}
+
+// Enabled reports whether the server should listen with TLS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+type AuthConfig struct {
+	TokenTTL            time.Duration `yaml:"tokenTtl" toml:"tokenTtl" env:"TOKEN_TTL"`
+	RefreshTokenTTL     time.Duration `yaml:"refreshTokenTtl" toml:"refreshTokenTtl" env:"REFRESH_TOKEN_TTL"`
+	SigningAlgorithm    string        `yaml:"signingAlgorithm" toml:"signingAlgorithm" env:"SIGNING_ALGORITHM"`
+	KeyRotationInterval time.Duration `yaml:"keyRotationInterval" toml:"keyRotationInterval" env:"SIGNING_KEY_ROTATION_INTERVAL"`
+	PartnerCacheTTL     time.Duration `yaml:"partnerCacheTtl" toml:"partnerCacheTtl" env:"PARTNER_CACHE_TTL"`
+	// SigningKeySecret names a PEM private key in the secret provider to sign
+	// tokens with. Empty generates and rotates keys in memory.
+	SigningKeySecret          string        `yaml:"signingKeySecret" toml:"signingKeySecret" env:"SIGNING_KEY_SECRET"`
+	SigningKeyRefreshInterval time.Duration `yaml:"signingKeyRefreshInterval" toml:"signingKeyRefreshInterval" env:"SIGNING_KEY_REFRESH_INTERVAL"`
+}
+
+// Secret provider types.
+const (
+	SecretsProviderFile  = "file"
+	SecretsProviderEnv   = "env"
+	SecretsProviderVault = "vault"
+)
+
+// DevSecretsDir holds the published demo credentials checked into the
+// repository.
+const DevSecretsDir = "dev/secrets"
+
+type SecretsConfig struct {
+	Provider   string        `yaml:"provider" toml:"provider" env:"SECRETS_PROVIDER"`
+	Dir        string        `yaml:"dir" toml:"dir" env:"SECRETS_DIR"`
+	EnvPrefix  string        `yaml:"envPrefix" toml:"envPrefix" env:"SECRETS_ENV_PREFIX"`
+	VaultAddr  string        `yaml:"vaultAddr" toml:"vaultAddr" env:"VAULT_ADDR"`
+	VaultToken string        `yaml:"vaultToken" toml:"vaultToken" env:"VAULT_TOKEN"`
+	VaultMount string        `yaml:"vaultMount" toml:"vaultMount" env:"VAULT_MOUNT"`
+	CacheTTL   time.Duration `yaml:"cacheTtl" toml:"cacheTtl" env:"SECRETS_CACHE_TTL"`
+}
+
+type AdminConfig struct {
+	// APIKeys holds comma-separated name:key pairs. Empty disables the
+	// admin API.
+	APIKeys string `yaml:"apiKeys" toml:"apiKeys" env:"ADMIN_API_KEYS"`
+}
+
+type MetricsConfig struct {
+	Addr string `yaml:"addr" toml:"addr" env:"METRICS_ADDR"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string  `yaml:"serviceName" toml:"serviceName" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sampleRatio" toml:"sampleRatio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+type LoggingConfig struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+}
+
+type RiskConfig struct {
+	RulesFile      string        `yaml:"rulesFile" toml:"rulesFile" env:"RISK_RULES_FILE"`
+	ReloadInterval time.Duration `yaml:"reloadInterval" toml:"reloadInterval" env:"RISK_RULES_RELOAD_INTERVAL"`
+}
+
+type ReviewConfig struct {
+	SLA time.Duration `yaml:"sla" toml:"sla" env:"REVIEW_SLA"`
+}
+
+// ProvisioningConfig locates the gateway that delivers purchased credit.
+type ProvisioningConfig struct {
+	GatewayURL   string        `yaml:"gatewayUrl" toml:"gatewayUrl" env:"PROVISIONING_GATEWAY_URL"`
+	GatewayToken string        `yaml:"gatewayToken" toml:"gatewayToken" env:"PROVISIONING_GATEWAY_TOKEN"`
+	Timeout      time.Duration `yaml:"timeout" toml:"timeout" env:"PROVISIONING_GATEWAY_TIMEOUT"`
+}
+
+// RecoveryConfig tunes the sweeper that settles transactions left PENDING,
+// for example by a crash between storing a purchase and provisioning it.
+type RecoveryConfig struct {
+	Enabled  bool          `yaml:"enabled" toml:"enabled" env:"RECOVERY_ENABLED"`
+	Interval time.Duration `yaml:"interval" toml:"interval" env:"RECOVERY_INTERVAL"`
+	// PendingThreshold is how long a transaction must have been PENDING
+	// before the sweeper asks the gateway about it.
+	PendingThreshold time.Duration `yaml:"pendingThreshold" toml:"pendingThreshold" env:"RECOVERY_PENDING_THRESHOLD"`
+	// MaxAttempts is how many inconclusive gateway checks are made before
+	// the transaction is escalated to operators.
+	MaxAttempts int `yaml:"maxAttempts" toml:"maxAttempts" env:"RECOVERY_MAX_ATTEMPTS"`
+	BatchSize   int `yaml:"batchSize" toml:"batchSize" env:"RECOVERY_BATCH_SIZE"`
+	// SingleInstance declares that only one instance of the service runs.
+	// Leases are held in memory, so outside development the sweep only
+	// runs when this is set.
+	SingleInstance bool `yaml:"singleInstance" toml:"singleInstance" env:"RECOVERY_SINGLE_INSTANCE"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"checkTimeout" toml:"checkTimeout" env:"HEALTH_CHECK_TIMEOUT"`
+	CacheTTL     time.Duration `yaml:"cacheTtl" toml:"cacheTtl" env:"HEALTH_CACHE_TTL"`
+}
+
+// Default returns the built-in configuration. It runs in production mode
+// with demo data enabled, so it fails validation until one of the two is
+// changed deliberately.
+func Default() *Config {
+	return &Config{
+		Environment: EnvironmentProduction,
+		DemoData:    true,
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+			DrainDelay:      5 * time.Second,
+		},
+		TLS: TLSConfig{
+			ClientAuth:     "none",
+			ReloadInterval: 30 * time.Second,
+		},
+		Auth: AuthConfig{
+			TokenTTL:            time.Hour,
+			RefreshTokenTTL:     30 * 24 * time.Hour,
+			SigningAlgorithm:    "ES256",
+			KeyRotationInterval: 24 * time.Hour,
+			PartnerCacheTTL:     30 * time.Second,
+
+			SigningKeyRefreshInterval: time.Minute,
+		},
+		Secrets: SecretsConfig{
+			Provider:   SecretsProviderFile,
+			Dir:        DevSecretsDir,
+			EnvPrefix:  "SECRET_",
+			VaultMount: "secret",
+			CacheTTL:   time.Minute,
+		},
+		Metrics: MetricsConfig{
+			Addr: ":9090",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "buy-credit-api",
+			SampleRatio: 1.0,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Risk: RiskConfig{
+			ReloadInterval: 30 * time.Second,
+		},
+		Review: ReviewConfig{
+			SLA: 4 * time.Hour,
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+			CacheTTL:     5 * time.Second,
+		},
+		Provisioning: ProvisioningConfig{
+			Timeout: 10 * time.Second,
+		},
+		Recovery: RecoveryConfig{
+			Enabled:          true,
+			Interval:         time.Minute,
+			PendingThreshold: 10 * time.Minute,
+			MaxAttempts:      5,
+			BatchSize:        100,
+		},
+	}
+}
+
+// IsDevelopment reports whether insecure conveniences are permitted.
+func (c *Config) IsDevelopment() bool {
+	return c.Environment == EnvironmentDevelopment
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `environment: development
+server:
+  addr: ":8000"
+  readTimeout: 5s
+logging:
+  level: debug
+`,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `environment = "development"
+[server]
+addr = ":8000"
+readTimeout = "5s"
+[logging]
+level = "debug"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HTTP_ADDR", ":7000")
+			cfg, err := Load(writeFile(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Server.Addr != ":7000" {
+				t.Errorf("server.addr = %q, want the environment's :7000", cfg.Server.Addr)
+			}
+			if cfg.Server.ReadTimeout != 5*time.Second || cfg.Logging.Level != "debug" {
+				t.Errorf("file values not applied: readTimeout %s, level %q", cfg.Server.ReadTimeout, cfg.Logging.Level)
+			}
+			if cfg.Server.WriteTimeout != Default().Server.WriteTimeout {
+				t.Errorf("writeTimeout = %s, want the default", cfg.Server.WriteTimeout)
+			}
+		})
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "unknown yaml key", file: "c.yaml", content: "environment: development\nservr:\n  addr: x\n", wantErr: "servr"},
+		{name: "unknown toml key", file: "c.toml", content: "environment = \"development\"\n[servr]\naddr = \"x\"\n", wantErr: "servr"},
+		{name: "unsupported format", file: "c.json", content: "{}", wantErr: "must be .yaml"},
+		{name: "invalid duration", file: "c.yaml", content: "environment: development\n", env: map[string]string{"TOKEN_TTL": "soon"}, wantErr: "invalid TOKEN_TTL"},
+		{name: "value and file", file: "c.yaml", content: "environment: development\n", env: map[string]string{"ADMIN_API_KEYS": "a:b", "ADMIN_API_KEYS_FILE": "/dev/null"}, wantErr: "only one of ADMIN_API_KEYS"},
+		{name: "invalid setting", file: "c.yaml", content: "environment: development\nrecovery:\n  maxAttempts: 0\n", wantErr: "recovery.maxAttempts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			_, err := Load(writeFile(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Load error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	keysFile := writeFile(t, "admin_keys", "ops:from-file\n")
+	env := map[string]string{
+		"ADMIN_API_KEYS_FILE":     keysFile,
+		"TRUSTED_PROXIES":         "10.0.0.0/8, ,192.168.1.1",
+		"RECOVERY_ENABLED":        "false",
+		"RECOVERY_MAX_ATTEMPTS":   "7",
+		"OTEL_TRACES_SAMPLER_ARG": "0.25",
+	}
+	cfg := Default()
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), lookup); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Admin.APIKeys != "ops:from-file" {
+		t.Errorf("admin keys = %q, want the file's contents without the newline", cfg.Admin.APIKeys)
+	}
+	if got := strings.Join(cfg.Server.TrustedProxies, "|"); got != "10.0.0.0/8|192.168.1.1" {
+		t.Errorf("trusted proxies = %q", got)
+	}
+	if cfg.Recovery.Enabled || cfg.Recovery.MaxAttempts != 7 || cfg.Tracing.SampleRatio != 0.25 {
+		t.Errorf("recovery %+v, sample ratio %v", cfg.Recovery, cfg.Tracing.SampleRatio)
+	}
+}
+
+func TestValidateProduction(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*Config)
+		wantErr string
+	}{
+		{name: "defaults without demo data"},
+		{name: "demo data", change: func(c *Config) { c.DemoData = true }, wantErr: "demoData"},
+		{name: "short admin key", change: func(c *Config) { c.Admin.APIKeys = "ops:short" }, wantErr: `admin key "ops"`},
+		{name: "checked-in signing key", change: func(c *Config) { c.Auth.SigningKeySecret = "jwt/signing_key" }, wantErr: "secrets.dir"},
+		{
+			name: "plain http vault",
+			change: func(c *Config) {
+				c.Secrets.Provider, c.Secrets.VaultAddr, c.Secrets.VaultToken = SecretsProviderVault, "http://vault:8200", "t"
+			},
+			wantErr: "secrets.vaultAddr",
+		},
+		{name: "plain http gateway", change: func(c *Config) { c.Provisioning.GatewayURL = "http://gw" }, wantErr: "provisioning.gatewayUrl"},
+		{name: "trust every proxy", change: func(c *Config) { c.Server.TrustedProxies = []string{"0.0.0.0/0"} }, wantErr: "server.trustedProxies"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.DemoData = false
+			if tt.change != nil {
+				tt.change(cfg)
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+
+			cfg.Environment = EnvironmentDevelopment
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("refused in development too: %v", err)
+			}
+		})
+	}
+}
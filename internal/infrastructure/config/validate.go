@@ -0,0 +1,176 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MinAdminKeyLength is the shortest admin API key accepted outside
+// development.
+const MinAdminKeyLength = 32
+
+// Validate reports every invalid setting at once. Outside development it
+// also refuses settings that are only safe on a workstation: the demo
+// partner with its published credentials, short admin keys and trusting
+// forwarding headers from any address.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch c.Environment {
+	case EnvironmentDevelopment, EnvironmentProduction:
+	default:
+		fail("environment must be %q or %q", EnvironmentDevelopment, EnvironmentProduction)
+	}
+
+	if c.Server.Addr == "" {
+		fail("server.addr is required")
+	}
+	if c.Metrics.Addr == "" {
+		fail("metrics.addr is required")
+	} else if c.Metrics.Addr == c.Server.Addr {
+		fail("metrics.addr must differ from server.addr")
+	}
+	for name, d := range map[string]time.Duration{
+		"server.readTimeout":             c.Server.ReadTimeout,
+		"server.writeTimeout":            c.Server.WriteTimeout,
+		"server.idleTimeout":             c.Server.IdleTimeout,
+		"server.shutdownTimeout":         c.Server.ShutdownTimeout,
+		"tls.reloadInterval":             c.TLS.ReloadInterval,
+		"auth.keyRotationInterval":       c.Auth.KeyRotationInterval,
+		"auth.partnerCacheTtl":           c.Auth.PartnerCacheTTL,
+		"auth.signingKeyRefreshInterval": c.Auth.SigningKeyRefreshInterval,
+		"risk.reloadInterval":            c.Risk.ReloadInterval,
+		"review.sla":                     c.Review.SLA,
+		"health.checkTimeout":            c.Health.CheckTimeout,
+		"provisioning.timeout":           c.Provisioning.Timeout,
+		"recovery.interval":              c.Recovery.Interval,
+		"recovery.pendingThreshold":      c.Recovery.PendingThreshold,
+	} {
+		if d <= 0 {
+			fail("%s must be positive", name)
+		}
+	}
+	if c.Server.DrainDelay < 0 {
+		fail("server.drainDelay must not be negative")
+	}
+	if c.Health.CacheTTL < 0 {
+		fail("health.cacheTtl must not be negative")
+	}
+
+	switch c.TLS.ClientAuth {
+	case "none", "optional", "require":
+	default:
+		fail("tls.clientAuth must be none, optional or require")
+	}
+	if c.TLS.Enabled() && c.TLS.KeyFile == "" {
+		fail("tls.keyFile is required with tls.certFile")
+	}
+	if !c.TLS.Enabled() && (c.TLS.KeyFile != "" || c.TLS.ClientCAFile != "") {
+		fail("tls.certFile is required with tls.keyFile or tls.clientCaFile")
+	}
+	if c.TLS.ClientAuth != "none" && c.TLS.ClientCAFile == "" {
+		fail("tls.clientCaFile is required when tls.clientAuth is %s", c.TLS.ClientAuth)
+	}
+
+	if c.Auth.TokenTTL < time.Minute || c.Auth.TokenTTL > 24*time.Hour {
+		fail("auth.tokenTtl must be between 1m and 24h")
+	}
+	if c.Auth.RefreshTokenTTL < c.Auth.TokenTTL {
+		fail("auth.refreshTokenTtl must not be shorter than auth.tokenTtl")
+	}
+	switch c.Auth.SigningAlgorithm {
+	case "RS256", "ES256", "EdDSA":
+	default:
+		fail("auth.signingAlgorithm must be RS256, ES256 or EdDSA")
+	}
+
+	switch c.Secrets.Provider {
+	case SecretsProviderFile:
+		if c.Secrets.Dir == "" {
+			fail("secrets.dir is required with the file provider")
+		}
+	case SecretsProviderEnv:
+	case SecretsProviderVault:
+		if c.Secrets.VaultAddr == "" || c.Secrets.VaultToken == "" {
+			fail("secrets.vaultAddr and secrets.vaultToken are required with the vault provider")
+		}
+	default:
+		fail("secrets.provider must be file, env or vault")
+	}
+	if c.Secrets.CacheTTL < 0 {
+		fail("secrets.cacheTtl must not be negative")
+	}
+
+	if c.Provisioning.GatewayURL != "" {
+		if u, err := url.Parse(c.Provisioning.GatewayURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("provisioning.gatewayUrl must be an http or https URL")
+		}
+	}
+	if c.Recovery.MaxAttempts < 1 {
+		fail("recovery.maxAttempts must be at least 1")
+	}
+	if c.Recovery.BatchSize < 1 {
+		fail("recovery.batchSize must be at least 1")
+	}
+
+	switch c.Tracing.Exporter {
+	case "", "none", "console", "otlp":
+	default:
+		fail("tracing.exporter must be none, console or otlp")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sampleRatio must be between 0 and 1")
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+		fail("logging.level must be debug, info, warn or error")
+	}
+	switch c.Logging.Format {
+	case "json", "text":
+	default:
+		fail("logging.format must be json or text")
+	}
+
+	if !c.IsDevelopment() {
+		errs = append(errs, c.validateProduction()...)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) validateProduction() []error {
+	var errs []error
+
+	if c.DemoData {
+		errs = append(errs, errors.New("demoData must be disabled outside development: the demo partner's credentials are public"))
+	}
+	for _, entry := range strings.Split(c.Admin.APIKeys, ",") {
+		name, key, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if ok && len(key) < MinAdminKeyLength {
+			errs = append(errs, fmt.Errorf("admin key %q must be at least %d characters outside development", name, MinAdminKeyLength))
+		}
+	}
+	if c.Auth.SigningKeySecret != "" && c.Secrets.Provider == SecretsProviderFile && filepath.Clean(c.Secrets.Dir) == DevSecretsDir {
+		errs = append(errs, errors.New("secrets.dir must not be the checked-in development secrets outside development"))
+	}
+	if c.Secrets.Provider == SecretsProviderVault && strings.HasPrefix(c.Secrets.VaultAddr, "http://") {
+		errs = append(errs, errors.New("secrets.vaultAddr must use https outside development"))
+	}
+	if strings.HasPrefix(c.Provisioning.GatewayURL, "http://") {
+		errs = append(errs, errors.New("provisioning.gatewayUrl must use https outside development"))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if proxy == "0.0.0.0/0" || proxy == "::/0" {
+			errs = append(errs, fmt.Errorf("server.trustedProxies must not include %s outside development", proxy))
+		}
+	}
+	return errs
+}
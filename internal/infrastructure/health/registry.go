@@ -0,0 +1,179 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the outcome of a single check or of the whole report.
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusFailing  Status = "failing"
+	StatusDraining Status = "draining"
+)
+
+// Check reports whether a dependency is usable. It should return promptly
+// once ctx is done.
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status    Status    `json:"status"`
+	Checks    []Result  `json:"checks"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Redacted returns a copy of the report without check errors, which can
+// name hosts, addresses and credentials, for callers that are not trusted.
+func (r *Report) Redacted() *Report {
+	redacted := *r
+	redacted.Checks = make([]Result, len(r.Checks))
+	for i, result := range r.Checks {
+		result.Error = ""
+		redacted.Checks[i] = result
+	}
+	return &redacted
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Registry runs readiness checks. Results are cached for cacheTTL so probes
+// from several load balancers do not multiply the load on dependencies, and
+// readiness is reported as draining once shutdown begins.
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks []namedCheck
+
+	// refreshMu lets a single caller run the checks while others wait for
+	// its result.
+	refreshMu sync.Mutex
+	cached    *Report
+}
+
+// NewRegistry builds an empty registry. Each check is cancelled after
+// timeout.
+func NewRegistry(timeout, cacheTTL time.Duration) *Registry {
+	return &Registry{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Register adds a named check. Checks run concurrently and are reported in
+// registration order.
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining marks the service as shutting down. Readiness fails from then
+// on so load balancers stop routing new requests before the listener closes.
+func (r *Registry) SetDraining() {
+	r.draining.Store(true)
+}
+
+// Ready returns the readiness report, running the checks if the cached
+// report has expired.
+func (r *Registry) Ready(ctx context.Context) *Report {
+	report := r.cachedReport(ctx)
+	if r.draining.Load() {
+		draining := *report
+		draining.Status = StatusDraining
+		return &draining
+	}
+	return report
+}
+
+func (r *Registry) cachedReport(ctx context.Context) *Report {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+
+	if r.cached != nil && time.Since(r.cached.CheckedAt) < r.cacheTTL {
+		return r.cached
+	}
+	r.cached = r.run(ctx)
+	return r.cached
+}
+
+func (r *Registry) run(ctx context.Context) *Report {
+	r.mu.RLock()
+	checks := append([]namedCheck(nil), r.checks...)
+	r.mu.RUnlock()
+
+	// Checks are detached from the probe request so one impatient client
+	// cannot poison the cached result with cancellations.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	defer cancel()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, Checks: results, CheckedAt: time.Now()}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFailing
+			slog.Warn("readiness check failing", "check", result.Name, "error", result.Error)
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, c namedCheck) Result {
+	start := time.Now()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- c.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out: %w", ctx.Err())
+	}
+
+	result := Result{
+		Name:      c.name,
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		wantStatus Status
+		wantErrors map[string]string
+	}{
+		{
+			name:       "all passing",
+			checks:     map[string]Check{"a": func(context.Context) error { return nil }},
+			wantStatus: StatusOK,
+		},
+		{
+			name: "one failing",
+			checks: map[string]Check{
+				"a": func(context.Context) error { return nil },
+				"b": func(context.Context) error { return errors.New("dial tcp 10.0.0.5:5432: refused") },
+			},
+			wantStatus: StatusFailing,
+			wantErrors: map[string]string{"b": "dial tcp 10.0.0.5:5432: refused"},
+		},
+		{
+			name:       "panicking",
+			checks:     map[string]Check{"a": func(context.Context) error { panic("boom") }},
+			wantStatus: StatusFailing,
+			wantErrors: map[string]string{"a": "check panicked: boom"},
+		},
+		{
+			name: "timing out",
+			checks: map[string]Check{"a": func(ctx context.Context) error {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			}},
+			wantStatus: StatusFailing,
+			wantErrors: map[string]string{"a": "timed out: context deadline exceeded"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(50*time.Millisecond, time.Minute)
+			for name, check := range tt.checks {
+				r.Register(name, check)
+			}
+
+			report := r.Ready(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Fatalf("status %s, want %s", report.Status, tt.wantStatus)
+			}
+			for _, result := range report.Checks {
+				if result.Error != tt.wantErrors[result.Name] {
+					t.Errorf("check %s error %q, want %q", result.Name, result.Error, tt.wantErrors[result.Name])
+				}
+			}
+
+			for _, result := range report.Redacted().Checks {
+				if result.Error != "" {
+					t.Errorf("redacted check %s kept error %q", result.Name, result.Error)
+				}
+			}
+			if report.Redacted().Status != report.Status {
+				t.Errorf("redacted status %s, want %s", report.Redacted().Status, report.Status)
+			}
+		})
+	}
+}
+
+func TestRegistryCachesAndDrains(t *testing.T) {
+	r := NewRegistry(time.Second, time.Minute)
+	calls := 0
+	r.Register("a", func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	r.Ready(context.Background())
+	r.Ready(context.Background())
+	if calls != 1 {
+		t.Fatalf("check ran %d times within the cache TTL, want 1", calls)
+	}
+
+	r.SetDraining()
+	if got := r.Ready(context.Background()).Status; got != StatusDraining {
+		t.Fatalf("status while draining %s, want %s", got, StatusDraining)
+	}
+}
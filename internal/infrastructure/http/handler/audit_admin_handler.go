@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+type AuditAdminHandler struct {
+	auditLog *application.AuditLog
+}
+
+func NewAuditAdminHandler(auditLog *application.AuditLog) *AuditAdminHandler {
+	return &AuditAdminHandler{
+		auditLog: auditLog,
+	}
+}
+
+// Query lists audit entries matching the query parameters, oldest first.
+// Pass the returned nextAfter as ?after= to fetch the next page.
+func (h *AuditAdminHandler) Query(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	resp, err := h.auditLog.Query(r.Context(), filter)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to query audit log")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// Verify recomputes the hash chain and reports the first broken link.
+func (h *AuditAdminHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	result, err := h.auditLog.Verify(r.Context())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to verify audit log")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, result)
+}
+
+func parseAuditFilter(q url.Values) (repository.AuditFilter, error) {
+	filter := repository.AuditFilter{
+		Action:       entity.AuditAction(q.Get("action")),
+		Actor:        q.Get("actor"),
+		ResourceType: q.Get("resourceType"),
+		ResourceID:   q.Get("resourceId"),
+	}
+
+	var err error
+	if v := q.Get("since"); v != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.New("since must be an RFC 3339 timestamp")
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.New("until must be an RFC 3339 timestamp")
+		}
+	}
+	if v := q.Get("after"); v != "" {
+		if filter.AfterSequence, err = strconv.ParseInt(v, 10, 64); err != nil || filter.AfterSequence < 0 {
+			return filter, errors.New("after must be a sequence number")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		filter.Limit, err = strconv.Atoi(v)
+		if err != nil || filter.Limit < 1 || filter.Limit > application.MaxAuditQueryLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", application.MaxAuditQueryLimit)
+		}
+	}
+	return filter, nil
+}
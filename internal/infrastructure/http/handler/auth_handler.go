@@ -1,10 +1,17 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/request"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
 )
 
@@ -12,29 +19,301 @@ type AuthHandler struct {
 	authUseCase *application.AuthUseCase
 }
 
+// clientRequestBody accepts the OAuth2 parameter names alongside the camelCase
+// names from the API specification and the legacy apiKey/apiSecret pair.
+type clientRequestBody struct {
+	GrantType     string `json:"grant_type" validate:"max=64"`
+	ClientID      string `json:"client_id" validate:"max=128"`
+	ClientSecret  string `json:"client_secret" validate:"max=256"`
+	Scope         string `json:"scope" validate:"max=512"`
+	RefreshToken  string `json:"refresh_token" validate:"max=256"`
+	Token         string `json:"token" validate:"max=4096"`
+	TokenTypeHint string `json:"token_type_hint" validate:"max=64"`
+
+	ClientIDCamel      string `json:"clientId" validate:"max=128"`
+	ClientSecretCamel  string `json:"clientSecret" validate:"max=256"`
+	RefreshTokenCamel  string `json:"refreshToken" validate:"max=256"`
+	TokenTypeHintCamel string `json:"tokenTypeHint" validate:"max=64"`
+
+	APIKey    string `json:"apiKey" validate:"max=128"`
+	APISecret string `json:"apiSecret" validate:"max=256"`
+}
+
+// clientRequest is a parsed token or revocation request. OAuth reports
+// whether the caller used OAuth2 conventions and expects RFC 6749 responses.
+type clientRequest struct {
+	clientRequestBody
+	OAuth    bool
+	HasBasic bool
+}
+
+// oauthTokenResponse is the RFC 6749 section 5.1 access token response.
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
 func NewAuthHandler(authUseCase *application.AuthUseCase) *AuthHandler {
 	return &AuthHandler{
 		authUseCase: authUseCase,
 	}
 }
 
+// CreateToken implements the OAuth2 client-credentials and refresh-token
+// grants. Requests that are form-encoded, carry grant_type, or use HTTP Basic
+// client authentication are treated as OAuth2 requests and receive RFC 6749
+// responses; plain JSON requests keep the documented camelCase response and
+// error envelope.
 func (h *AuthHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
-	var req application.AuthRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+	req, ok := h.parseClientRequest(w, r)
+	if !ok {
 		return
 	}
 
-	if req.APIKey == "" || req.APISecret == "" {
-		response.Error(w, http.StatusBadRequest, "MISSING_FIELDS", "apiKey and apiSecret are required")
+	if req.OAuth && req.GrantType == "" {
+		response.OAuthError(w, http.StatusBadRequest, "invalid_request", "grant_type is required")
 		return
 	}
 
-	authResp, err := h.authUseCase.Authenticate(r.Context(), req)
+	if req.ClientID == "" || req.ClientSecret == "" {
+		writeMissingClientCredentials(w, req.OAuth)
+		return
+	}
+
+	authResp, err := h.authUseCase.Authenticate(r.Context(), application.AuthRequest{
+		GrantType:    req.GrantType,
+		ClientID:     req.ClientID,
+		ClientSecret: req.ClientSecret,
+		Scope:        req.Scope,
+		RefreshToken: req.RefreshToken,
+		Client:       clientInfo(r),
+	})
 	if err != nil {
-		response.Error(w, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid client credentials")
+		writeAuthError(w, err, req.OAuth, req.HasBasic)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if req.OAuth {
+		response.JSON(w, http.StatusOK, oauthTokenResponse{
+			AccessToken:  authResp.AccessToken,
+			TokenType:    authResp.TokenType,
+			ExpiresIn:    authResp.ExpiresIn,
+			RefreshToken: authResp.RefreshToken,
+			Scope:        authResp.Scope,
+		})
 		return
 	}
 
 	response.JSON(w, http.StatusOK, authResp)
 }
+
+// RevokeToken implements RFC 7009 token revocation for access and refresh
+// tokens. It responds 200 for unknown tokens so callers cannot probe them.
+func (h *AuthHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.parseClientRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if req.ClientID == "" || req.ClientSecret == "" {
+		writeMissingClientCredentials(w, req.OAuth)
+		return
+	}
+
+	if req.Token == "" {
+		if req.OAuth {
+			response.OAuthError(w, http.StatusBadRequest, "invalid_request", "token is required")
+			return
+		}
+		response.Error(w, http.StatusBadRequest, "MISSING_FIELDS", "token is required")
+		return
+	}
+
+	err := h.authUseCase.Revoke(r.Context(), application.RevokeRequest{
+		ClientID:      req.ClientID,
+		ClientSecret:  req.ClientSecret,
+		Token:         req.Token,
+		TokenTypeHint: req.TokenTypeHint,
+		Client:        clientInfo(r),
+	})
+	if err != nil {
+		writeAuthError(w, err, req.OAuth, req.HasBasic)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseClientRequest reads form-encoded or JSON parameters and HTTP Basic
+// client credentials, writing an error response and returning false on failure.
+func (h *AuthHandler) parseClientRequest(w http.ResponseWriter, r *http.Request) (clientRequest, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	basicID, basicSecret, hasBasic := basicClientCredentials(r)
+
+	req := clientRequest{OAuth: hasBasic, HasBasic: hasBasic}
+
+	if mediaType == "application/x-www-form-urlencoded" {
+		req.OAuth = true
+		r.Body = http.MaxBytesReader(w, r.Body, request.DefaultMaxBodyBytes)
+		if err := r.ParseForm(); err != nil {
+			response.OAuthError(w, http.StatusBadRequest, "invalid_request", "Request body could not be parsed")
+			return req, false
+		}
+		req.GrantType = r.PostForm.Get("grant_type")
+		req.ClientID = r.PostForm.Get("client_id")
+		req.ClientSecret = r.PostForm.Get("client_secret")
+		req.Scope = r.PostForm.Get("scope")
+		req.RefreshToken = r.PostForm.Get("refresh_token")
+		req.Token = r.PostForm.Get("token")
+		req.TokenTypeHint = r.PostForm.Get("token_type_hint")
+	} else {
+		if err := request.DecodeJSON(w, r, &req.clientRequestBody); err != nil {
+			if req.OAuth {
+				response.OAuthError(w, http.StatusBadRequest, "invalid_request", err.Error())
+				return req, false
+			}
+			request.WriteError(w, err)
+			return req, false
+		}
+		req.ClientID = firstNonEmpty(req.ClientID, req.ClientIDCamel, req.APIKey)
+		req.ClientSecret = firstNonEmpty(req.ClientSecret, req.ClientSecretCamel, req.APISecret)
+		req.RefreshToken = firstNonEmpty(req.RefreshToken, req.RefreshTokenCamel)
+		req.TokenTypeHint = firstNonEmpty(req.TokenTypeHint, req.TokenTypeHintCamel)
+		req.OAuth = req.OAuth || req.GrantType != ""
+	}
+
+	if hasBasic {
+		if req.ClientID != "" || req.ClientSecret != "" {
+			response.OAuthError(w, http.StatusBadRequest, "invalid_request", "Only one client authentication method may be used")
+			return req, false
+		}
+		req.ClientID, req.ClientSecret = basicID, basicSecret
+	}
+
+	return req, true
+}
+
+func writeMissingClientCredentials(w http.ResponseWriter, oauth bool) {
+	if oauth {
+		response.OAuthError(w, http.StatusBadRequest, "invalid_request", "client_id and client_secret are required")
+		return
+	}
+	response.Error(w, http.StatusBadRequest, "MISSING_FIELDS", "clientId and clientSecret are required")
+}
+
+func writeAuthError(w http.ResponseWriter, err error, oauth, hasBasic bool) {
+	var lockout *application.LockoutError
+
+	switch {
+	case errors.As(err, &lockout):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockout.RetryAfter.Seconds()))))
+		if oauth {
+			response.OAuthError(w, http.StatusTooManyRequests, "temporarily_unavailable", "Too many failed attempts; try again later")
+			return
+		}
+		response.Error(w, http.StatusTooManyRequests, "TOO_MANY_ATTEMPTS", "Too many failed attempts; try again later")
+	case errors.Is(err, application.ErrAuthBusy):
+		w.Header().Set("Retry-After", "1")
+		if oauth {
+			response.OAuthError(w, http.StatusServiceUnavailable, "temporarily_unavailable", "Authentication is busy; try again shortly")
+			return
+		}
+		response.Error(w, http.StatusServiceUnavailable, "AUTH_BUSY", "Authentication is busy; try again shortly")
+	case errors.Is(err, application.ErrUnsupportedGrantType):
+		if oauth {
+			response.OAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "Grant type is not supported")
+			return
+		}
+		response.Error(w, http.StatusBadRequest, "UNSUPPORTED_GRANT_TYPE", "Grant type is not supported")
+	case errors.Is(err, application.ErrInvalidGrant):
+		if oauth {
+			response.OAuthError(w, http.StatusBadRequest, "invalid_grant", "Refresh token is invalid, expired or revoked")
+			return
+		}
+		response.Error(w, http.StatusBadRequest, "INVALID_GRANT", "Refresh token is invalid, expired or revoked")
+	case errors.Is(err, application.ErrInvalidScope):
+		if oauth {
+			response.OAuthError(w, http.StatusBadRequest, "invalid_scope", "Requested scope is unknown or not granted")
+			return
+		}
+		response.Error(w, http.StatusBadRequest, "INVALID_SCOPE", "Requested scope is unknown or not granted")
+	case errors.Is(err, application.ErrInvalidCredentials):
+		if oauth {
+			if hasBasic {
+				w.Header().Set("WWW-Authenticate", `Basic realm="token"`)
+			}
+			response.OAuthError(w, http.StatusUnauthorized, "invalid_client", "Invalid client credentials")
+			return
+		}
+		response.Error(w, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid client credentials")
+	case errors.Is(err, application.ErrIPNotAllowed):
+		if oauth {
+			response.OAuthError(w, http.StatusBadRequest, "unauthorized_client", "Source IP address is not allowed")
+			return
+		}
+		response.Error(w, http.StatusForbidden, "IP_NOT_ALLOWED", "Source IP address is not allowed")
+	case errors.Is(err, application.ErrAuthModeNotAllowed):
+		if oauth {
+			response.OAuthError(w, http.StatusBadRequest, "unauthorized_client", "Partner does not accept bearer tokens")
+			return
+		}
+		response.Error(w, http.StatusForbidden, "AUTH_MODE_NOT_ALLOWED", "Partner does not accept bearer tokens")
+	case errors.Is(err, entity.ErrPartnerInactive),
+		errors.Is(err, entity.ErrPartnerSuspended),
+		errors.Is(err, entity.ErrPartnerPendingApproval):
+		if oauth {
+			response.OAuthError(w, http.StatusBadRequest, "unauthorized_client", err.Error())
+			return
+		}
+		middleware.WritePartnerStatusError(w, err)
+	default:
+		if oauth {
+			response.OAuthError(w, http.StatusInternalServerError, "server_error", "Request could not be processed")
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be processed")
+	}
+}
+
+// basicClientCredentials reads HTTP Basic client authentication. Per RFC 6749
+// section 2.3.1 the client ID and secret are form-urlencoded before encoding.
+func basicClientCredentials(r *http.Request) (string, string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return "", "", false
+	}
+
+	if decoded, err := url.QueryUnescape(username); err == nil {
+		username = decoded
+	}
+	if decoded, err := url.QueryUnescape(password); err == nil {
+		password = decoded
+	}
+
+	return username, password, true
+}
+
+func clientInfo(r *http.Request) application.ClientInfo {
+	thumbprint, subject := middleware.ClientCertificate(r)
+	return application.ClientInfo{
+		IP:             middleware.ClientIP(r),
+		CertThumbprint: thumbprint,
+		CertSubject:    subject,
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,410 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/security"
+)
+
+const (
+	testClientID     = "acme"
+	testClientSecret = "acme_secret_value"
+	testClientIP     = "198.51.100.7"
+)
+
+type tokenTestEnv struct {
+	handler  *AuthHandler
+	partners repository.PartnerRepository
+	jwt      *auth.JWTService
+	revoked  repository.RevokedTokenRepository
+}
+
+// newTokenTestEnv serves the token endpoints for partner acme, which may only
+// call from 198.51.100.0/24.
+func newTokenTestEnv(t *testing.T) *tokenTestEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	partners, err := inmemory.NewInMemoryPartnerRepository(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := auth.HashSecret(testClientSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partner := entity.NewPartner("partner_acme", "Acme", testClientID, "wlt_acme")
+	partner.Scopes = []string{entity.ScopeTransactionsRead}
+	partner.AuthModes = []entity.AuthMode{entity.AuthModeBearer}
+	partner.AllowedCIDRs = []string{"198.51.100.0/24"}
+	partner.AddClientSecret(entity.ClientSecret{ID: "sec_acme", Hash: hash, CreatedAt: time.Now()})
+	if err := partners.Create(ctx, partner); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := auth.NewKeyManager(auth.KeyManagerConfig{Algorithm: auth.AlgorithmES256, GracePeriod: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := application.DefaultLoginGuardConfig()
+	config.BaseDelay = 0
+	events := security.NewLogEventPublisher()
+
+	env := &tokenTestEnv{
+		partners: partners,
+		jwt:      auth.NewJWTService(keys),
+		revoked:  inmemory.NewInMemoryRevokedTokenRepository(),
+	}
+	env.handler = NewAuthHandler(application.NewAuthUseCase(
+		partners,
+		inmemory.NewInMemoryRefreshTokenRepository(),
+		env.revoked,
+		env.jwt,
+		application.NewLoginGuard(inmemory.NewInMemoryLoginAttemptRepository(), events, config),
+		events,
+		application.NewAuditLog(inmemory.NewInMemoryAuditRepository()),
+		application.AuthConfig{},
+	))
+	return env
+}
+
+// post sends form parameters from ip, authenticating the client with HTTP
+// Basic unless the parameters already carry client_id.
+func (env *tokenTestEnv) post(h http.HandlerFunc, ip string, params url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/v1/auth/token", strings.NewReader(params.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if params.Get("client_id") == "" {
+		r.SetBasicAuth(testClientID, testClientSecret)
+	}
+	r.RemoteAddr = ip + ":4000"
+	w := httptest.NewRecorder()
+	h(w, r)
+	return w
+}
+
+func (env *tokenTestEnv) token(t *testing.T, params url.Values) (int, oauthTokenResponse, string) {
+	t.Helper()
+	w := env.post(env.handler.CreateToken, testClientIP, params)
+	var body struct {
+		oauthTokenResponse
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return w.Code, body.oauthTokenResponse, body.Error
+}
+
+func (env *tokenTestEnv) clientCredentials(t *testing.T) oauthTokenResponse {
+	t.Helper()
+	code, resp, oauthErr := env.token(t, url.Values{"grant_type": {"client_credentials"}})
+	if code != http.StatusOK {
+		t.Fatalf("client_credentials: status %d, error %q", code, oauthErr)
+	}
+	return resp
+}
+
+func (env *tokenTestEnv) refresh(t *testing.T, refreshToken string) (int, oauthTokenResponse, string) {
+	t.Helper()
+	return env.token(t, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body response.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("status %d with undecodable body: %v", w.Code, err)
+	}
+	return body.Error.Code
+}
+
+func TestCreateTokenErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		ip         string
+		params     url.Values
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "client credentials",
+			ip:         testClientIP,
+			params:     url.Values{"grant_type": {"client_credentials"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong secret",
+			ip:         testClientIP,
+			params:     url.Values{"grant_type": {"client_credentials"}, "client_id": {testClientID}, "client_secret": {"wrong"}},
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "invalid_client",
+		},
+		{
+			name:       "address outside allowlist",
+			ip:         "203.0.113.9",
+			params:     url.Values{"grant_type": {"client_credentials"}},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "unauthorized_client",
+		},
+		{
+			name:       "missing grant type",
+			ip:         testClientIP,
+			params:     url.Values{},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_request",
+		},
+		{
+			name:       "unsupported grant type",
+			ip:         testClientIP,
+			params:     url.Values{"grant_type": {"password"}},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "unsupported_grant_type",
+		},
+		{
+			name:       "scope not granted",
+			ip:         testClientIP,
+			params:     url.Values{"grant_type": {"client_credentials"}, "scope": {entity.ScopeTransactionsWrite}},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_scope",
+		},
+		{
+			name:       "unknown refresh token",
+			ip:         testClientIP,
+			params:     url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"rt_unknown"}},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid_grant",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTokenTestEnv(t)
+			w := env.post(env.handler.CreateToken, tt.ip, tt.params)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var body struct {
+				AccessToken string `json:"access_token"`
+				Error       string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != tt.wantError {
+				t.Fatalf("error %q, want %q", body.Error, tt.wantError)
+			}
+			if tt.wantError == "" && body.AccessToken == "" {
+				t.Fatal("no access token issued")
+			}
+			if got := w.Header().Get("Cache-Control"); got != "no-store" {
+				t.Fatalf("Cache-Control %q, want no-store", got)
+			}
+		})
+	}
+}
+
+func TestCreateTokenBasicChallenge(t *testing.T) {
+	env := newTokenTestEnv(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/auth/token", strings.NewReader("grant_type=client_credentials"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth(testClientID, "wrong")
+	r.RemoteAddr = testClientIP + ":4000"
+	w := httptest.NewRecorder()
+	env.handler.CreateToken(w, r)
+
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("status %d, WWW-Authenticate %q; want 401 with a challenge", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	env := newTokenTestEnv(t)
+	first := env.clientCredentials(t)
+	if first.RefreshToken == "" {
+		t.Fatal("no refresh token issued")
+	}
+
+	code, second, oauthErr := env.refresh(t, first.RefreshToken)
+	if code != http.StatusOK {
+		t.Fatalf("refresh: status %d, error %q", code, oauthErr)
+	}
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh token not rotated: %q", second.RefreshToken)
+	}
+	if second.AccessToken == first.AccessToken || second.Scope != first.Scope {
+		t.Fatalf("second grant %+v, first %+v", second, first)
+	}
+
+	code, third, oauthErr := env.refresh(t, second.RefreshToken)
+	if code != http.StatusOK || third.RefreshToken == "" {
+		t.Fatalf("refresh with the rotated token: status %d, error %q", code, oauthErr)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	env := newTokenTestEnv(t)
+	first := env.clientCredentials(t)
+	code, second, _ := env.refresh(t, first.RefreshToken)
+	if code != http.StatusOK {
+		t.Fatalf("refresh: status %d", code)
+	}
+
+	// Presenting the exchanged token again looks like theft.
+	if code, _, oauthErr := env.refresh(t, first.RefreshToken); code != http.StatusBadRequest || oauthErr != "invalid_grant" {
+		t.Fatalf("reused refresh token: status %d, error %q; want 400 invalid_grant", code, oauthErr)
+	}
+
+	if code, _, oauthErr := env.refresh(t, second.RefreshToken); code != http.StatusBadRequest || oauthErr != "invalid_grant" {
+		t.Fatalf("newest refresh token of the family: status %d, error %q; want 400 invalid_grant", code, oauthErr)
+	}
+	for name, token := range map[string]string{"first": first.AccessToken, "second": second.AccessToken} {
+		if !env.accessTokenRevoked(t, token) {
+			t.Errorf("%s access token of the family not revoked", name)
+		}
+	}
+
+	// Tokens from another grant are unaffected.
+	other := env.clientCredentials(t)
+	if env.accessTokenRevoked(t, other.AccessToken) {
+		t.Fatal("access token from a separate grant revoked")
+	}
+	if code, _, oauthErr := env.refresh(t, other.RefreshToken); code != http.StatusOK {
+		t.Fatalf("refresh token from a separate grant: status %d, error %q", code, oauthErr)
+	}
+}
+
+func (env *tokenTestEnv) setPartnerStatus(t *testing.T, status entity.PartnerStatus) {
+	t.Helper()
+	ctx := context.Background()
+	partner, err := env.partners.FindByID(ctx, "partner_acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partner.Status = status
+	if err := env.partners.Update(ctx, partner); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateTokenPartnerStatus(t *testing.T) {
+	tests := []struct {
+		status   entity.PartnerStatus
+		wantCode string
+	}{
+		{status: entity.PartnerStatusSuspended, wantCode: "PARTNER_SUSPENDED"},
+		{status: entity.PartnerStatusInactive, wantCode: "PARTNER_INACTIVE"},
+		{status: entity.PartnerStatusPendingApproval, wantCode: "PARTNER_PENDING_APPROVAL"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			env := newTokenTestEnv(t)
+			grant := env.clientCredentials(t)
+			env.setPartnerStatus(t, tt.status)
+
+			// JSON clients get the partner status error.
+			body := `{"clientId":"` + testClientID + `","clientSecret":"` + testClientSecret + `"}`
+			r := httptest.NewRequest(http.MethodPost, "/v1/auth/token", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			r.RemoteAddr = testClientIP + ":4000"
+			w := httptest.NewRecorder()
+			env.handler.CreateToken(w, r)
+			if w.Code != http.StatusForbidden || errorCode(t, w) != tt.wantCode {
+				t.Fatalf("JSON token request: status %d: %s; want 403 %s", w.Code, w.Body.String(), tt.wantCode)
+			}
+
+			// OAuth2 clients get unauthorized_client, for new grants and
+			// refreshes of grants issued while the partner was active.
+			if code, _, oauthErr := env.token(t, url.Values{"grant_type": {"client_credentials"}}); code != http.StatusBadRequest || oauthErr != "unauthorized_client" {
+				t.Fatalf("client_credentials: status %d, error %q; want 400 unauthorized_client", code, oauthErr)
+			}
+			if code, _, oauthErr := env.refresh(t, grant.RefreshToken); code != http.StatusBadRequest || oauthErr != "unauthorized_client" {
+				t.Fatalf("refresh: status %d, error %q; want 400 unauthorized_client", code, oauthErr)
+			}
+		})
+	}
+}
+
+func (env *tokenTestEnv) accessTokenRevoked(t *testing.T, token string) bool {
+	t.Helper()
+	claims, err := env.jwt.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	revoked, err := env.revoked.IsRevoked(context.Background(), claims.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return revoked
+}
+
+func TestRevokeToken(t *testing.T) {
+	// Revoking a refresh token ends the whole grant, including the access
+	// tokens issued with it.
+	tests := []struct {
+		name        string
+		token       func(oauthTokenResponse) string
+		hint        string
+		wantAccess  bool
+		wantRefresh bool
+	}{
+		{name: "access token", token: func(g oauthTokenResponse) string { return g.AccessToken }, wantAccess: true},
+		{name: "access token with wrong hint", token: func(g oauthTokenResponse) string { return g.AccessToken }, hint: "refresh_token", wantAccess: true},
+		{name: "refresh token", token: func(g oauthTokenResponse) string { return g.RefreshToken }, hint: "refresh_token", wantAccess: true, wantRefresh: true},
+		{name: "refresh token without hint", token: func(g oauthTokenResponse) string { return g.RefreshToken }, wantAccess: true, wantRefresh: true},
+		{name: "unknown token", token: func(oauthTokenResponse) string { return "rt_unknown" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTokenTestEnv(t)
+			grant := env.clientCredentials(t)
+
+			params := url.Values{"token": {tt.token(grant)}}
+			if tt.hint != "" {
+				params.Set("token_type_hint", tt.hint)
+			}
+			w := env.post(env.handler.RevokeToken, testClientIP, params)
+			if w.Code != http.StatusOK {
+				t.Fatalf("revoke: status %d: %s", w.Code, w.Body.String())
+			}
+
+			if got := env.accessTokenRevoked(t, grant.AccessToken); got != tt.wantAccess {
+				t.Errorf("access token revoked = %v, want %v", got, tt.wantAccess)
+			}
+			code, _, _ := env.refresh(t, grant.RefreshToken)
+			if refreshRevoked := code != http.StatusOK; refreshRevoked != tt.wantRefresh {
+				t.Errorf("refresh token revoked = %v (status %d), want %v", refreshRevoked, code, tt.wantRefresh)
+			}
+		})
+	}
+}
+
+func TestRevokeTokenRequiresClientAuthentication(t *testing.T) {
+	env := newTokenTestEnv(t)
+	grant := env.clientCredentials(t)
+
+	w := env.post(env.handler.RevokeToken, testClientIP, url.Values{
+		"token":         {grant.AccessToken},
+		"client_id":     {testClientID},
+		"client_secret": {"wrong"},
+	})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401", w.Code)
+	}
+	if env.accessTokenRevoked(t, grant.AccessToken) {
+		t.Fatal("token revoked without client authentication")
+	}
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/request"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+type CredentialHandler struct {
+	credentialUseCase *application.CredentialUseCase
+}
+
+type rotateSecretBody struct {
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds" validate:"gte=0"`
+}
+
+func NewCredentialHandler(credentialUseCase *application.CredentialUseCase) *CredentialHandler {
+	return &CredentialHandler{
+		credentialUseCase: credentialUseCase,
+	}
+}
+
+// RotateSecret issues a new client secret for the authenticated partner. The
+// plaintext secret appears in this response only and cannot be retrieved later.
+func (h *CredentialHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	h.rotate(w, r, middleware.GetPartnerID(r.Context()))
+}
+
+// RotatePartnerSecret issues a new client secret for the partner named in the
+// path, for operators provisioning or recovering a partner's credentials.
+func (h *CredentialHandler) RotatePartnerSecret(w http.ResponseWriter, r *http.Request) {
+	h.rotate(w, r, chi.URLParam(r, "partnerId"))
+}
+
+func (h *CredentialHandler) rotate(w http.ResponseWriter, r *http.Request, partnerID string) {
+	var body rotateSecretBody
+	if r.ContentLength != 0 {
+		if err := request.DecodeJSON(w, r, &body); err != nil {
+			request.WriteError(w, err)
+			return
+		}
+	}
+
+	var req application.RotateSecretRequest
+	if body.GracePeriodSeconds != nil {
+		grace := time.Duration(*body.GracePeriodSeconds) * time.Second
+		req.GracePeriod = &grace
+	}
+
+	resp, err := h.credentialUseCase.RotateSecret(r.Context(), partnerID, req)
+	if err != nil {
+		writeCredentialError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusCreated, resp)
+}
+
+func (h *CredentialHandler) ListSecrets(w http.ResponseWriter, r *http.Request) {
+	secrets, err := h.credentialUseCase.ListSecrets(r.Context(), middleware.GetPartnerID(r.Context()))
+	if err != nil {
+		writeCredentialError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"secrets": secrets,
+	})
+}
+
+func (h *CredentialHandler) RevokeSecret(w http.ResponseWriter, r *http.Request) {
+	secretID := chi.URLParam(r, "secretId")
+	if secretID == "" {
+		response.Error(w, http.StatusBadRequest, "MISSING_SECRET_ID", "Secret ID is required")
+		return
+	}
+
+	if err := h.credentialUseCase.RevokeSecret(r.Context(), middleware.GetPartnerID(r.Context()), secretID); err != nil {
+		writeCredentialError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeCredentialError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, application.ErrPartnerNotFound):
+		response.Error(w, http.StatusNotFound, "PARTNER_NOT_FOUND", "Partner not found")
+	case errors.Is(err, application.ErrSecretNotFound):
+		response.Error(w, http.StatusNotFound, "SECRET_NOT_FOUND", "Client secret not found")
+	case errors.Is(err, application.ErrPartnerConflict):
+		response.Error(w, http.StatusConflict, "PARTNER_CONFLICT", "The partner was changed by another request; retry")
+	case errors.Is(err, application.ErrTooManySecrets):
+		response.Error(w, http.StatusConflict, "TOO_MANY_SECRETS", "Revoke an existing client secret before rotating again")
+	case errors.Is(err, application.ErrLastSecret):
+		response.Error(w, http.StatusConflict, "LAST_SECRET", "The only active client secret cannot be revoked")
+	case errors.Is(err, application.ErrInvalidGracePeriod):
+		response.Error(w, http.StatusBadRequest, "INVALID_GRACE_PERIOD", "gracePeriodSeconds must be between 0 and 2592000")
+	default:
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be processed")
+	}
+}
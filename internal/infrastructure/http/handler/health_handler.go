@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/health"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+	}
+}
+
+// Livez reports that the process is running and serving HTTP. It checks no
+// dependencies, so an outage elsewhere does not get the process restarted.
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"status": health.StatusOK,
+	})
+}
+
+// Readyz reports whether the service should receive traffic: 200 when every
+// check passes, 503 when any fails or the server is draining. It is public,
+// so check errors are left out; they are logged and served by ReadyzDetails.
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, h.registry.Ready(r.Context()).Redacted())
+}
+
+// ReadyzDetails is Readyz including each failing check's error, for
+// operators.
+func (h *HealthHandler) ReadyzDetails(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, h.registry.Ready(r.Context()))
+}
+
+func writeReport(w http.ResponseWriter, report *health.Report) {
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, status, report)
+}
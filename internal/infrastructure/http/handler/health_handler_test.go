@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/health"
+)
+
+func TestReadyzHidesCheckErrors(t *testing.T) {
+	registry := health.NewRegistry(time.Second, time.Minute)
+	registry.Register("database", func(context.Context) error {
+		return errors.New("dial tcp 10.0.0.5:5432: password authentication failed")
+	})
+	h := NewHealthHandler(registry)
+
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantError bool
+	}{
+		{"public", h.Readyz, false},
+		{"admin", h.ReadyzDetails, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != http.StatusServiceUnavailable {
+				t.Fatalf("status %d, want 503", w.Code)
+			}
+			var report health.Report
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Checks) != 1 || report.Checks[0].Status != health.StatusFailing {
+				t.Fatalf("checks = %+v, want one failing check", report.Checks)
+			}
+			if gotError := report.Checks[0].Error != ""; gotError != tt.wantError {
+				t.Fatalf("error %q shown = %v, want %v", report.Checks[0].Error, gotError, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+type JWKSHandler struct {
+	keyManager *auth.KeyManager
+}
+
+func NewJWKSHandler(keyManager *auth.KeyManager) *JWKSHandler {
+	return &JWKSHandler{
+		keyManager: keyManager,
+	}
+}
+
+// GetJWKS publishes the public keys used to sign access tokens so downstream
+// services can verify them. The cache lifetime is kept short so consumers
+// pick up rotated keys well within the rotation grace period.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(auth.JWKSCacheTTL.Seconds())))
+	response.JSON(w, http.StatusOK, h.keyManager.JWKS())
+}
@@ -0,0 +1,414 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/request"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+)
+
+type PartnerAdminHandler struct {
+	partnerAdminUseCase *application.PartnerAdminUseCase
+}
+
+type createPartnerBody struct {
+	Name                    string                   `json:"name" validate:"required,max=100"`
+	ClientID                string                   `json:"clientId" validate:"max=64"`
+	WalletID                string                   `json:"walletId" validate:"required,max=64"`
+	Scopes                  []string                 `json:"scopes" validate:"required"`
+	AuthModes               []entity.AuthMode        `json:"authModes"`
+	AllowedTransactionTypes []entity.TransactionType `json:"allowedTransactionTypes"`
+	SpendingLimits          []entity.SpendingLimit   `json:"spendingLimits"`
+}
+
+type updatePartnerBody struct {
+	Name      *string            `json:"name"`
+	Scopes    *[]string          `json:"scopes"`
+	AuthModes *[]entity.AuthMode `json:"authModes"`
+}
+
+type setWalletBody struct {
+	WalletID string `json:"walletId" validate:"required,max=64"`
+}
+
+type setTransactionTypesBody struct {
+	AllowedTransactionTypes []entity.TransactionType `json:"allowedTransactionTypes" validate:"required"`
+}
+
+type partnerStatusBody struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type setAllowedIPsBody struct {
+	// A pointer so that an explicit empty list, which clears the allowlist,
+	// is distinguishable from a missing field.
+	AllowedCIDRs *[]string `json:"allowedCidrs" validate:"required"`
+}
+
+type setClientCertificatesBody struct {
+	// Pointers so that explicit empty lists, which remove the binding, are
+	// distinguishable from missing fields.
+	Thumbprints *[]string `json:"clientCertThumbprints" validate:"required"`
+	Subjects    *[]string `json:"clientCertSubjects" validate:"required"`
+}
+
+type setRateLimitsBody struct {
+	RateLimits map[entity.RateLimitGroup]int `json:"rateLimits"`
+}
+
+type setSpendingLimitsBody struct {
+	SpendingLimits []entity.SpendingLimit `json:"spendingLimits"`
+}
+
+func NewPartnerAdminHandler(partnerAdminUseCase *application.PartnerAdminUseCase) *PartnerAdminHandler {
+	return &PartnerAdminHandler{
+		partnerAdminUseCase: partnerAdminUseCase,
+	}
+}
+
+// CreatePartner registers a partner and returns its first credentials. The
+// plaintext secrets appear in this response only and cannot be retrieved
+// later.
+func (h *PartnerAdminHandler) CreatePartner(w http.ResponseWriter, r *http.Request) {
+	var body createPartnerBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	resp, err := h.partnerAdminUseCase.CreatePartner(r.Context(), application.CreatePartnerRequest{
+		Name:                    body.Name,
+		ClientID:                body.ClientID,
+		WalletID:                body.WalletID,
+		Scopes:                  body.Scopes,
+		AuthModes:               body.AuthModes,
+		AllowedTransactionTypes: body.AllowedTransactionTypes,
+		SpendingLimits:          body.SpendingLimits,
+	})
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin created partner",
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", resp.Partner.ID, "client_id", resp.Partner.ClientID)
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusCreated, resp)
+}
+
+// ListPartners lists partners ordered by ID, optionally filtered by ?status=.
+// Pass the returned nextAfter as ?after= to fetch the next page.
+func (h *PartnerAdminHandler) ListPartners(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := application.ListPartnersRequest{
+		Status: entity.PartnerStatus(q.Get("status")),
+		After:  q.Get("after"),
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > application.MaxPartnerListLimit {
+			response.Error(w, http.StatusBadRequest, "INVALID_QUERY", fmt.Sprintf("limit must be between 1 and %d", application.MaxPartnerListLimit))
+			return
+		}
+		req.Limit = limit
+	}
+
+	resp, err := h.partnerAdminUseCase.ListPartners(r.Context(), req)
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func (h *PartnerAdminHandler) GetPartner(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.partnerAdminUseCase.GetPartner(r.Context(), chi.URLParam(r, "partnerId"))
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// UpdatePartner changes the fields present in the body: name, scopes and
+// authModes.
+func (h *PartnerAdminHandler) UpdatePartner(w http.ResponseWriter, r *http.Request) {
+	var body updatePartnerBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	partnerID := chi.URLParam(r, "partnerId")
+	resp, err := h.partnerAdminUseCase.UpdatePartner(r.Context(), partnerID, application.UpdatePartnerRequest{
+		Name:      body.Name,
+		Scopes:    body.Scopes,
+		AuthModes: body.AuthModes,
+	})
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin updated partner",
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", partnerID)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// SetWallet assigns the wallet the partner's purchases are funded from.
+func (h *PartnerAdminHandler) SetWallet(w http.ResponseWriter, r *http.Request) {
+	var body setWalletBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	partnerID := chi.URLParam(r, "partnerId")
+	resp, err := h.partnerAdminUseCase.SetWallet(r.Context(), partnerID, body.WalletID)
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin set partner wallet",
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", partnerID, "wallet_id", resp.WalletID)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// SetTransactionTypes replaces the transaction types the partner may create.
+func (h *PartnerAdminHandler) SetTransactionTypes(w http.ResponseWriter, r *http.Request) {
+	var body setTransactionTypesBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	partnerID := chi.URLParam(r, "partnerId")
+	resp, err := h.partnerAdminUseCase.SetAllowedTransactionTypes(r.Context(), partnerID, body.AllowedTransactionTypes)
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin set allowed transaction types",
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", partnerID, "transaction_types", resp.AllowedTransactionTypes)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// Suspend blocks the partner from the API until it is reactivated.
+func (h *PartnerAdminHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "admin suspended partner", h.partnerAdminUseCase.SuspendPartner)
+}
+
+// Reactivate returns a suspended or inactive partner to ACTIVE.
+func (h *PartnerAdminHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "admin reactivated partner", h.partnerAdminUseCase.ReactivatePartner)
+}
+
+func (h *PartnerAdminHandler) changeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	msg string,
+	change func(ctx context.Context, partnerID, reason string) (*application.PartnerResponse, error),
+) {
+	var body partnerStatusBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	partnerID := chi.URLParam(r, "partnerId")
+	resp, err := change(r.Context(), partnerID, body.Reason)
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info(msg,
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", partnerID, "reason", body.Reason)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func (h *PartnerAdminHandler) GetAllowedIPs(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.partnerAdminUseCase.GetAllowedIPs(r.Context(), chi.URLParam(r, "partnerId"))
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// SetAllowedIPs replaces the partner's IP allowlist. Send an empty list to
+// allow any address.
+func (h *PartnerAdminHandler) SetAllowedIPs(w http.ResponseWriter, r *http.Request) {
+	var body setAllowedIPsBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	partnerID := chi.URLParam(r, "partnerId")
+	resp, err := h.partnerAdminUseCase.SetAllowedIPs(r.Context(), partnerID, *body.AllowedCIDRs)
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin set allowed IPs",
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", partnerID, "allowed_cidrs", resp.AllowedCIDRs)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func (h *PartnerAdminHandler) GetClientCertificates(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.partnerAdminUseCase.GetClientCertificates(r.Context(), chi.URLParam(r, "partnerId"))
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// SetClientCertificates replaces the certificates the partner is bound to.
+// Send empty lists to remove the binding.
+func (h *PartnerAdminHandler) SetClientCertificates(w http.ResponseWriter, r *http.Request) {
+	var body setClientCertificatesBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	partnerID := chi.URLParam(r, "partnerId")
+	resp, err := h.partnerAdminUseCase.SetClientCertificates(r.Context(), partnerID, *body.Thumbprints, *body.Subjects)
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin set client certificates",
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", partnerID,
+		"thumbprints", resp.Thumbprints, "subjects", resp.Subjects)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func (h *PartnerAdminHandler) GetRateLimits(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.partnerAdminUseCase.GetRateLimits(r.Context(), chi.URLParam(r, "partnerId"))
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// SetRateLimits replaces the partner's per-minute rate limit overrides. Send
+// an empty object to restore the defaults.
+func (h *PartnerAdminHandler) SetRateLimits(w http.ResponseWriter, r *http.Request) {
+	var body setRateLimitsBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	partnerID := chi.URLParam(r, "partnerId")
+	resp, err := h.partnerAdminUseCase.SetRateLimits(r.Context(), partnerID, body.RateLimits)
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin set rate limits",
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", partnerID, "overrides", resp.Overrides)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func (h *PartnerAdminHandler) GetSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.partnerAdminUseCase.GetSpendingLimits(r.Context(), chi.URLParam(r, "partnerId"))
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// SetSpendingLimits replaces the partner's per-currency spending limits.
+func (h *PartnerAdminHandler) SetSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	var body setSpendingLimitsBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	partnerID := chi.URLParam(r, "partnerId")
+	resp, err := h.partnerAdminUseCase.SetSpendingLimits(r.Context(), partnerID, body.SpendingLimits)
+	if err != nil {
+		writePartnerAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin set spending limits",
+		"admin", middleware.GetAdminActor(r.Context()), "partner_id", partnerID, "spending_limits", resp.SpendingLimits)
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func writePartnerAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, application.ErrPartnerNotFound):
+		response.Error(w, http.StatusNotFound, "PARTNER_NOT_FOUND", "Partner not found")
+	case errors.Is(err, application.ErrPartnerExists):
+		response.Error(w, http.StatusConflict, "PARTNER_EXISTS", "A partner with this client ID already exists")
+	case errors.Is(err, application.ErrPartnerConflict):
+		response.Error(w, http.StatusConflict, "PARTNER_CONFLICT", "The partner was changed by another request; retry")
+	case errors.Is(err, application.ErrInvalidPartnerStatus):
+		response.Error(w, http.StatusConflict, "INVALID_STATUS_CHANGE", err.Error())
+	case errors.Is(err, application.ErrInvalidPartner):
+		response.Error(w, http.StatusBadRequest, "INVALID_PARTNER", err.Error())
+	case errors.Is(err, application.ErrInvalidCIDR):
+		response.Error(w, http.StatusBadRequest, "INVALID_CIDR", err.Error())
+	case errors.Is(err, application.ErrInvalidCertificate):
+		response.Error(w, http.StatusBadRequest, "INVALID_CLIENT_CERTIFICATE", err.Error())
+	case errors.Is(err, application.ErrInvalidRateLimit):
+		response.Error(w, http.StatusBadRequest, "INVALID_RATE_LIMIT", err.Error())
+	case errors.Is(err, application.ErrInvalidSpendingLimit):
+		response.Error(w, http.StatusBadRequest, "INVALID_SPENDING_LIMIT", err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be processed")
+	}
+}
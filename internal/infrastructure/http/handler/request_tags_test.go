@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/request"
+)
+
+// TestRequestBodyTags parses the validate tags of every request body, so a
+// malformed tag fails here rather than on a partner's request.
+func TestRequestBodyTags(t *testing.T) {
+	bodies := []interface{}{
+		application.CreateTransactionRequest{},
+		clientRequestBody{},
+		rotateSecretBody{},
+		createPartnerBody{},
+		updatePartnerBody{},
+		setWalletBody{},
+		setTransactionTypesBody{},
+		partnerStatusBody{},
+		setAllowedIPsBody{},
+		setClientCertificatesBody{},
+		setRateLimitsBody{},
+		setSpendingLimitsBody{},
+		reviewDecisionBody{},
+		forceFailBody{},
+	}
+	for _, body := range bodies {
+		t.Run(fmt.Sprintf("%T", body), func(t *testing.T) {
+			if err := request.CheckTags(body); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestCreateTransactionRequestAmount(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		wantRule string
+	}{
+		{amount: 10},
+		{amount: 0, wantRule: "required"},
+		{amount: -10, wantRule: "gt"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.amount), func(t *testing.T) {
+			err := request.Validate(application.CreateTransactionRequest{UserID: "u1", Amount: tt.amount, Currency: "USD"})
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			var reqErr *request.Error
+			if !errors.As(err, &reqErr) || len(reqErr.Fields) != 1 || reqErr.Fields[0].Field != "amount" || reqErr.Fields[0].Rule != tt.wantRule {
+				t.Fatalf("error = %+v, want amount failing %s", err, tt.wantRule)
+			}
+		})
+	}
+}
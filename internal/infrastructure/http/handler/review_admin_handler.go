@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/request"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+type ReviewAdminHandler struct {
+	reviewUseCase *application.ReviewUseCase
+}
+
+type reviewDecisionBody struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func NewReviewAdminHandler(reviewUseCase *application.ReviewUseCase) *ReviewAdminHandler {
+	return &ReviewAdminHandler{
+		reviewUseCase: reviewUseCase,
+	}
+}
+
+func (h *ReviewAdminHandler) ListHeld(w http.ResponseWriter, r *http.Request) {
+	items, err := h.reviewUseCase.ListHeld(r.Context())
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"transactions": items,
+	})
+}
+
+func (h *ReviewAdminHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	item, err := h.reviewUseCase.GetReview(r.Context(), chi.URLParam(r, "transactionId"))
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, item)
+}
+
+// Approve resumes processing of a held transaction.
+func (h *ReviewAdminHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.reviewUseCase.Approve)
+}
+
+// Reject fails a held transaction.
+func (h *ReviewAdminHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.reviewUseCase.Reject)
+}
+
+func (h *ReviewAdminHandler) decide(
+	w http.ResponseWriter,
+	r *http.Request,
+	decide func(ctx context.Context, transactionID, reviewer, reason string) (*application.ReviewItemResponse, error),
+) {
+	var body reviewDecisionBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	item, err := decide(r.Context(), chi.URLParam(r, "transactionId"), middleware.GetAdminActor(r.Context()), body.Reason)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, item)
+}
+
+func writeReviewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, application.ErrTransactionNotFound):
+		response.Error(w, http.StatusNotFound, "TRANSACTION_NOT_FOUND", "Transaction not found")
+	case errors.Is(err, application.ErrNotHeldForReview):
+		response.Error(w, http.StatusConflict, "NOT_HELD_FOR_REVIEW", "Transaction is not held for review")
+	case errors.Is(err, application.ErrTransactionBusy):
+		response.Error(w, http.StatusConflict, "TRANSACTION_BUSY", "Transaction is being decided elsewhere; retry shortly")
+	default:
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be processed")
+	}
+}
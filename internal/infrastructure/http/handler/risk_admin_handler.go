@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/risk"
+)
+
+type RiskAdminHandler struct {
+	riskEngine *risk.Engine
+	auditLog   *application.AuditLog
+}
+
+func NewRiskAdminHandler(riskEngine *risk.Engine, auditLog *application.AuditLog) *RiskAdminHandler {
+	return &RiskAdminHandler{
+		riskEngine: riskEngine,
+		auditLog:   auditLog,
+	}
+}
+
+// GetRules returns the risk rules currently in force.
+func (h *RiskAdminHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.riskEngine.Rules())
+}
+
+// ReloadRules re-reads the rules file. The previous rules stay in force if
+// the file is invalid.
+func (h *RiskAdminHandler) ReloadRules(w http.ResponseWriter, r *http.Request) {
+	before := h.riskEngine.Rules()
+	if err := h.riskEngine.Reload(); err != nil {
+		response.Error(w, http.StatusUnprocessableEntity, "INVALID_RISK_RULES", err.Error())
+		return
+	}
+
+	rules := h.riskEngine.Rules()
+	entry := entity.NewAuditEntry(entity.AuditActionRiskRulesReloaded, "", entity.AuditResourceRiskRules, rules.Version)
+	entry.SetChange(before, rules)
+	h.auditLog.RecordCommitted(r.Context(), entry)
+
+	logging.FromContext(r.Context()).Info("admin reloaded risk rules",
+		"admin", middleware.GetAdminActor(r.Context()), "version", rules.Version)
+	response.JSON(w, http.StatusOK, rules)
+}
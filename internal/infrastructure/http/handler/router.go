@@ -1,25 +1,47 @@
 package handler
 
 import (
+	"log/slog"
 	"net/http"
+	"net/netip"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
 	appMiddleware "github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/metrics"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/tracing"
 )
 
 func SetupRouter(
 	authHandler *AuthHandler,
 	transactionHandler *TransactionHandler,
+	jwksHandler *JWKSHandler,
+	credentialHandler *CredentialHandler,
+	partnerAdminHandler *PartnerAdminHandler,
+	riskAdminHandler *RiskAdminHandler,
+	reviewAdminHandler *ReviewAdminHandler,
+	transactionAdminHandler *TransactionAdminHandler,
+	auditAdminHandler *AuditAdminHandler,
+	healthHandler *HealthHandler,
+	appMetrics *metrics.Metrics,
 	authMiddleware *appMiddleware.AuthMiddleware,
+	signatureMiddleware *appMiddleware.SignatureMiddleware,
+	ipAllowlistMiddleware *appMiddleware.IPAllowlistMiddleware,
+	rateLimitMiddleware *appMiddleware.RateLimitMiddleware,
+	adminAuthMiddleware *appMiddleware.AdminAuthMiddleware,
+	trustedProxies []netip.Prefix,
+	logger *slog.Logger,
 ) http.Handler {
 	r := chi.NewRouter()
 
 	// Global middleware
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	r.Use(appMetrics.Middleware)
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(appMiddleware.RealIP(trustedProxies))
+	r.Use(tracing.Middleware)
+	r.Use(appMiddleware.RequestLogger(logger))
+	r.Use(middleware.Recoverer)
 
 	// Health check
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -27,20 +49,95 @@ func SetupRouter(
 		w.Write([]byte("OK"))
 	})
 
+	// Orchestrator probes
+	r.Get("/livez", healthHandler.Livez)
+	r.Get("/readyz", healthHandler.Readyz)
+
+	// Public signing keys for downstream token verification
+	r.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// API v1 routes
 	r.Route("/v1", func(r chi.Router) {
-		// Public routes
-		r.Post("/auth/token", authHandler.CreateToken)
+		// Public routes, rate limited per source IP
+		r.Group(func(r chi.Router) {
+			r.Use(rateLimitMiddleware.Limit(entity.RateLimitGroupAuth))
 
-		// Protected routes
+			r.Post("/auth/token", authHandler.CreateToken)
+			r.Post("/auth/revoke", authHandler.RevokeToken)
+		})
+
+		// Protected routes, authenticated by HMAC request signature or bearer
+		// token. Source IPs are limited first so invalid credentials count.
 		r.Group(func(r chi.Router) {
+			r.Use(rateLimitMiddleware.LimitSourceIP(entity.SourceIPRateLimit))
+			r.Use(signatureMiddleware.Authenticate)
 			r.Use(authMiddleware.Authenticate)
+			r.Use(ipAllowlistMiddleware.Enforce)
 
 			// Transaction routes
-			r.Post("/transactions", transactionHandler.CreateTransaction)
-			r.Get("/transactions/{transactionId}", transactionHandler.GetTransaction)
+			r.Group(func(r chi.Router) {
+				r.Use(rateLimitMiddleware.Limit(entity.RateLimitGroupTransactions))
+
+				r.With(appMiddleware.RequireScope(entity.ScopeTransactionsWrite)).
+					Post("/transactions", transactionHandler.CreateTransaction)
+				r.With(appMiddleware.RequireScope(entity.ScopeTransactionsRead)).
+					Get("/transactions/{transactionId}", transactionHandler.GetTransaction)
+				r.With(appMiddleware.RequireScope(entity.ScopeTransactionsRead)).
+					Get("/limits", transactionHandler.GetLimits)
+			})
+
+			// Credential management routes
+			r.Group(func(r chi.Router) {
+				r.Use(rateLimitMiddleware.Limit(entity.RateLimitGroupCredentials))
+				r.Use(appMiddleware.RequireScope(entity.ScopeCredentialsManage))
+
+				r.Get("/credentials/secrets", credentialHandler.ListSecrets)
+				r.Post("/credentials/secrets", credentialHandler.RotateSecret)
+				r.Delete("/credentials/secrets/{secretId}", credentialHandler.RevokeSecret)
+			})
 		})
 	})
 
+	// Admin API, authenticated separately from partners
+	r.Route("/admin/v1", func(r chi.Router) {
+		r.Use(rateLimitMiddleware.LimitSourceIP(entity.SourceIPRateLimit))
+		r.Use(adminAuthMiddleware.Authenticate)
+
+		r.Get("/partners", partnerAdminHandler.ListPartners)
+		r.Post("/partners", partnerAdminHandler.CreatePartner)
+		r.Get("/partners/{partnerId}", partnerAdminHandler.GetPartner)
+		r.Patch("/partners/{partnerId}", partnerAdminHandler.UpdatePartner)
+		r.Post("/partners/{partnerId}/suspend", partnerAdminHandler.Suspend)
+		r.Post("/partners/{partnerId}/reactivate", partnerAdminHandler.Reactivate)
+		r.Post("/partners/{partnerId}/credentials/secrets", credentialHandler.RotatePartnerSecret)
+		r.Put("/partners/{partnerId}/wallet", partnerAdminHandler.SetWallet)
+		r.Put("/partners/{partnerId}/transaction-types", partnerAdminHandler.SetTransactionTypes)
+		r.Get("/partners/{partnerId}/allowed-ips", partnerAdminHandler.GetAllowedIPs)
+		r.Put("/partners/{partnerId}/allowed-ips", partnerAdminHandler.SetAllowedIPs)
+		r.Get("/partners/{partnerId}/client-certificates", partnerAdminHandler.GetClientCertificates)
+		r.Put("/partners/{partnerId}/client-certificates", partnerAdminHandler.SetClientCertificates)
+		r.Get("/partners/{partnerId}/rate-limits", partnerAdminHandler.GetRateLimits)
+		r.Put("/partners/{partnerId}/rate-limits", partnerAdminHandler.SetRateLimits)
+		r.Get("/partners/{partnerId}/spending-limits", partnerAdminHandler.GetSpendingLimits)
+		r.Put("/partners/{partnerId}/spending-limits", partnerAdminHandler.SetSpendingLimits)
+
+		r.Get("/risk/rules", riskAdminHandler.GetRules)
+		r.Post("/risk/rules/reload", riskAdminHandler.ReloadRules)
+
+		r.Get("/reviews", reviewAdminHandler.ListHeld)
+		r.Get("/reviews/{transactionId}", reviewAdminHandler.GetReview)
+		r.Post("/reviews/{transactionId}/approve", reviewAdminHandler.Approve)
+		r.Post("/reviews/{transactionId}/reject", reviewAdminHandler.Reject)
+
+		r.Get("/transactions", transactionAdminHandler.ListTransactions)
+		r.Get("/transactions/{transactionId}", transactionAdminHandler.GetTransaction)
+		r.Post("/transactions/{transactionId}/fail", transactionAdminHandler.ForceFail)
+
+		r.Get("/audit", auditAdminHandler.Query)
+		r.Get("/audit/verify", auditAdminHandler.Verify)
+
+		r.Get("/readyz", healthHandler.ReadyzDetails)
+	})
+
 	return r
 }
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/request"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+)
+
+type TransactionAdminHandler struct {
+	transactionAdminUseCase *application.TransactionAdminUseCase
+}
+
+type forceFailBody struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func NewTransactionAdminHandler(transactionAdminUseCase *application.TransactionAdminUseCase) *TransactionAdminHandler {
+	return &TransactionAdminHandler{
+		transactionAdminUseCase: transactionAdminUseCase,
+	}
+}
+
+// ListTransactions lists transactions matching the query parameters, oldest
+// first. Pass the returned nextAfter as ?after= to fetch the next page.
+func (h *TransactionAdminHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	req, err := parseListTransactionsRequest(r.URL.Query())
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	resp, err := h.transactionAdminUseCase.ListTransactions(r.Context(), req)
+	if err != nil {
+		writeTransactionAdminError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func (h *TransactionAdminHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.transactionAdminUseCase.GetTransaction(r.Context(), chi.URLParam(r, "transactionId"))
+	if err != nil {
+		writeTransactionAdminError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// ForceFail fails a transaction stuck in PENDING.
+func (h *TransactionAdminHandler) ForceFail(w http.ResponseWriter, r *http.Request) {
+	var body forceFailBody
+	if err := request.DecodeJSON(w, r, &body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+	if err := request.Validate(body); err != nil {
+		request.WriteError(w, err)
+		return
+	}
+
+	transactionID := chi.URLParam(r, "transactionId")
+	resp, err := h.transactionAdminUseCase.ForceFail(r.Context(), transactionID, body.Reason)
+	if err != nil {
+		writeTransactionAdminError(w, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("admin force-failed transaction",
+		"admin", middleware.GetAdminActor(r.Context()),
+		"transaction_id", transactionID,
+		"reason", body.Reason,
+	)
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func parseListTransactionsRequest(q url.Values) (application.ListTransactionsRequest, error) {
+	req := application.ListTransactionsRequest{
+		PartnerID: q.Get("partnerId"),
+		Status:    entity.TransactionStatus(q.Get("status")),
+		After:     q.Get("after"),
+	}
+
+	var err error
+	if v := q.Get("since"); v != "" {
+		if req.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return req, errors.New("since must be an RFC 3339 timestamp")
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if req.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return req, errors.New("until must be an RFC 3339 timestamp")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		req.Limit, err = strconv.Atoi(v)
+		if err != nil || req.Limit < 1 || req.Limit > application.MaxTransactionListLimit {
+			return req, fmt.Errorf("limit must be between 1 and %d", application.MaxTransactionListLimit)
+		}
+	}
+	return req, nil
+}
+
+func writeTransactionAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, application.ErrTransactionNotFound):
+		response.Error(w, http.StatusNotFound, "TRANSACTION_NOT_FOUND", "Transaction not found")
+	case errors.Is(err, application.ErrTransactionNotPending):
+		response.Error(w, http.StatusConflict, "TRANSACTION_NOT_PENDING", err.Error())
+	case errors.Is(err, application.ErrTransactionBusy):
+		response.Error(w, http.StatusConflict, "TRANSACTION_BUSY", "Transaction is being checked by the recovery sweep; retry shortly")
+	case errors.Is(err, application.ErrInvalidTransactionFilter):
+		response.Error(w, http.StatusBadRequest, "INVALID_QUERY", err.Error())
+	case errors.Is(err, application.ErrReasonRequired):
+		response.Error(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be processed")
+	}
+}
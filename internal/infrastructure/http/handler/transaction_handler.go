@@ -1,11 +1,13 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/request"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
 )
 
@@ -21,35 +23,53 @@ func NewTransactionHandler(transactionUseCase *application.TransactionUseCase) *
 
 func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 	var req application.CreateTransactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		request.WriteError(w, err)
 		return
 	}
 
-	// Validate required fields
-	if req.UserID == "" || req.Amount == 0 || req.Currency == "" {
-		response.Error(w, http.StatusBadRequest, "MISSING_FIELDS", "userId, amount, and currency are required")
-		return
-	}
-
-	txnResp, err := h.transactionUseCase.CreateTransaction(r.Context(), req)
+	txnResp, err := h.transactionUseCase.CreateTransaction(r.Context(), middleware.GetPartnerID(r.Context()), req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		code := "INTERNAL_ERROR"
-
-		switch err.Error() {
-		case "invalid amount":
-			statusCode = http.StatusBadRequest
-			code = "INVALID_AMOUNT"
+		var limitErr *application.LimitExceededError
+		if errors.As(err, &limitErr) {
+			response.LimitError(w, http.StatusUnprocessableEntity, "LIMIT_EXCEEDED", "Spending limit exceeded", response.LimitDetail{
+				Type:      string(limitErr.Type),
+				Currency:  limitErr.Currency,
+				Limit:     limitErr.Limit,
+				Used:      limitErr.Used,
+				Remaining: limitErr.Remaining,
+			})
+			return
 		}
 
-		response.Error(w, statusCode, code, err.Error())
+		switch {
+		case errors.Is(err, application.ErrTransactionDenied):
+			response.Error(w, http.StatusForbidden, "TRANSACTION_DENIED", "Transaction was declined")
+		case errors.Is(err, application.ErrTransactionTypeNotAllowed):
+			response.Error(w, http.StatusForbidden, "TRANSACTION_TYPE_NOT_ALLOWED", "Partner may not create this type of transaction")
+		case errors.Is(err, application.ErrInvalidAmount):
+			response.Error(w, http.StatusBadRequest, "INVALID_AMOUNT", "Amount must be greater than zero")
+		default:
+			response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be processed")
+		}
 		return
 	}
 
 	response.JSON(w, http.StatusOK, txnResp)
 }
 
+// GetLimits reports the partner's spending limits and usage. Pass the userId
+// query parameter to include that end user's daily usage.
+func (h *TransactionHandler) GetLimits(w http.ResponseWriter, r *http.Request) {
+	limits, err := h.transactionUseCase.GetLimits(r.Context(), middleware.GetPartnerID(r.Context()), r.URL.Query().Get("userId"))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be processed")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, limits)
+}
+
 func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
 	transactionID := chi.URLParam(r, "transactionId")
 	if transactionID == "" {
@@ -57,9 +77,13 @@ func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	txnResp, err := h.transactionUseCase.GetTransaction(r.Context(), transactionID)
+	txnResp, err := h.transactionUseCase.GetTransaction(r.Context(), middleware.GetPartnerID(r.Context()), transactionID)
 	if err != nil {
-		response.Error(w, http.StatusNotFound, "TRANSACTION_NOT_FOUND", "Transaction not found")
+		if errors.Is(err, application.ErrTransactionNotFound) {
+			response.Error(w, http.StatusNotFound, "TRANSACTION_NOT_FOUND", "Transaction not found")
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be processed")
 		return
 	}
 
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/risk"
+)
+
+type nopTransactionMetrics struct{}
+
+func (nopTransactionMetrics) TransactionStatusChanged(*entity.Transaction) {}
+
+// newTransactionTestRouter serves the partner transaction routes for
+// partner_acme, limited to 100 USD a day, and partner_other. The caller is
+// named by the X-Test-Partner header in place of authentication.
+func newTransactionTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	ctx := context.Background()
+
+	partners, err := inmemory.NewInMemoryPartnerRepository(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acme := entity.NewPartner("partner_acme", "Acme", "acme", "wlt_acme")
+	acme.SpendingLimits = []entity.SpendingLimit{{Currency: "USD", DailyMax: 100}}
+	other := entity.NewPartner("partner_other", "Other", "other", "wlt_other")
+	for _, p := range []*entity.Partner{acme, other} {
+		if err := partners.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history := inmemory.NewInMemoryPurchaseHistoryRepository()
+	engine, err := risk.NewEngine(history, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewTransactionHandler(application.NewTransactionUseCase(
+		inmemory.NewInMemoryTransactionRepository(),
+		partners,
+		inmemory.NewInMemorySpendingRepository(),
+		engine,
+		nopTransactionMetrics{},
+		application.NewAuditLog(inmemory.NewInMemoryAuditRepository()),
+	))
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.PartnerIDKey, r.Header.Get("X-Test-Partner"))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	r.Post("/v1/transactions", h.CreateTransaction)
+	r.Get("/v1/transactions/{transactionId}", h.GetTransaction)
+	r.Get("/v1/limits", h.GetLimits)
+	return r
+}
+
+func serve(router http.Handler, partnerID, method, target, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Test-Partner", partnerID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func purchaseBody(amount string) string {
+	return `{"userId":"u1","walletId":"wlt_u1","amount":` + amount + `,"currency":"USD","metadata":{"phoneNumber":"+254700000001"}}`
+}
+
+func TestCreateTransactionLimitExceeded(t *testing.T) {
+	router := newTransactionTestRouter(t)
+
+	if w := serve(router, "partner_acme", http.MethodPost, "/v1/transactions", purchaseBody("70")); w.Code != http.StatusOK {
+		t.Fatalf("first purchase: status %d: %s", w.Code, w.Body)
+	}
+
+	w := serve(router, "partner_acme", http.MethodPost, "/v1/transactions", purchaseBody("50"))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status %d, want 422: %s", w.Code, w.Body)
+	}
+	var body response.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := response.LimitDetail{Type: "DAILY", Currency: "USD", Limit: 100, Used: 70, Remaining: 30}
+	if body.Error.Code != "LIMIT_EXCEEDED" || body.Error.Limit == nil || *body.Error.Limit != want {
+		t.Fatalf("error %+v, limit %+v; want LIMIT_EXCEEDED with %+v", body.Error, body.Error.Limit, want)
+	}
+}
+
+func TestGetTransactionOtherPartner(t *testing.T) {
+	router := newTransactionTestRouter(t)
+
+	w := serve(router, "partner_acme", http.MethodPost, "/v1/transactions", purchaseBody("10"))
+	var created application.TransactionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.ID == "" {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		name      string
+		partnerID string
+		id        string
+		wantCode  int
+	}{
+		{name: "owner", partnerID: "partner_acme", id: created.ID, wantCode: http.StatusOK},
+		{name: "other partner", partnerID: "partner_other", id: created.ID, wantCode: http.StatusNotFound},
+		{name: "unknown transaction", partnerID: "partner_acme", id: "txn_missing", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.partnerID, http.MethodGet, "/v1/transactions/"+tt.id, "")
+			if w.Code != tt.wantCode {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode == http.StatusNotFound && errorCode(t, w) != "TRANSACTION_NOT_FOUND" {
+				t.Fatalf("body %s, want TRANSACTION_NOT_FOUND", w.Body)
+			}
+		})
+	}
+}
+
+func TestGetLimitsResponse(t *testing.T) {
+	router := newTransactionTestRouter(t)
+	if w := serve(router, "partner_acme", http.MethodPost, "/v1/transactions", purchaseBody("25")); w.Code != http.StatusOK {
+		t.Fatalf("purchase: status %d: %s", w.Code, w.Body)
+	}
+
+	w := serve(router, "partner_acme", http.MethodGet, "/v1/limits?userId=u1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	var body struct {
+		PartnerID string `json:"partnerId"`
+		UserID    string `json:"userId"`
+		Limits    []struct {
+			Currency  string          `json:"currency"`
+			Daily     json.RawMessage `json:"daily"`
+			Monthly   json.RawMessage `json:"monthly"`
+			UserDaily json.RawMessage `json:"userDaily"`
+		} `json:"limits"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.PartnerID != "partner_acme" || body.UserID != "u1" || len(body.Limits) != 1 {
+		t.Fatalf("body %s", w.Body)
+	}
+	usd := body.Limits[0]
+	var daily struct {
+		Limit     float64 `json:"limit"`
+		Used      float64 `json:"used"`
+		Remaining float64 `json:"remaining"`
+		ResetsAt  string  `json:"resetsAt"`
+	}
+	if err := json.Unmarshal(usd.Daily, &daily); err != nil {
+		t.Fatal(err)
+	}
+	if usd.Currency != "USD" || daily.Limit != 100 || daily.Used != 25 || daily.Remaining != 75 || !strings.HasSuffix(daily.ResetsAt, "T00:00:00Z") {
+		t.Fatalf("USD limits %s", w.Body)
+	}
+	// Only configured quotas are reported.
+	if usd.Monthly != nil || usd.UserDaily != nil {
+		t.Fatalf("unconfigured quotas reported: %s", w.Body)
+	}
+
+	w = serve(router, "partner_other", http.MethodGet, "/v1/limits", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"limits":[]`) {
+		t.Fatalf("partner without limits: status %d: %s", w.Code, w.Body)
+	}
+}
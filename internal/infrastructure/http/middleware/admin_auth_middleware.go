@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+const (
+	AdminKeyHeader = "X-Admin-Key"
+
+	AdminActorKey contextKey = "adminActor"
+)
+
+// AdminAuthMiddleware authenticates operators calling the admin API with
+// static API keys. It is deliberately separate from partner authentication:
+// partner credentials never grant admin access.
+type AdminAuthMiddleware struct {
+	// keys maps the SHA-256 of each key to the operator name it identifies.
+	keys map[[sha256.Size]byte]string
+}
+
+// NewAdminAuthMiddleware builds the middleware from operator name to key
+// pairs. With no keys configured every admin request is rejected.
+func NewAdminAuthMiddleware(keys map[string]string) *AdminAuthMiddleware {
+	hashed := make(map[[sha256.Size]byte]string, len(keys))
+	for actor, key := range keys {
+		hashed[sha256.Sum256([]byte(key))] = actor
+	}
+	return &AdminAuthMiddleware{keys: hashed}
+}
+
+// ParseAdminKeys parses a comma-separated list of name:key pairs.
+func ParseAdminKeys(list string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		actor, key, ok := strings.Cut(entry, ":")
+		if !ok || actor == "" || key == "" {
+			return nil, fmt.Errorf("admin key entry must be name:key")
+		}
+		if _, dup := keys[actor]; dup {
+			return nil, fmt.Errorf("duplicate admin key name %q", actor)
+		}
+		keys[actor] = key
+	}
+	return keys, nil
+}
+
+func (m *AdminAuthMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(AdminKeyHeader)
+		if key == "" {
+			response.Error(w, http.StatusUnauthorized, "MISSING_ADMIN_KEY", AdminKeyHeader+" header is required")
+			return
+		}
+
+		actor, ok := m.lookup(key)
+		if !ok {
+			response.Error(w, http.StatusUnauthorized, "INVALID_ADMIN_KEY", "Invalid admin key")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), AdminActorKey, actor)
+		ctx = application.WithActor(ctx, application.AdminActor(actor), ClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (m *AdminAuthMiddleware) lookup(key string) (string, bool) {
+	sum := sha256.Sum256([]byte(key))
+
+	// Compare against every key so timing does not reveal which matched.
+	var actor string
+	for candidate, name := range m.keys {
+		if subtle.ConstantTimeCompare(candidate[:], sum[:]) == 1 {
+			actor = name
+		}
+	}
+	return actor, actor != ""
+}
+
+// GetAdminActor returns the operator name of an authenticated admin request.
+func GetAdminActor(ctx context.Context) string {
+	if actor, ok := ctx.Value(AdminActorKey).(string); ok {
+		return actor
+	}
+	return ""
+}
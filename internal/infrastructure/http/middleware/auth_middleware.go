@@ -5,8 +5,12 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
 	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
 )
 
 type contextKey string
@@ -14,20 +18,41 @@ type contextKey string
 const (
 	PartnerIDKey contextKey = "partnerId"
 	ClientIDKey  contextKey = "clientId"
+	TokenIDKey   contextKey = "tokenId"
+	ScopesKey    contextKey = "scopes"
+	AuthModeKey  contextKey = "authMode"
 )
 
 type AuthMiddleware struct {
-	jwtService *auth.JWTService
+	jwtService       *auth.JWTService
+	revokedTokenRepo repository.RevokedTokenRepository
+	partnerRepo      repository.PartnerRepository
 }
 
-func NewAuthMiddleware(jwtService *auth.JWTService) *AuthMiddleware {
+// NewAuthMiddleware builds the bearer token middleware. partnerRepo is
+// consulted on every request to enforce partner status, so it should be
+// backed by a short-lived cache.
+func NewAuthMiddleware(
+	jwtService *auth.JWTService,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	partnerRepo repository.PartnerRepository,
+) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtService: jwtService,
+		jwtService:       jwtService,
+		revokedTokenRepo: revokedTokenRepo,
+		partnerRepo:      partnerRepo,
 	}
 }
 
+// Authenticate requires a valid bearer token, unless an earlier middleware
+// such as SignatureMiddleware has already authenticated the request.
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetAuthMode(r.Context()) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			response.Error(w, http.StatusUnauthorized, "MISSING_AUTH_TOKEN", "Authorization header is required")
@@ -41,13 +66,61 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		}
 
 		claims, err := m.jwtService.ValidateToken(parts[1])
+		if err != nil || claims.ID == "" {
+			response.Error(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired token")
+			return
+		}
+
+		revoked, err := m.revokedTokenRepo.IsRevoked(r.Context(), claims.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Token could not be verified")
+			return
+		}
+		if revoked {
+			response.Error(w, http.StatusUnauthorized, "TOKEN_REVOKED", "Token has been revoked")
+			return
+		}
+
+		partner, err := m.partnerRepo.FindByID(r.Context(), claims.PartnerID)
 		if err != nil {
 			response.Error(w, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired token")
 			return
 		}
+		if err := partner.CheckActive(); err != nil {
+			WritePartnerStatusError(w, err)
+			return
+		}
+
+		if !partner.AcceptsAuthMode(entity.AuthModeBearer) {
+			response.Error(w, http.StatusForbidden, "AUTH_MODE_NOT_ALLOWED", "Partner does not accept bearer tokens")
+			return
+		}
 
-		ctx := context.WithValue(r.Context(), PartnerIDKey, claims.PartnerID)
+		// Certificate-bound tokens (RFC 8705) are only accepted over the
+		// certificate they were issued to.
+		thumbprint, subject := ClientCertificate(r)
+		bound := claims.CertificateThumbprint()
+		if (bound != "" && bound != thumbprint) ||
+			(partner.RequiresClientCertificate() && (bound == "" || !partner.MatchesClientCertificate(thumbprint, subject))) {
+			response.Error(w, http.StatusUnauthorized, "CERTIFICATE_MISMATCH", "Token must be presented over the client certificate it was issued to")
+			return
+		}
+
+		// Scopes withdrawn from the partner stop working before the token expires.
+		var scopes []string
+		for _, s := range entity.ParseScope(claims.Scope) {
+			if partner.HasScope(s) {
+				scopes = append(scopes, s)
+			}
+		}
+
+		ctx := logging.WithPartnerID(r.Context(), claims.PartnerID)
+		ctx = application.WithActor(ctx, application.PartnerActor(claims.PartnerID), ClientIP(r))
+		ctx = context.WithValue(ctx, PartnerIDKey, claims.PartnerID)
 		ctx = context.WithValue(ctx, ClientIDKey, claims.ClientID)
+		ctx = context.WithValue(ctx, TokenIDKey, claims.ID)
+		ctx = context.WithValue(ctx, ScopesKey, scopes)
+		ctx = context.WithValue(ctx, AuthModeKey, entity.AuthModeBearer)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -66,3 +139,25 @@ func GetClientID(ctx context.Context) string {
 	}
 	return ""
 }
+
+func GetTokenID(ctx context.Context) string {
+	if tokenID, ok := ctx.Value(TokenIDKey).(string); ok {
+		return tokenID
+	}
+	return ""
+}
+
+func GetScopes(ctx context.Context) []string {
+	if scopes, ok := ctx.Value(ScopesKey).([]string); ok {
+		return scopes
+	}
+	return nil
+}
+
+// GetAuthMode returns how the request was authenticated, or "" if it was not.
+func GetAuthMode(ctx context.Context) entity.AuthMode {
+	if mode, ok := ctx.Value(AuthModeKey).(entity.AuthMode); ok {
+		return mode
+	}
+	return ""
+}
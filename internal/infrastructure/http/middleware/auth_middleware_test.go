@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/certs"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+var (
+	partnerCert = &x509.Certificate{Raw: []byte("partner certificate"), Subject: pkix.Name{CommonName: "partner"}}
+	otherCert   = &x509.Certificate{Raw: []byte("other certificate"), Subject: pkix.Name{CommonName: "other"}}
+)
+
+type bearerTestEnv struct {
+	middleware *AuthMiddleware
+	jwt        *auth.JWTService
+	revoked    repository.RevokedTokenRepository
+}
+
+func newBearerTestEnv(t *testing.T) *bearerTestEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	keys, err := auth.NewKeyManager(auth.KeyManagerConfig{Algorithm: auth.AlgorithmES256, GracePeriod: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwt := auth.NewJWTService(keys)
+
+	partners, err := inmemory.NewInMemoryPartnerRepository(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := entity.NewPartner("partner-active", "Active Partner", "active-client", "wallet-1")
+	active.Scopes = []string{"credit:read"}
+
+	suspended := entity.NewPartner("partner-suspended", "Suspended Partner", "suspended-client", "wallet-2")
+	suspended.Status = entity.PartnerStatusSuspended
+
+	hmacOnly := entity.NewPartner("partner-hmac", "HMAC Partner", "hmac-client", "wallet-3")
+	hmacOnly.AuthModes = []entity.AuthMode{entity.AuthModeHMAC}
+
+	bound := entity.NewPartner("partner-mtls", "mTLS Partner", "mtls-client", "wallet-4")
+	bound.ClientCertThumbprints = []string{certs.Thumbprint(partnerCert)}
+
+	for _, p := range []*entity.Partner{active, suspended, hmacOnly, bound} {
+		if err := partners.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revoked := inmemory.NewInMemoryRevokedTokenRepository()
+	return &bearerTestEnv{
+		middleware: NewAuthMiddleware(jwt, revoked, partners),
+		jwt:        jwt,
+		revoked:    revoked,
+	}
+}
+
+func (e *bearerTestEnv) token(t *testing.T, partnerID, scope, thumbprint string) (string, *auth.Claims) {
+	t.Helper()
+	token, claims, err := e.jwt.GenerateToken(partnerID, partnerID+"-client", scope, thumbprint, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token, claims
+}
+
+func withClientCert(r *http.Request, cert *x509.Certificate) *http.Request {
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	return r
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	env := newBearerTestEnv(t)
+
+	active, _ := env.token(t, "partner-active", "credit:read credit:write", "")
+	revoked, revokedClaims := env.token(t, "partner-active", "credit:read", "")
+	if err := env.revoked.Revoke(context.Background(), revokedClaims.ID, revokedClaims.ExpiresAt.Time); err != nil {
+		t.Fatal(err)
+	}
+	suspended, _ := env.token(t, "partner-suspended", "", "")
+	hmacOnly, _ := env.token(t, "partner-hmac", "", "")
+	unknown, _ := env.token(t, "partner-deleted", "", "")
+	boundToPartnerCert, _ := env.token(t, "partner-active", "credit:read", certs.Thumbprint(partnerCert))
+	mtlsBound, _ := env.token(t, "partner-mtls", "", certs.Thumbprint(partnerCert))
+	mtlsUnbound, _ := env.token(t, "partner-mtls", "", "")
+
+	tests := []struct {
+		name       string
+		header     string
+		cert       *x509.Certificate
+		wantCode   int
+		wantErr    string
+		wantScopes string
+	}{
+		{name: "valid token", header: "Bearer " + active, wantCode: http.StatusOK, wantScopes: "credit:read"},
+		{name: "no header", wantCode: http.StatusUnauthorized, wantErr: "MISSING_AUTH_TOKEN"},
+		{name: "basic auth", header: "Basic dXNlcjpwYXNz", wantCode: http.StatusUnauthorized, wantErr: "INVALID_AUTH_FORMAT"},
+		{name: "malformed token", header: "Bearer not-a-token", wantCode: http.StatusUnauthorized, wantErr: "INVALID_TOKEN"},
+		{name: "revoked token", header: "Bearer " + revoked, wantCode: http.StatusUnauthorized, wantErr: "TOKEN_REVOKED"},
+		{name: "deleted partner", header: "Bearer " + unknown, wantCode: http.StatusUnauthorized, wantErr: "INVALID_TOKEN"},
+		{name: "suspended partner", header: "Bearer " + suspended, wantCode: http.StatusForbidden, wantErr: "PARTNER_SUSPENDED"},
+		{name: "partner without bearer mode", header: "Bearer " + hmacOnly, wantCode: http.StatusForbidden, wantErr: "AUTH_MODE_NOT_ALLOWED"},
+		{name: "bound token over its certificate", header: "Bearer " + boundToPartnerCert, cert: partnerCert, wantCode: http.StatusOK, wantScopes: "credit:read"},
+		{name: "bound token without a certificate", header: "Bearer " + boundToPartnerCert, wantCode: http.StatusUnauthorized, wantErr: "CERTIFICATE_MISMATCH"},
+		{name: "bound token over another certificate", header: "Bearer " + boundToPartnerCert, cert: otherCert, wantCode: http.StatusUnauthorized, wantErr: "CERTIFICATE_MISMATCH"},
+		{name: "certificate partner with bound token", header: "Bearer " + mtlsBound, cert: partnerCert, wantCode: http.StatusOK},
+		{name: "certificate partner with unbound token", header: "Bearer " + mtlsUnbound, cert: partnerCert, wantCode: http.StatusUnauthorized, wantErr: "CERTIFICATE_MISMATCH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotScopes []string
+			h := env.middleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotScopes = GetScopes(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/transactions", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.cert != nil {
+				r = withClientCert(r, tt.cert)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantErr != "" {
+				if got := errorCode(t, w); got != tt.wantErr {
+					t.Fatalf("error code %s, want %s", got, tt.wantErr)
+				}
+				return
+			}
+			// Scopes the partner no longer holds are dropped from the token's.
+			if got := strings.Join(gotScopes, " "); got != tt.wantScopes {
+				t.Fatalf("scopes %q, want %q", got, tt.wantScopes)
+			}
+		})
+	}
+}
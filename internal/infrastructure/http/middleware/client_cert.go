@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/certs"
+)
+
+// ClientCertificate returns the x5t#S256 thumbprint and subject DN of the
+// client certificate verified during the TLS handshake, or empty strings if
+// the connection did not present a verified certificate.
+func ClientCertificate(r *http.Request) (thumbprint, subject string) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", ""
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	return certs.Thumbprint(cert), cert.Subject.String()
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+// IPAllowlistMiddleware rejects authenticated requests from addresses outside
+// the partner's allowed CIDR ranges. It must run after authentication.
+type IPAllowlistMiddleware struct {
+	partnerRepo repository.PartnerRepository
+	events      application.SecurityEventPublisher
+}
+
+func NewIPAllowlistMiddleware(partnerRepo repository.PartnerRepository, events application.SecurityEventPublisher) *IPAllowlistMiddleware {
+	return &IPAllowlistMiddleware{
+		partnerRepo: partnerRepo,
+		events:      events,
+	}
+}
+
+func (m *IPAllowlistMiddleware) Enforce(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		partnerID := GetPartnerID(r.Context())
+
+		partner, err := m.partnerRepo.FindByID(r.Context(), partnerID)
+		if err != nil {
+			response.Error(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid authentication")
+			return
+		}
+
+		ip := ClientIP(r)
+		if !partner.AllowsIP(ip) {
+			event := entity.NewSecurityEvent(entity.SecurityEventIPNotAllowed, "request from address outside allowlist")
+			event.PartnerID = partner.ID
+			event.ClientID = partner.ClientID
+			event.IP = ip
+			event.Details = map[string]string{"method": r.Method, "path": r.URL.Path}
+			m.events.Publish(r.Context(), event)
+
+			response.Error(w, http.StatusForbidden, "IP_NOT_ALLOWED", "Source IP address is not allowed")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+// WritePartnerStatusError writes the 403 response for a partner status error
+// and reports whether err was one.
+func WritePartnerStatusError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, entity.ErrPartnerSuspended):
+		response.Error(w, http.StatusForbidden, "PARTNER_SUSPENDED", "Partner account is suspended")
+	case errors.Is(err, entity.ErrPartnerPendingApproval):
+		response.Error(w, http.StatusForbidden, "PARTNER_PENDING_APPROVAL", "Partner account is pending approval")
+	case errors.Is(err, entity.ErrPartnerInactive):
+		response.Error(w, http.StatusForbidden, "PARTNER_INACTIVE", "Partner account is inactive")
+	default:
+		return false
+	}
+	return true
+}
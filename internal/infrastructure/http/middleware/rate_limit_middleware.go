@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+)
+
+// RateLimitFallbackKeys bounds the local store used while the shared rate
+// limit store is unavailable.
+const RateLimitFallbackKeys = 100_000
+
+// RateLimitMiddleware enforces per-minute request limits per route group.
+// Authenticated requests are counted per partner, honouring the partner's
+// overrides; unauthenticated requests are counted per source IP. When the
+// store fails, requests are counted in a local fallback store instead, so
+// limits still hold on each instance; if that fails too, requests are
+// refused rather than let through unlimited.
+type RateLimitMiddleware struct {
+	store       repository.RateLimitRepository
+	fallback    repository.RateLimitRepository
+	partnerRepo repository.PartnerRepository
+}
+
+func NewRateLimitMiddleware(store, fallback repository.RateLimitRepository, partnerRepo repository.PartnerRepository) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		store:       store,
+		fallback:    fallback,
+		partnerRepo: partnerRepo,
+	}
+}
+
+// Limit returns middleware applying group's limit. Place it after the
+// authentication middleware to count authenticated routes per partner.
+func (m *RateLimitMiddleware) Limit(group entity.RateLimitGroup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := entity.DefaultRateLimits[group]
+			key := "ratelimit:" + string(group) + ":ip:" + ClientIP(r)
+
+			if partnerID := GetPartnerID(r.Context()); partnerID != "" {
+				key = "ratelimit:" + string(group) + ":partner:" + partnerID
+				if partner, err := m.partnerRepo.FindByID(r.Context(), partnerID); err == nil {
+					limit = partner.RateLimit(group)
+				}
+			}
+
+			if m.allow(w, r, key, limit, true) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// LimitSourceIP returns middleware applying limit per source IP. Place it
+// before authentication so rejected credentials still count. It sets the
+// X-RateLimit headers only when rejecting, leaving them to the per-group
+// limit otherwise.
+func (m *RateLimitMiddleware) LimitSourceIP(limit int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if m.allow(w, r, "ratelimit:source:ip:"+ClientIP(r), limit, false) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// allow counts the request against key and writes a 429 response if it is
+// over limit.
+func (m *RateLimitMiddleware) allow(w http.ResponseWriter, r *http.Request, key string, limit int, headers bool) bool {
+	count, resetAt, err := m.store.Increment(r.Context(), key, entity.RateLimitWindow)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("rate limit store unavailable; using local limits", "key", key, "error", err)
+		count, resetAt, err = m.fallback.Increment(r.Context(), key, entity.RateLimitWindow)
+	}
+	if err != nil {
+		// Fail closed: without any counter the limit cannot be enforced.
+		logging.FromContext(r.Context()).Error("rate limit fallback unavailable", "key", key, "error", err)
+		w.Header().Set("Retry-After", "1")
+		response.Error(w, http.StatusServiceUnavailable, "RATE_LIMIT_UNAVAILABLE", "Request could not be rate limited; try again shortly")
+		return false
+	}
+
+	over := count > limit
+	if headers || over {
+		remaining := limit - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+	}
+	if !over {
+		return true
+	}
+
+	retryAfter := int(time.Until(resetAt).Round(time.Second) / time.Second)
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	response.Error(w, http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED", "Rate limit exceeded")
+	return false
+}
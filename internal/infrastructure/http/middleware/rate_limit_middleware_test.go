@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	return 0, time.Time{}, errors.New("store down")
+}
+
+func newTestRateLimitMiddleware(t *testing.T, store, fallback repository.RateLimitRepository) *RateLimitMiddleware {
+	t.Helper()
+	partners, err := inmemory.NewInMemoryPartnerRepository(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRateLimitMiddleware(store, fallback, partners)
+}
+
+func serve(h http.Handler, ip string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/v1/transactions/txn_1", nil)
+	r.RemoteAddr = ip + ":4000"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestLimitSourceIPRunsBeforeAuthentication(t *testing.T) {
+	m := newTestRateLimitMiddleware(t, inmemory.NewInMemoryRateLimitRepository(), inmemory.NewInMemoryRateLimitRepository())
+
+	authCalls := 0
+	rejectAll := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authCalls++
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	h := m.LimitSourceIP(3)(rejectAll(http.NotFoundHandler()))
+
+	for i := 0; i < 3; i++ {
+		if w := serve(h, "203.0.113.9"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("request %d: status %d, want 401", i+1, w.Code)
+		}
+	}
+	w := serve(h, "203.0.113.9")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("over limit: status %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" || w.Header().Get("X-RateLimit-Limit") != "3" {
+		t.Fatalf("over limit: missing rate limit headers: %v", w.Header())
+	}
+	if authCalls != 3 {
+		t.Fatalf("authentication ran %d times, want 3", authCalls)
+	}
+	if w := serve(h, "203.0.113.10"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("other IP: status %d, want 401", w.Code)
+	}
+}
+
+func TestLimitSourceIPLeavesHeadersToGroupLimit(t *testing.T) {
+	m := newTestRateLimitMiddleware(t, inmemory.NewInMemoryRateLimitRepository(), inmemory.NewInMemoryRateLimitRepository())
+	h := m.LimitSourceIP(5)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	if w := serve(h, "203.0.113.9"); w.Header().Get("X-RateLimit-Limit") != "" {
+		t.Fatalf("headers set under limit: %v", w.Header())
+	}
+}
+
+func TestRateLimitStoreFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback repository.RateLimitRepository
+		// codes are the statuses of successive requests from one IP.
+		codes []int
+	}{
+		{
+			name:     "fallback enforces limit",
+			fallback: inmemory.NewInMemoryRateLimitRepository(),
+			codes:    []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:     "no counter fails closed",
+			fallback: failingRateLimitStore{},
+			codes:    []int{http.StatusServiceUnavailable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestRateLimitMiddleware(t, failingRateLimitStore{}, tt.fallback)
+			h := m.LimitSourceIP(2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			for i, want := range tt.codes {
+				if w := serve(h, "203.0.113.9"); w.Code != want {
+					t.Fatalf("request %d: status %d, want %d", i+1, w.Code, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitGroupCountsPerPartner(t *testing.T) {
+	m := newTestRateLimitMiddleware(t, inmemory.NewInMemoryRateLimitRepository(), inmemory.NewInMemoryRateLimitRepository())
+	h := m.Limit(entity.RateLimitGroupCredentials)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	limit := entity.DefaultRateLimits[entity.RateLimitGroupCredentials]
+	for i := 0; i <= limit; i++ {
+		// Each request comes from a new address; the partner is what counts.
+		r := httptest.NewRequest(http.MethodGet, "/v1/credentials/secrets", nil)
+		r.RemoteAddr = fmt.Sprintf("203.0.113.%d:4000", i)
+		r = r.WithContext(context.WithValue(r.Context(), PartnerIDKey, "partner_acme"))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		want := http.StatusOK
+		if i == limit {
+			want = http.StatusTooManyRequests
+		}
+		if w.Code != want {
+			t.Fatalf("request %d: status %d, want %d", i+1, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+// RealIP takes the client address from X-Forwarded-For on connections from
+// trusted proxies. The header is read right to left, skipping trusted
+// proxies, and the first other address is the client: entries further left
+// were supplied by the client and may be forged. True-Client-IP and X-Real-IP
+// are ignored, as is every forwarding header on other connections, so
+// clients cannot spoof their address to get past IP allowlists or rate
+// limits.
+func RealIP(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if addr, ok := forwardedClientIP(r, trustedProxies); ok {
+				r.RemoteAddr = addr.String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedClientIP returns the nearest untrusted address in
+// X-Forwarded-For, or the furthest one if every hop is trusted. A malformed
+// entry ends the walk, since nothing beyond it can be attributed.
+func forwardedClientIP(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	peer, err := netip.ParseAddr(ClientIP(r))
+	if err != nil || !isTrusted(peer.Unmap(), trustedProxies) {
+		return netip.Addr{}, false
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !isTrusted(client, trustedProxies) {
+			break
+		}
+	}
+	return client, client.IsValid()
+}
+
+// ParseTrustedProxies parses a comma-separated list of IP addresses and CIDR
+// ranges.
+func ParseTrustedProxies(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, err := entity.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+func isTrusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		peer    string
+		headers map[string][]string
+		want    string
+	}{
+		{
+			name: "untrusted peer ignores forwarding headers",
+			peer: "203.0.113.5:4000",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"198.51.100.1"},
+				"X-Real-Ip":       {"198.51.100.2"},
+				"True-Client-Ip":  {"198.51.100.3"},
+			},
+			want: "203.0.113.5",
+		},
+		{
+			name:    "trusted peer with single hop",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "spoofed leftmost entry is skipped",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.1"}},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "trusted hops are skipped right to left",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.1, 192.0.2.7, 10.1.1.1"}},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "repeated headers are read as one list",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4", "198.51.100.1"}},
+			want:    "198.51.100.1",
+		},
+		{
+			name: "True-Client-IP and X-Real-IP ignored from trusted peer",
+			peer: "10.0.0.1:4000",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"198.51.100.1"},
+				"X-Real-Ip":       {"1.2.3.4"},
+				"True-Client-Ip":  {"1.2.3.4"},
+			},
+			want: "198.51.100.1",
+		},
+		{
+			name:    "only X-Real-IP from trusted peer keeps peer",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Real-Ip": {"1.2.3.4"}},
+			want:    "10.0.0.1",
+		},
+		{
+			name:    "every hop trusted uses furthest",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"10.2.2.2, 10.1.1.1"}},
+			want:    "10.2.2.2",
+		},
+		{
+			name:    "malformed entry stops the walk",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, garbage, 10.1.1.1"}},
+			want:    "10.1.1.1",
+		},
+		{
+			name:    "malformed nearest entry keeps peer",
+			peer:    "10.0.0.1:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, garbage"}},
+			want:    "10.0.0.1",
+		},
+		{
+			name:    "IPv4-mapped IPv6 entries are unmapped",
+			peer:    "[::ffff:10.0.0.1]:4000",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, ::ffff:198.51.100.1"}},
+			want:    "198.51.100.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.peer
+			for name, values := range tt.headers {
+				for _, v := range values {
+					r.Header.Add(name, v)
+				}
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Fatalf("client IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIPWithoutTrustedProxies(t *testing.T) {
+	var got string
+	handler := RealIP(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ClientIP(r)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:4000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got != "10.0.0.1" {
+		t.Fatalf("client IP = %q, want peer address", got)
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+// RequireScope rejects requests whose access token lacks any of the given
+// scopes. It must run after AuthMiddleware.Authenticate.
+func RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			granted := GetScopes(r.Context())
+
+			for _, required := range scopes {
+				if !hasScope(granted, required) {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+						`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " "),
+					))
+					response.Error(w, http.StatusForbidden, "INSUFFICIENT_SCOPE",
+						fmt.Sprintf("Token is missing required scope: %s", required))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func hasScope(granted []string, scope string) bool {
+	for _, s := range granted {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireScope(t *testing.T) {
+	env := newBearerTestEnv(t)
+
+	// partner-active currently holds credit:read only.
+	readToken, _ := env.token(t, "partner-active", "credit:read", "")
+	withdrawnToken, _ := env.token(t, "partner-active", "credit:read credit:write", "")
+	noScopeToken, _ := env.token(t, "partner-active", "", "")
+
+	tests := []struct {
+		name     string
+		token    string
+		required []string
+		wantCode int
+	}{
+		{name: "granted scope", token: readToken, required: []string{"credit:read"}, wantCode: http.StatusOK},
+		{name: "no scope required", token: noScopeToken, wantCode: http.StatusOK},
+		{name: "token without scope", token: noScopeToken, required: []string{"credit:read"}, wantCode: http.StatusForbidden},
+		{name: "scope not in token", token: readToken, required: []string{"credit:write"}, wantCode: http.StatusForbidden},
+		{name: "scope withdrawn from partner", token: withdrawnToken, required: []string{"credit:write"}, wantCode: http.StatusForbidden},
+		{name: "one of several missing", token: withdrawnToken, required: []string{"credit:read", "credit:write"}, wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			h := env.middleware.Authenticate(RequireScope(tt.required...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})))
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/transactions", nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode == http.StatusOK {
+				if !called {
+					t.Fatal("handler not called")
+				}
+				return
+			}
+			if called {
+				t.Fatal("handler called without the required scope")
+			}
+			if got := errorCode(t, w); got != "INSUFFICIENT_SCOPE" {
+				t.Fatalf("error code %s, want INSUFFICIENT_SCOPE", got)
+			}
+			challenge := w.Header().Get("WWW-Authenticate")
+			if !strings.Contains(challenge, `error="insufficient_scope"`) || !strings.Contains(challenge, strings.Join(tt.required, " ")) {
+				t.Fatalf("WWW-Authenticate %q", challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/request"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+)
+
+// Headers carrying an HMAC request signature.
+const (
+	HeaderClientID  = "X-Client-Id"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+
+	DefaultSignatureClockSkew = 5 * time.Minute
+
+	minNonceLength = 16
+	maxNonceLength = 128
+)
+
+// SignatureMiddleware authenticates requests signed with a partner's request
+// signing key, as an alternative to bearer tokens. Requests without an
+// X-Signature header pass through untouched so that AuthMiddleware can
+// handle them; it must therefore run before AuthMiddleware.Authenticate.
+type SignatureMiddleware struct {
+	partnerRepo repository.PartnerRepository
+	nonceRepo   repository.NonceRepository
+	secrets     auth.SecretProvider
+	clockSkew   time.Duration
+}
+
+func NewSignatureMiddleware(
+	partnerRepo repository.PartnerRepository,
+	nonceRepo repository.NonceRepository,
+	secrets auth.SecretProvider,
+	clockSkew time.Duration,
+) *SignatureMiddleware {
+	return &SignatureMiddleware{
+		partnerRepo: partnerRepo,
+		nonceRepo:   nonceRepo,
+		secrets:     secrets,
+		clockSkew:   clockSkew,
+	}
+}
+
+func (m *SignatureMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature := r.Header.Get(HeaderSignature)
+		if signature == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		clientID := r.Header.Get(HeaderClientID)
+		timestamp := r.Header.Get(HeaderTimestamp)
+		nonce := r.Header.Get(HeaderNonce)
+		if clientID == "" || timestamp == "" || nonce == "" {
+			response.Error(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "X-Client-Id, X-Timestamp and X-Nonce are required with X-Signature")
+			return
+		}
+
+		if len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+			response.Error(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "X-Nonce must be between 16 and 128 characters")
+			return
+		}
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "X-Timestamp must be Unix seconds")
+			return
+		}
+
+		signedAt := time.Unix(unix, 0)
+		if skew := time.Since(signedAt); skew > m.clockSkew || skew < -m.clockSkew {
+			response.Error(w, http.StatusUnauthorized, "SIGNATURE_EXPIRED", "X-Timestamp is outside the allowed clock skew")
+			return
+		}
+
+		partner, err := m.partnerRepo.FindByClientID(r.Context(), clientID)
+		if err != nil {
+			response.Error(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Request signature is invalid")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, request.DefaultMaxBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				response.Error(w, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE", "Request body is too large")
+				return
+			}
+			response.Error(w, http.StatusBadRequest, "INVALID_REQUEST", "Request body could not be read")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		canonical := auth.CanonicalRequest(r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+
+		verified := false
+		for _, key := range partner.ActiveSigningKeys(time.Now()) {
+			secret, err := m.signingSecret(r.Context(), key)
+			if err != nil {
+				logging.FromContext(r.Context()).Error("request signing key unavailable",
+					"partner_id", partner.ID, "key_id", key.ID, "error", err)
+				continue
+			}
+			if auth.VerifyRequestSignature(secret, canonical, signature) {
+				verified = true
+				break
+			}
+		}
+		if !verified {
+			response.Error(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Request signature is invalid")
+			return
+		}
+
+		if err := partner.CheckActive(); err != nil {
+			WritePartnerStatusError(w, err)
+			return
+		}
+
+		if !partner.AcceptsAuthMode(entity.AuthModeHMAC) {
+			response.Error(w, http.StatusForbidden, "AUTH_MODE_NOT_ALLOWED", "Partner does not accept HMAC request signatures")
+			return
+		}
+
+		if thumbprint, subject := ClientCertificate(r); !partner.MatchesClientCertificate(thumbprint, subject) {
+			response.Error(w, http.StatusUnauthorized, "CERTIFICATE_MISMATCH", "Request must be made over the partner's client certificate")
+			return
+		}
+
+		// Nonces are only recorded once the signature is verified, so
+		// unauthenticated callers cannot burn a partner's nonces.
+		fresh, err := m.nonceRepo.Remember(r.Context(), partner.ClientID+":"+nonce, signedAt.Add(m.clockSkew))
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Signature could not be verified")
+			return
+		}
+		if !fresh {
+			response.Error(w, http.StatusUnauthorized, "NONCE_REUSED", "X-Nonce has already been used")
+			return
+		}
+
+		ctx := logging.WithPartnerID(r.Context(), partner.ID)
+		ctx = application.WithActor(ctx, application.PartnerActor(partner.ID), ClientIP(r))
+		ctx = context.WithValue(ctx, PartnerIDKey, partner.ID)
+		ctx = context.WithValue(ctx, ClientIDKey, partner.ClientID)
+		ctx = context.WithValue(ctx, ScopesKey, append([]string(nil), partner.Scopes...))
+		ctx = context.WithValue(ctx, AuthModeKey, entity.AuthModeHMAC)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// signingSecret returns the key's inline secret, or resolves it from the
+// secret store when the key holds a reference.
+func (m *SignatureMiddleware) signingSecret(ctx context.Context, key entity.RequestSigningKey) (string, error) {
+	if key.SecretRef == "" {
+		return key.Secret, nil
+	}
+	if m.secrets == nil {
+		return "", errors.New("no secret provider configured")
+	}
+	return m.secrets.Secret(ctx, key.SecretRef)
+}
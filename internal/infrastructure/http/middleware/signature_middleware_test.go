@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+type mapSecrets map[string]string
+
+func (s mapSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if value, ok := s[name]; ok {
+		return value, nil
+	}
+	return "", auth.ErrSecretNotFound
+}
+
+const (
+	inlineSecret = "ss_inline"
+	storedSecret = "ss_stored"
+	expiredKey   = "ss_expired"
+)
+
+// signedRequest describes one request to the signature middleware. Zero
+// fields are filled with values that produce a valid signature.
+type signedRequest struct {
+	clientID  string
+	secret    string
+	signedAt  time.Time
+	timestamp string
+	nonce     string
+	body      string
+	// sentBody and sentURI, when set, replace what was signed.
+	sentBody string
+	sentURI  string
+}
+
+func (s signedRequest) build() *http.Request {
+	if s.clientID == "" {
+		s.clientID = "hmac-client"
+	}
+	if s.secret == "" {
+		s.secret = inlineSecret
+	}
+	if s.signedAt.IsZero() {
+		s.signedAt = time.Now()
+	}
+	if s.timestamp == "" {
+		s.timestamp = strconv.FormatInt(s.signedAt.Unix(), 10)
+	}
+	if s.body == "" {
+		s.body = `{"amount":100}`
+	}
+	if s.sentBody == "" {
+		s.sentBody = s.body
+	}
+	const uri = "/v1/transactions?source=test"
+	if s.sentURI == "" {
+		s.sentURI = uri
+	}
+
+	canonical := auth.CanonicalRequest(http.MethodPost, uri, s.timestamp, s.nonce, []byte(s.body))
+	r := httptest.NewRequest(http.MethodPost, s.sentURI, strings.NewReader(s.sentBody))
+	r.Header.Set(HeaderClientID, s.clientID)
+	r.Header.Set(HeaderTimestamp, s.timestamp)
+	r.Header.Set(HeaderNonce, s.nonce)
+	r.Header.Set(HeaderSignature, auth.SignRequest(s.secret, canonical))
+	return r
+}
+
+func newTestSignatureMiddleware(t *testing.T) *SignatureMiddleware {
+	t.Helper()
+	ctx := context.Background()
+	partners, err := inmemory.NewInMemoryPartnerRepository(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := time.Now().Add(-time.Hour)
+	hmac := entity.NewPartner("partner-hmac", "HMAC Partner", "hmac-client", "wallet-1")
+	hmac.AuthModes = []entity.AuthMode{entity.AuthModeHMAC}
+	hmac.SigningKeys = []entity.RequestSigningKey{
+		{ID: "sk_expired", Secret: expiredKey, ExpiresAt: &expired},
+		{ID: "sk_inline", Secret: inlineSecret},
+		{ID: "sk_stored", SecretRef: "partners/hmac/signing_key"},
+	}
+
+	suspended := entity.NewPartner("partner-suspended", "Suspended Partner", "suspended-client", "wallet-2")
+	suspended.AuthModes = []entity.AuthMode{entity.AuthModeHMAC}
+	suspended.SigningKeys = []entity.RequestSigningKey{{ID: "sk_inline", Secret: inlineSecret}}
+	suspended.Status = entity.PartnerStatusSuspended
+
+	bearer := entity.NewPartner("partner-bearer", "Bearer Partner", "bearer-client", "wallet-3")
+	bearer.SigningKeys = []entity.RequestSigningKey{{ID: "sk_inline", Secret: inlineSecret}}
+
+	for _, p := range []*entity.Partner{hmac, suspended, bearer} {
+		if err := partners.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secrets := mapSecrets{"partners/hmac/signing_key": storedSecret}
+	return NewSignatureMiddleware(partners, inmemory.NewInMemoryNonceRepository(), secrets, DefaultSignatureClockSkew)
+}
+
+// echoPartner reports the authenticated partner and the body it received.
+var echoPartner = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	partnerID, _ := r.Context().Value(PartnerIDKey).(string)
+	fmt.Fprintf(w, "%s %s", partnerID, body)
+})
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body response.ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("status %d with undecodable body: %v", w.Code, err)
+	}
+	return body.Error.Code
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      signedRequest
+		wantCode int
+		wantErr  string
+	}{
+		{name: "inline key", req: signedRequest{}, wantCode: http.StatusOK},
+		{name: "key from secret store", req: signedRequest{secret: storedSecret}, wantCode: http.StatusOK},
+		{name: "expired key", req: signedRequest{secret: expiredKey}, wantCode: http.StatusUnauthorized, wantErr: "INVALID_SIGNATURE"},
+		{name: "wrong secret", req: signedRequest{secret: "ss_guess"}, wantCode: http.StatusUnauthorized, wantErr: "INVALID_SIGNATURE"},
+		{name: "unknown client", req: signedRequest{clientID: "nobody"}, wantCode: http.StatusUnauthorized, wantErr: "INVALID_SIGNATURE"},
+		{name: "body changed after signing", req: signedRequest{sentBody: `{"amount":900}`}, wantCode: http.StatusUnauthorized, wantErr: "INVALID_SIGNATURE"},
+		{name: "query changed after signing", req: signedRequest{sentURI: "/v1/transactions?source=other"}, wantCode: http.StatusUnauthorized, wantErr: "INVALID_SIGNATURE"},
+		{name: "short nonce", req: signedRequest{nonce: "short"}, wantCode: http.StatusUnauthorized, wantErr: "INVALID_SIGNATURE"},
+		{name: "timestamp not unix seconds", req: signedRequest{timestamp: time.Now().Format(time.RFC3339)}, wantCode: http.StatusUnauthorized, wantErr: "INVALID_SIGNATURE"},
+		{name: "stale timestamp", req: signedRequest{signedAt: time.Now().Add(-DefaultSignatureClockSkew - time.Minute)}, wantCode: http.StatusUnauthorized, wantErr: "SIGNATURE_EXPIRED"},
+		{name: "future timestamp", req: signedRequest{signedAt: time.Now().Add(DefaultSignatureClockSkew + time.Minute)}, wantCode: http.StatusUnauthorized, wantErr: "SIGNATURE_EXPIRED"},
+		{name: "suspended partner", req: signedRequest{clientID: "suspended-client"}, wantCode: http.StatusForbidden, wantErr: "PARTNER_SUSPENDED"},
+		{name: "partner without HMAC mode", req: signedRequest{clientID: "bearer-client"}, wantCode: http.StatusForbidden, wantErr: "AUTH_MODE_NOT_ALLOWED"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestSignatureMiddleware(t).Authenticate(echoPartner)
+			if tt.req.nonce == "" {
+				tt.req.nonce = fmt.Sprintf("nonce-%016d", i)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, tt.req.build())
+			if w.Code != tt.wantCode {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantErr != "" {
+				if got := errorCode(t, w); got != tt.wantErr {
+					t.Fatalf("error code %s, want %s", got, tt.wantErr)
+				}
+				return
+			}
+			if got, want := w.Body.String(), `partner-hmac {"amount":100}`; got != want {
+				t.Fatalf("handler saw %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSignatureMiddlewareNonces(t *testing.T) {
+	const nonce = "nonce-replayed-0001"
+
+	t.Run("replay", func(t *testing.T) {
+		h := newTestSignatureMiddleware(t).Authenticate(echoPartner)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, signedRequest{nonce: nonce}.build())
+		if w.Code != http.StatusOK {
+			t.Fatalf("first request: status %d: %s", w.Code, w.Body)
+		}
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, signedRequest{nonce: nonce}.build())
+		if w.Code != http.StatusUnauthorized || errorCode(t, w) != "NONCE_REUSED" {
+			t.Fatalf("replayed request: status %d, want 401 NONCE_REUSED", w.Code)
+		}
+	})
+
+	t.Run("forged request does not burn the nonce", func(t *testing.T) {
+		h := newTestSignatureMiddleware(t).Authenticate(echoPartner)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, signedRequest{nonce: nonce, secret: "ss_guess"}.build())
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("forged request: status %d, want 401", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, signedRequest{nonce: nonce}.build())
+		if w.Code != http.StatusOK {
+			t.Fatalf("genuine request after forgery: status %d: %s", w.Code, w.Body)
+		}
+	})
+}
+
+func TestSignatureMiddlewarePassesUnsignedRequests(t *testing.T) {
+	h := newTestSignatureMiddleware(t).Authenticate(echoPartner)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/transactions", strings.NewReader(`{"amount":100}`))
+	r.Header.Set(HeaderClientID, "hmac-client")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got, want := w.Body.String(), ` {"amount":100}`; w.Code != http.StatusOK || got != want {
+		t.Fatalf("status %d, handler saw %q; want 200 and %q with no partner", w.Code, got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+// DefaultMaxBodyBytes caps request bodies when no explicit limit is given.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// Error describes why a request body was rejected and how to report it.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Fields     []response.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// DecodeJSON decodes a JSON body into dst using DefaultMaxBodyBytes and validates the result.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return DecodeJSONWithLimit(w, r, dst, DefaultMaxBodyBytes)
+}
+
+// DecodeJSONWithLimit requires an application/json body of at most maxBytes,
+// rejects unknown fields and trailing data, and runs Validate on dst.
+func DecodeJSONWithLimit(w http.ResponseWriter, r *http.Request, dst interface{}, maxBytes int64) error {
+	if err := requireJSON(r); err != nil {
+		return err
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return decodeError(err, maxBytes)
+	}
+
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return &Error{
+			StatusCode: http.StatusBadRequest,
+			Code:       "INVALID_REQUEST",
+			Message:    "Request body must contain a single JSON object",
+		}
+	}
+
+	return Validate(dst)
+}
+
+// WriteError renders err using the standard error envelope.
+func WriteError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrInvalidRule) {
+		response.Error(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Request could not be validated")
+		return
+	}
+
+	var reqErr *Error
+	if !errors.As(err, &reqErr) {
+		response.Error(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if len(reqErr.Fields) > 0 {
+		response.ErrorWithDetails(w, reqErr.StatusCode, reqErr.Code, reqErr.Message, reqErr.Fields)
+		return
+	}
+
+	response.Error(w, reqErr.StatusCode, reqErr.Code, reqErr.Message)
+}
+
+func requireJSON(r *http.Request) error {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return &Error{
+			StatusCode: http.StatusUnsupportedMediaType,
+			Code:       "UNSUPPORTED_MEDIA_TYPE",
+			Message:    "Content-Type must be application/json",
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "application/json" {
+		return &Error{
+			StatusCode: http.StatusUnsupportedMediaType,
+			Code:       "UNSUPPORTED_MEDIA_TYPE",
+			Message:    "Content-Type must be application/json",
+		}
+	}
+
+	return nil
+}
+
+func decodeError(err error, maxBytes int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &Error{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Code:       "REQUEST_TOO_LARGE",
+			Message:    fmt.Sprintf("Request body must not exceed %d bytes", maxBytes),
+		}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return &Error{
+			StatusCode: http.StatusBadRequest,
+			Code:       "INVALID_REQUEST",
+			Message:    "Request body contains malformed JSON",
+		}
+	case errors.As(err, &typeErr):
+		return &Error{
+			StatusCode: http.StatusBadRequest,
+			Code:       "INVALID_REQUEST",
+			Message:    "Request body contains an invalid value",
+			Fields: []response.FieldError{{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+			}},
+		}
+	case errors.Is(err, io.EOF):
+		return &Error{
+			StatusCode: http.StatusBadRequest,
+			Code:       "INVALID_REQUEST",
+			Message:    "Request body must not be empty",
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &Error{
+			StatusCode: http.StatusBadRequest,
+			Code:       "INVALID_REQUEST",
+			Message:    "Request body contains an unknown field",
+			Fields: []response.FieldError{{
+				Field:   field,
+				Rule:    "unknown",
+				Message: "is not a recognised field",
+			}},
+		}
+	default:
+		return &Error{
+			StatusCode: http.StatusBadRequest,
+			Code:       "INVALID_REQUEST",
+			Message:    "Invalid request body",
+		}
+	}
+}
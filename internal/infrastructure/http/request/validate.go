@@ -0,0 +1,332 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+// ErrInvalidRule is returned by Validate and CheckTags when a `validate` tag
+// names an unknown rule or has a malformed argument.
+var ErrInvalidRule = errors.New("request: invalid validation rule")
+
+// Validate checks the `validate` struct tags on v and returns an *Error listing
+// every failing field. Supported rules:
+//
+//	required        value must not be the zero value
+//	min=N / max=N   string/slice/map length, or numeric value, bounds
+//	len=N           exact string/slice/map length
+//	gt=N / gte=N    numeric lower bounds
+//	oneof=a b c     string must equal one of the listed values
+//
+// Field names are reported using their json tag. Nested structs are validated
+// with dotted field paths. Tags are parsed once per type; a tag that does not
+// parse makes Validate return an error wrapping ErrInvalidRule.
+func Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields, err := validateStruct(rv, "")
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	code := "MISSING_FIELDS"
+	message := "Required fields are missing"
+	for _, f := range fields {
+		if f.Rule != "required" {
+			code = "VALIDATION_FAILED"
+			message = "Request validation failed"
+			break
+		}
+	}
+
+	return &Error{
+		StatusCode: http.StatusBadRequest,
+		Code:       code,
+		Message:    message,
+		Fields:     fields,
+	}
+}
+
+// CheckTags parses the `validate` tags of v's type and of every struct type
+// nested in it, so malformed tags are found without a request.
+func CheckTags(v interface{}) error {
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil
+	}
+	return checkType(rt, map[reflect.Type]bool{})
+}
+
+func checkType(rt reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[rt] {
+		return nil
+	}
+	seen[rt] = true
+
+	if _, err := structRules(rt); err != nil {
+		return err
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		ft := rt.Field(i).Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft.PkgPath() != "time" {
+			if err := checkType(ft, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rule is one parsed entry of a validate tag.
+type rule struct {
+	key string
+	arg string
+	// n is the numeric argument of min, max, len, gt and gte.
+	n float64
+	// allowed lists the values accepted by oneof.
+	allowed []string
+}
+
+type parsedRules struct {
+	fields [][]rule
+	err    error
+}
+
+// rulesByType caches the parsed tags of each struct type validated so far.
+var rulesByType sync.Map
+
+// structRules returns the rules of each field of rt, indexed like its fields.
+func structRules(rt reflect.Type) ([][]rule, error) {
+	if cached, ok := rulesByType.Load(rt); ok {
+		parsed := cached.(parsedRules)
+		return parsed.fields, parsed.err
+	}
+
+	parsed := parsedRules{fields: make([][]rule, rt.NumField())}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		rules, err := parseTag(tag)
+		if err != nil {
+			parsed = parsedRules{err: fmt.Errorf("%w: %s.%s: %v", ErrInvalidRule, rt.Name(), sf.Name, err)}
+			break
+		}
+		parsed.fields[i] = rules
+	}
+
+	rulesByType.Store(rt, parsed)
+	return parsed.fields, parsed.err
+}
+
+func parseTag(tag string) ([]rule, error) {
+	var rules []rule
+	for _, entry := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		r := rule{key: key, arg: arg}
+
+		switch key {
+		case "required":
+			if arg != "" {
+				return nil, fmt.Errorf("required takes no argument")
+			}
+		case "min", "max", "len", "gt", "gte":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s needs a number, got %q", key, arg)
+			}
+			r.n = n
+		case "oneof":
+			r.allowed = strings.Fields(arg)
+			if len(r.allowed) == 0 {
+				return nil, fmt.Errorf("oneof needs at least one value")
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule %q", key)
+		}
+
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func validateStruct(rv reflect.Value, prefix string) ([]response.FieldError, error) {
+	rt := rv.Type()
+	rules, err := structRules(rt)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []response.FieldError
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fv := rv.Field(i)
+		if fe, ok := validateField(fv, name, rules[i]); !ok {
+			errs = append(errs, fe)
+			continue
+		}
+
+		nested := fv
+		if nested.Kind() == reflect.Ptr && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.Type().PkgPath() != "time" {
+			nestedErrs, err := validateStruct(nested, name)
+			if err != nil {
+				return nil, err
+			}
+			errs = append(errs, nestedErrs...)
+		}
+	}
+
+	return errs, nil
+}
+
+func validateField(fv reflect.Value, name string, rules []rule) (response.FieldError, bool) {
+	// A pointer counts as supplied when it is not nil; the remaining rules
+	// apply to the value it points to.
+	supplied := !isZero(fv)
+	if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+		fv = fv.Elem()
+	}
+
+	for _, r := range rules {
+		if r.key == "required" {
+			if !supplied {
+				return response.FieldError{Field: name, Rule: r.key, Message: "is required"}, false
+			}
+			continue
+		}
+
+		// Optional fields that were not supplied skip the remaining rules.
+		if !supplied {
+			return response.FieldError{}, true
+		}
+
+		if msg, ok := checkRule(fv, r); !ok {
+			return response.FieldError{Field: name, Rule: r.key, Message: msg}, false
+		}
+	}
+
+	return response.FieldError{}, true
+}
+
+func checkRule(fv reflect.Value, r rule) (string, bool) {
+	switch r.key {
+	case "min", "max", "len":
+		size, isLength := measure(fv)
+		unit := ""
+		if isLength {
+			unit = " characters"
+			if fv.Kind() != reflect.String {
+				unit = " items"
+			}
+		}
+		switch {
+		case r.key == "min" && size < r.n:
+			return fmt.Sprintf("must be at least %s%s", r.arg, unit), false
+		case r.key == "max" && size > r.n:
+			return fmt.Sprintf("must be at most %s%s", r.arg, unit), false
+		case r.key == "len" && size != r.n:
+			return fmt.Sprintf("must be exactly %s%s", r.arg, unit), false
+		}
+	case "gt", "gte":
+		value, _ := measure(fv)
+		if r.key == "gt" && value <= r.n {
+			return fmt.Sprintf("must be greater than %s", r.arg), false
+		}
+		if r.key == "gte" && value < r.n {
+			return fmt.Sprintf("must be greater than or equal to %s", r.arg), false
+		}
+	case "oneof":
+		value := fmt.Sprint(fv.Interface())
+		for _, a := range r.allowed {
+			if value == a {
+				return "", true
+			}
+		}
+		return fmt.Sprintf("must be one of: %s", strings.Join(r.allowed, ", ")), false
+	}
+
+	return "", true
+}
+
+// measure returns the length of strings, slices and maps, or the numeric value
+// of number kinds. The boolean reports whether the result is a length.
+func measure(fv reflect.Value) (float64, bool) {
+	switch fv.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(fv.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(fv.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), false
+	}
+	return 0, false
+}
+
+func isZero(fv reflect.Value) bool {
+	switch fv.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(fv.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return fv.Len() == 0
+	}
+	return fv.IsZero()
+}
+
+func fieldName(sf reflect.StructField) string {
+	tag := sf.Tag.Get("json")
+	if tag == "" {
+		return sf.Name
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
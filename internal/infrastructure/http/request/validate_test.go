@@ -0,0 +1,191 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/http/response"
+)
+
+type ruleSample struct {
+	Required string   `json:"required" validate:"required"`
+	Name     string   `json:"name" validate:"min=2,max=4"`
+	Code     string   `json:"code" validate:"len=3"`
+	Tags     []string `json:"tags" validate:"max=2"`
+	Amount   float64  `json:"amount" validate:"gt=0"`
+	Count    *int     `json:"count" validate:"gte=0"`
+	Mode     string   `json:"mode" validate:"oneof=fast slow"`
+	Nested   *nestedSample
+}
+
+type nestedSample struct {
+	Phone string `json:"phone" validate:"required,max=5"`
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestValidateRules(t *testing.T) {
+	valid := func() ruleSample { return ruleSample{Required: "x"} }
+
+	tests := []struct {
+		name      string
+		change    func(*ruleSample)
+		wantField string
+		wantRule  string
+	}{
+		{name: "valid", change: func(*ruleSample) {}},
+		{name: "required missing", change: func(s *ruleSample) { s.Required = "" }, wantField: "required", wantRule: "required"},
+		{name: "required blank", change: func(s *ruleSample) { s.Required = "  " }, wantField: "required", wantRule: "required"},
+		{name: "min", change: func(s *ruleSample) { s.Name = "a" }, wantField: "name", wantRule: "min"},
+		{name: "max", change: func(s *ruleSample) { s.Name = "abcde" }, wantField: "name", wantRule: "max"},
+		{name: "max counts characters", change: func(s *ruleSample) { s.Name = "äöüß" }},
+		{name: "len", change: func(s *ruleSample) { s.Code = "US" }, wantField: "code", wantRule: "len"},
+		{name: "max items", change: func(s *ruleSample) { s.Tags = []string{"a", "b", "c"} }, wantField: "tags", wantRule: "max"},
+		{name: "gt", change: func(s *ruleSample) { s.Amount = -1 }, wantField: "amount", wantRule: "gt"},
+		{name: "gt passes", change: func(s *ruleSample) { s.Amount = 0.01 }},
+		{name: "gte through pointer", change: func(s *ruleSample) { s.Count = intPtr(-1) }, wantField: "count", wantRule: "gte"},
+		{name: "gte pointer to zero", change: func(s *ruleSample) { s.Count = intPtr(0) }},
+		{name: "oneof", change: func(s *ruleSample) { s.Mode = "medium" }, wantField: "mode", wantRule: "oneof"},
+		{name: "oneof passes", change: func(s *ruleSample) { s.Mode = "slow" }},
+		{name: "nested", change: func(s *ruleSample) { s.Nested = &nestedSample{} }, wantField: "Nested.phone", wantRule: "required"},
+		{name: "nested max", change: func(s *ruleSample) { s.Nested = &nestedSample{Phone: "123456"} }, wantField: "Nested.phone", wantRule: "max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample := valid()
+			tt.change(&sample)
+
+			err := Validate(&sample)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+
+			var reqErr *Error
+			if !errors.As(err, &reqErr) {
+				t.Fatalf("error = %v, want *Error", err)
+			}
+			if len(reqErr.Fields) != 1 {
+				t.Fatalf("fields = %+v, want one", reqErr.Fields)
+			}
+			if got := reqErr.Fields[0]; got.Field != tt.wantField || got.Rule != tt.wantRule || got.Message == "" {
+				t.Fatalf("field error = %+v, want %s failing %s", got, tt.wantField, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestValidateErrorShape(t *testing.T) {
+	tests := []struct {
+		name       string
+		sample     ruleSample
+		wantCode   string
+		wantFields []response.FieldError
+	}{
+		{
+			name:     "only required fields missing",
+			sample:   ruleSample{},
+			wantCode: "MISSING_FIELDS",
+			wantFields: []response.FieldError{
+				{Field: "required", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:     "every failing field listed",
+			sample:   ruleSample{Code: "EURO", Amount: -5},
+			wantCode: "VALIDATION_FAILED",
+			wantFields: []response.FieldError{
+				{Field: "required", Rule: "required", Message: "is required"},
+				{Field: "code", Rule: "len", Message: "must be exactly 3 characters"},
+				{Field: "amount", Rule: "gt", Message: "must be greater than 0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.sample)
+
+			var reqErr *Error
+			if !errors.As(err, &reqErr) {
+				t.Fatalf("error = %v, want *Error", err)
+			}
+			if reqErr.StatusCode != http.StatusBadRequest || reqErr.Code != tt.wantCode {
+				t.Fatalf("status %d code %s, want 400 %s", reqErr.StatusCode, reqErr.Code, tt.wantCode)
+			}
+			if !reflect.DeepEqual(reqErr.Fields, tt.wantFields) {
+				t.Fatalf("fields = %+v, want %+v", reqErr.Fields, tt.wantFields)
+			}
+
+			w := httptest.NewRecorder()
+			WriteError(w, err)
+			var body response.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != http.StatusBadRequest || body.Error.Code != tt.wantCode || !reflect.DeepEqual(body.Error.Details, tt.wantFields) {
+				t.Fatalf("response %d %+v", w.Code, body.Error)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsBadTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample interface{}
+	}{
+		{name: "unknown rule", sample: &struct {
+			Email string `validate:"email"`
+		}{Email: "a@b.c"}},
+		{name: "non-numeric argument", sample: &struct {
+			Name string `validate:"max=ten"`
+		}{Name: "x"}},
+		{name: "empty oneof", sample: &struct {
+			Mode string `validate:"oneof="`
+		}{Mode: "x"}},
+		{name: "argument on required", sample: &struct {
+			Name string `validate:"required=yes"`
+		}{Name: "x"}},
+		{name: "bad tag on unset field", sample: &struct {
+			Name string `validate:"max=ten"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckTags(tt.sample); !errors.Is(err, ErrInvalidRule) {
+				t.Fatalf("CheckTags error = %v, want ErrInvalidRule", err)
+			}
+			err := Validate(tt.sample)
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Fatalf("Validate error = %v, want ErrInvalidRule", err)
+			}
+
+			w := httptest.NewRecorder()
+			WriteError(w, err)
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("status %d, want 500", w.Code)
+			}
+		})
+	}
+}
+
+func TestCheckTagsFindsNestedTypes(t *testing.T) {
+	type inner struct {
+		Name string `validate:"maximum=3"`
+	}
+	type outer struct {
+		Inner *inner
+	}
+	if err := CheckTags(outer{}); !errors.Is(err, ErrInvalidRule) {
+		t.Fatalf("CheckTags error = %v, want ErrInvalidRule", err)
+	}
+	if err := CheckTags(ruleSample{}); err != nil {
+		t.Fatalf("CheckTags: %v", err)
+	}
+}
@@ -5,15 +5,39 @@ import (
 	"net/http"
 )
 
+// TraceIDHeader is set by the tracing middleware before handlers run; error
+// bodies repeat its value.
+const TraceIDHeader = "X-Trace-Id"
+
 type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
 }
 
 type ErrorDetail struct {
-	Code    string `json:"code"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+	Limit   *LimitDetail `json:"limit,omitempty"`
+	// TraceID identifies the request's trace for support and debugging.
+	TraceID string `json:"traceId,omitempty"`
+}
+
+// FieldError describes a single field that failed request validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
 	Message string `json:"message"`
 }
 
+// LimitDetail describes the spending limit a request would exceed.
+type LimitDetail struct {
+	Type      string  `json:"type"`
+	Currency  string  `json:"currency"`
+	Limit     float64 `json:"limit"`
+	Used      float64 `json:"used"`
+	Remaining float64 `json:"remaining"`
+}
+
 func JSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -21,12 +45,44 @@ func JSON(w http.ResponseWriter, statusCode int, data interface{}) {
 }
 
 func Error(w http.ResponseWriter, statusCode int, code, message string) {
+	ErrorWithDetails(w, statusCode, code, message, nil)
+}
+
+func ErrorWithDetails(w http.ResponseWriter, statusCode int, code, message string, details []FieldError) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(ErrorResponse{
 		Error: ErrorDetail{
 			Code:    code,
 			Message: message,
+			Details: details,
+			TraceID: w.Header().Get(TraceIDHeader),
 		},
 	})
 }
+
+func LimitError(w http.ResponseWriter, statusCode int, code, message string, limit LimitDetail) {
+	JSON(w, statusCode, ErrorResponse{
+		Error: ErrorDetail{
+			Code:    code,
+			Message: message,
+			Limit:   &limit,
+			TraceID: w.Header().Get(TraceIDHeader),
+		},
+	})
+}
+
+// OAuthErrorResponse is the RFC 6749 section 5.2 error body used by the token endpoint.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func OAuthError(w http.ResponseWriter, statusCode int, code, description string) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	JSON(w, statusCode, OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
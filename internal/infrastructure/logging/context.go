@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type contextKey string
+
+const (
+	loggerKey contextKey = "logger"
+	fieldsKey contextKey = "logFields"
+)
+
+// fields holds correlation IDs that become known part way through a request,
+// such as the partner ID after authentication. It is shared by every context
+// derived from the request so the final request log line carries them too.
+type fields struct {
+	mu            sync.Mutex
+	partnerID     string
+	transactionID string
+}
+
+func (f *fields) get() (string, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.partnerID, f.transactionID
+}
+
+func fieldsFromContext(ctx context.Context) *fields {
+	f, _ := ctx.Value(fieldsKey).(*fields)
+	return f
+}
+
+// NewContext returns a context carrying logger and an empty set of
+// correlation fields. It is called once per request.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	ctx = context.WithValue(ctx, loggerKey, logger)
+	return context.WithValue(ctx, fieldsKey, &fields{})
+}
+
+// FromContext returns the logger stored in ctx, or the default logger, bound
+// to ctx so that records include its correlation fields.
+func FromContext(ctx context.Context) *slog.Logger {
+	logger, ok := ctx.Value(loggerKey).(*slog.Logger)
+	if !ok {
+		logger = slog.Default()
+	}
+	return slog.New(&boundHandler{next: logger.Handler(), ctx: ctx})
+}
+
+// WithPartnerID records the partner the current request acts for.
+func WithPartnerID(ctx context.Context, partnerID string) context.Context {
+	return withField(ctx, func(f *fields) { f.partnerID = partnerID })
+}
+
+// WithTransactionID records the transaction the current request or job
+// operates on.
+func WithTransactionID(ctx context.Context, transactionID string) context.Context {
+	return withField(ctx, func(f *fields) { f.transactionID = transactionID })
+}
+
+// withField updates the request's shared fields, or starts a new set when
+// ctx does not belong to a request.
+func withField(ctx context.Context, set func(*fields)) context.Context {
+	f := fieldsFromContext(ctx)
+	if f == nil {
+		f = &fields{}
+		ctx = context.WithValue(ctx, fieldsKey, f)
+	}
+	f.mu.Lock()
+	set(f)
+	f.mu.Unlock()
+	return ctx
+}
+
+// boundHandler passes its own context to the wrapped handler, so plain
+// Info/Warn/Error calls behave like their *Context variants.
+type boundHandler struct {
+	next slog.Handler
+	ctx  context.Context
+}
+
+func (h *boundHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.next.Enabled(h.ctx, level)
+}
+
+func (h *boundHandler) Handle(_ context.Context, record slog.Record) error {
+	return h.next.Handle(h.ctx, record)
+}
+
+func (h *boundHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &boundHandler{next: h.next.WithAttrs(attrs), ctx: h.ctx}
+}
+
+func (h *boundHandler) WithGroup(name string) slog.Handler {
+	return &boundHandler{next: h.next.WithGroup(name), ctx: h.ctx}
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/tracing"
+)
+
+// Format selects how log records are encoded.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatText Format = "text"
+)
+
+type Config struct {
+	Level  slog.Level
+	Format Format
+	Output io.Writer
+}
+
+// New builds a logger that redacts sensitive attributes and adds the request
+// ID, trace ID, partner ID and transaction ID found in the record's context.
+func New(config Config) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{
+		Level:       config.Level,
+		ReplaceAttr: redact,
+	}
+
+	var handler slog.Handler
+	switch config.Format {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(config.Output, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(config.Output, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", config.Format)
+	}
+	return slog.New(&contextHandler{next: handler}), nil
+}
+
+// ParseLevel accepts debug, info, warn or error; "" means info.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return level, nil
+}
+
+// contextHandler adds correlation attributes from the context to every
+// record before passing it on.
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestID := middleware.GetReqID(ctx); requestID != "" {
+			record.AddAttrs(slog.String("request_id", requestID))
+		}
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			record.AddAttrs(slog.String("trace_id", traceID))
+		}
+		if f := fieldsFromContext(ctx); f != nil {
+			partnerID, transactionID := f.get()
+			if partnerID != "" {
+				record.AddAttrs(slog.String("partner_id", partnerID))
+			}
+			if transactionID != "" {
+				record.AddAttrs(slog.String("transaction_id", transactionID))
+			}
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys, lower-cased with '-' and '_' removed,
+// whose values are never logged.
+var sensitiveKeys = map[string]bool{
+	"authorization":      true,
+	"proxyauthorization": true,
+	"cookie":             true,
+	"setcookie":          true,
+	"apikey":             true,
+	"adminkey":           true,
+	"xadminkey":          true,
+	"signature":          true,
+	"xsignature":         true,
+	"assertion":          true,
+	"privatekey":         true,
+}
+
+var (
+	credentialPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	phonePattern      = regexp.MustCompile(`\+\d[\d \-]{6,}\d`)
+)
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	return strings.NewReplacer("-", "", "_", "").Replace(key)
+}
+
+func isSensitiveKey(key string) bool {
+	k := normalizeKey(key)
+	return sensitiveKeys[k] ||
+		strings.HasSuffix(k, "secret") ||
+		strings.HasSuffix(k, "password") ||
+		strings.HasSuffix(k, "token")
+}
+
+func isPhoneKey(key string) bool {
+	k := normalizeKey(key)
+	return strings.Contains(k, "phone") || strings.Contains(k, "msisdn")
+}
+
+// redact is the handler's ReplaceAttr hook. Attributes named like secrets or
+// tokens are replaced, phone numbers are masked to their last four digits, and
+// credentials or international phone numbers embedded in free text are
+// scrubbed.
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	if isPhoneKey(a.Key) {
+		return slog.String(a.Key, MaskPhoneNumber(a.Value.String()))
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, scrub(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			return slog.String(a.Key, scrub(v.Error()))
+		case http.Header:
+			return slog.Any(a.Key, redactHeader(v))
+		}
+	}
+	return a
+}
+
+func scrub(s string) string {
+	s = credentialPattern.ReplaceAllString(s, redacted)
+	return phonePattern.ReplaceAllStringFunc(s, MaskPhoneNumber)
+}
+
+func redactHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		if isSensitiveKey(name) {
+			out[name] = []string{redacted}
+			continue
+		}
+		out[name] = values
+	}
+	return out
+}
+
+// MaskPhoneNumber keeps only the last four digits of a phone number.
+func MaskPhoneNumber(s string) string {
+	var digits []rune
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) <= 4 {
+		return strings.Repeat("*", len(digits))
+	}
+	return "***" + string(digits[len(digits)-4:])
+}
@@ -0,0 +1,135 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	const (
+		secret = "s3cr3t-value"
+		token  = "eyJhbGciOiJFUzI1NiJ9.eyJzdWIiOiJwMSJ9.c2ln"
+		phone  = "+62 812 3456 7890"
+	)
+
+	tests := []struct {
+		name  string
+		log   func(*slog.Logger)
+		leaks []string
+		want  []string
+	}{
+		{
+			name:  "secret attribute",
+			log:   func(l *slog.Logger) { l.Info("login", "client_secret", secret, "partner_id", "p1") },
+			leaks: []string{secret},
+			want:  []string{`"client_secret":"[REDACTED]"`, `"partner_id":"p1"`},
+		},
+		{
+			name:  "token attributes",
+			log:   func(l *slog.Logger) { l.Info("grant", "access_token", token, "refreshToken", "rt_abc123") },
+			leaks: []string{token, "rt_abc123"},
+		},
+		{
+			name:  "signature and admin key headers as attributes",
+			log:   func(l *slog.Logger) { l.Info("request", "X-Signature", "sig-value", "X-Admin-Key", "admin-value") },
+			leaks: []string{"sig-value", "admin-value"},
+		},
+		{
+			name: "header map",
+			log: func(l *slog.Logger) {
+				l.Info("request", "headers", http.Header{
+					"Authorization": {"Bearer " + token},
+					"Cookie":        {"session=abc"},
+					"Content-Type":  {"application/json"},
+				})
+			},
+			leaks: []string{token, "session=abc"},
+			want:  []string{"application/json"},
+		},
+		{
+			name:  "credential in error",
+			log:   func(l *slog.Logger) { l.Error("upstream failed", "error", errors.New("rejected header Bearer "+token)) },
+			leaks: []string{token},
+			want:  []string{"rejected header [REDACTED]"},
+		},
+		{
+			name:  "credential in message",
+			log:   func(l *slog.Logger) { l.Warn("bad Authorization: Basic dXNlcjpwYXNz") },
+			leaks: []string{"dXNlcjpwYXNz"},
+		},
+		{
+			name:  "nested group",
+			log:   func(l *slog.Logger) { l.Info("config", slog.Group("db", "password", secret, "host", "db.local")) },
+			leaks: []string{secret},
+			want:  []string{`"host":"db.local"`},
+		},
+		{
+			name:  "phone attribute",
+			log:   func(l *slog.Logger) { l.Info("purchase", "phone_number", phone) },
+			leaks: []string{"3456", "812"},
+			want:  []string{`"phone_number":"***7890"`},
+		},
+		{
+			name:  "phone in free text",
+			log:   func(l *slog.Logger) { l.Info("purchase", "detail", "topping up "+phone+" now") },
+			leaks: []string{"812 3456"},
+			want:  []string{"topping up ***7890 now"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(Config{Level: slog.LevelDebug, Format: FormatJSON, Output: &buf})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.log(logger)
+
+			out := buf.String()
+			for _, leak := range tt.leaks {
+				if strings.Contains(out, leak) {
+					t.Errorf("log contains %q: %s", leak, out)
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("log lacks %q: %s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactionTextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Config{Format: FormatText, Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.InfoContext(context.Background(), "login", "client_secret", "s3cr3t-value")
+
+	if out := buf.String(); strings.Contains(out, "s3cr3t-value") || !strings.Contains(out, "client_secret=[REDACTED]") {
+		t.Fatalf("text log not redacted: %s", out)
+	}
+}
+
+func TestMaskPhoneNumber(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "+6281234567890", want: "***7890"},
+		{in: "+1 (555) 010-2030", want: "***2030"},
+		{in: "1234", want: "****"},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := MaskPhoneNumber(tt.in); got != tt.want {
+			t.Errorf("MaskPhoneNumber(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
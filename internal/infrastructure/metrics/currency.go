@@ -0,0 +1,39 @@
+package metrics
+
+import "strings"
+
+// otherCurrency labels transactions in a currency outside isoCurrencies, so
+// that arbitrary client-supplied codes cannot create new series.
+const otherCurrency = "other"
+
+// isoCurrencies holds the active ISO 4217 currency codes, leaving out precious
+// metals, bond units and testing codes.
+var isoCurrencies = func() map[string]bool {
+	codes := strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND
+		BOB BOV BRL BSD BTN BWP BYN BZD CAD CDF CHE CHF CHW CLF CLP CNY COP COU
+		CRC CUC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS
+		GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY
+		KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA
+		MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD
+		OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK
+		SGD SHP SLE SLL SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD
+		TWD TZS UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV WST XAF XCD XCG
+		XOF XPF YER ZAR ZMW ZWG ZWL
+	`)
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	return set
+}()
+
+// currencyLabel returns the label value for currency: its upper-case ISO 4217
+// code, or otherCurrency.
+func currencyLabel(currency string) string {
+	code := strings.ToUpper(currency)
+	if isoCurrencies[code] {
+		return code
+	}
+	return otherCurrency
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+const namespace = "buycredit"
+
+// Metrics holds the service's Prometheus collectors on a dedicated registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	transactions        *prometheus.CounterVec
+	transactionAmount   *prometheus.CounterVec
+	recoveries          *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route pattern and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transactions_total",
+			Help:      "Transactions entering each status, by partner and ISO 4217 currency.",
+		}, []string{"partner", "status", "currency"}),
+		transactionAmount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_amount_total",
+			Help:      "Sum of transaction amounts entering each status, by partner and ISO 4217 currency.",
+		}, []string{"partner", "status", "currency"}),
+		recoveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_recoveries_total",
+			Help:      "Stuck transactions checked by the recovery sweep, by outcome.",
+		}, []string{"outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.transactions,
+		m.transactionAmount,
+		m.recoveries,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records request counts and latency. Routes are labelled by their
+// chi pattern (e.g. /v1/transactions/{transactionId}) to keep cardinality
+// bounded; requests that match no route are labelled "unmatched".
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := prometheus.Labels{
+			"method": r.Method,
+			"route":  route,
+			"status": strconv.Itoa(status),
+		}
+		m.httpRequests.With(labels).Inc()
+		m.httpRequestDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// TransactionStatusChanged counts a transaction entering its current status.
+// Currencies outside ISO 4217 are labelled "other".
+func (m *Metrics) TransactionStatusChanged(txn *entity.Transaction) {
+	labels := prometheus.Labels{
+		"partner":  txn.PartnerID,
+		"status":   string(txn.Status),
+		"currency": currencyLabel(txn.Currency),
+	}
+	m.transactions.With(labels).Inc()
+	m.transactionAmount.With(labels).Add(txn.Amount)
+}
+
+// TransactionRecovered counts a stuck transaction checked by the recovery
+// sweep: "completed", "failed", "deferred" or "escalated".
+func (m *Metrics) TransactionRecovered(outcome string) {
+	m.recoveries.WithLabelValues(outcome).Inc()
+}
+
+// RegisterQueueDepth exposes the length of a work queue, read at scrape time.
+// A length that cannot be read is reported as NaN, so that a failing store
+// is not mistaken for an empty queue.
+func (m *Metrics) RegisterQueueDepth(queue string, depth func(ctx context.Context) (int, error)) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "Number of items waiting in a work queue; NaN when it cannot be read.",
+		ConstLabels: prometheus.Labels{"queue": queue},
+	}, func() float64 {
+		n, err := depth(context.Background())
+		if err != nil {
+			slog.Warn("queue depth unavailable", "queue", queue, "error", err)
+			return math.NaN()
+		}
+		return float64(n)
+	}))
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+func TestCurrencyLabel(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     string
+	}{
+		{"USD", "USD"},
+		{"kes", "KES"},
+		{"Eur", "EUR"},
+		{"ABC", otherCurrency},
+		{"XAU", otherCurrency},
+		{"", otherCurrency},
+		{"US D", otherCurrency},
+	}
+	for _, tt := range tests {
+		if got := currencyLabel(tt.currency); got != tt.want {
+			t.Errorf("currencyLabel(%q) = %q, want %q", tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestTransactionStatusChangedBoundsCurrencies(t *testing.T) {
+	m := New()
+
+	for _, currency := range []string{"USD", "usd", "QQQ", "ZZZ", "AAA"} {
+		m.TransactionStatusChanged(entity.NewTransaction("txn_1", "partner_acme", "u1", currency, 10))
+	}
+
+	if n := testutil.CollectAndCount(m.transactions); n != 2 {
+		t.Fatalf("%d transaction series, want 2 (USD and other)", n)
+	}
+	if got := testutil.ToFloat64(m.transactions.WithLabelValues("partner_acme", string(entity.TransactionStatusPending), otherCurrency)); got != 3 {
+		t.Fatalf("other currency count = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(m.transactionAmount.WithLabelValues("partner_acme", string(entity.TransactionStatusPending), "USD")); got != 20 {
+		t.Fatalf("USD amount = %v, want 20", got)
+	}
+}
+
+func TestQueueDepthReportsUnreadableQueues(t *testing.T) {
+	m := New()
+	m.RegisterQueueDepth("review", func(context.Context) (int, error) { return 3, nil })
+	m.RegisterQueueDepth("escalated", func(context.Context) (int, error) { return 0, errors.New("store down") })
+
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	depths := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "buycredit_queue_depth" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			depths[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+	if depths["review"] != 3 {
+		t.Errorf("review depth = %v, want 3", depths["review"])
+	}
+	if !math.IsNaN(depths["escalated"]) {
+		t.Errorf("escalated depth = %v, want NaN while the store is down", depths["escalated"])
+	}
+}
@@ -0,0 +1,104 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/tracing"
+)
+
+const (
+	// maxResponseSize caps how much of a gateway response is read.
+	maxResponseSize = 64 << 10
+	// probeTransactionID is looked up by Check; no purchase has this ID.
+	probeTransactionID = "txn_readiness_probe"
+)
+
+// HTTPGateway asks the provisioning service for a purchase's outcome with
+// GET {baseURL}/v1/purchases/{transactionId}. The service answers with
+// {"status": "...", "reference": "...", "reason": "..."}, or 404 if it never
+// received the purchase.
+type HTTPGateway struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+type statusResponse struct {
+	Status    string `json:"status"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+}
+
+// NewHTTPGateway returns a gateway for the service at baseURL. The token, if
+// set, is sent as a bearer token.
+func NewHTTPGateway(baseURL, token string, timeout time.Duration) *HTTPGateway {
+	return &HTTPGateway{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client: &http.Client{
+			Transport: tracing.NewTransport(nil),
+			Timeout:   timeout,
+		},
+	}
+}
+
+// Check reports whether the gateway is reachable and accepts the token, by
+// looking up a purchase that does not exist.
+func (g *HTTPGateway) Check(ctx context.Context) error {
+	_, err := g.Status(ctx, &entity.Transaction{ID: probeTransactionID})
+	return err
+}
+
+func (g *HTTPGateway) Status(ctx context.Context, txn *entity.Transaction) (*application.ProvisioningResult, error) {
+	endpoint := g.baseURL + "/v1/purchases/" + url.PathEscape(txn.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("provisioning gateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &application.ProvisioningResult{Outcome: application.ProvisioningNotFound}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provisioning gateway: unexpected status %d", resp.StatusCode)
+	}
+
+	var body statusResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("provisioning gateway: invalid response: %w", err)
+	}
+
+	outcome := application.ProvisioningOutcome(body.Status)
+	switch outcome {
+	case application.ProvisioningDelivered,
+		application.ProvisioningFailed,
+		application.ProvisioningInProgress,
+		application.ProvisioningNotFound:
+	default:
+		return nil, fmt.Errorf("provisioning gateway: unknown status %q", body.Status)
+	}
+	return &application.ProvisioningResult{
+		Outcome:   outcome,
+		Reference: body.Reference,
+		Reason:    body.Reason,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package provisioning
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/application"
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+func TestHTTPGatewayStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantOutcome application.ProvisioningOutcome
+		wantErr     bool
+	}{
+		{"delivered", http.StatusOK, `{"status":"DELIVERED","reference":"ref_1"}`, application.ProvisioningDelivered, false},
+		{"failed", http.StatusOK, `{"status":"FAILED","reason":"invalid number"}`, application.ProvisioningFailed, false},
+		{"in progress", http.StatusOK, `{"status":"IN_PROGRESS"}`, application.ProvisioningInProgress, false},
+		{"never received", http.StatusNotFound, ``, application.ProvisioningNotFound, false},
+		{"unknown status", http.StatusOK, `{"status":"MAYBE"}`, "", true},
+		{"malformed body", http.StatusOK, `{"status":`, "", true},
+		{"server error", http.StatusBadGateway, ``, "", true},
+		{"unauthorized", http.StatusUnauthorized, ``, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotAuth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			gateway := NewHTTPGateway(srv.URL+"/", "gw-token", time.Second)
+			result, err := gateway.Status(context.Background(), &entity.Transaction{ID: "txn_123"})
+
+			if gotPath != "/v1/purchases/txn_123" || gotAuth != "Bearer gw-token" {
+				t.Errorf("request to %q with %q, want /v1/purchases/txn_123 with the bearer token", gotPath, gotAuth)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && result.Outcome != tt.wantOutcome {
+				t.Fatalf("outcome %s, want %s", result.Outcome, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+func TestHTTPGatewayCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"probe not found", http.StatusNotFound, false},
+		{"token rejected", http.StatusUnauthorized, true},
+		{"unavailable", http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/purchases/"+probeTransactionID {
+					t.Errorf("probe requested %q", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := NewHTTPGateway(srv.URL, "", time.Second).Check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+		if err := NewHTTPGateway(srv.URL, "", time.Second).Check(context.Background()); err == nil {
+			t.Fatal("Check() succeeded against a closed server")
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+// CachedPartnerRepository caches FindByID lookups for a short TTL so that
+// per-request checks such as partner status do not hit storage every time.
+// FindByClientID is not cached because token issuance must see current
+// credentials, and FindByIDForUpdate is not cached so that writers start
+// from the stored version. Updates made through this repository invalidate
+// the entry immediately; updates made elsewhere become visible within the
+// TTL.
+type CachedPartnerRepository struct {
+	inner repository.PartnerRepository
+	ttl   time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]cachedPartner
+}
+
+type cachedPartner struct {
+	partner   *entity.Partner
+	expiresAt time.Time
+}
+
+func NewCachedPartnerRepository(inner repository.PartnerRepository, ttl time.Duration) *CachedPartnerRepository {
+	return &CachedPartnerRepository{
+		inner:   inner,
+		ttl:     ttl,
+		entries: make(map[string]cachedPartner),
+	}
+}
+
+func (r *CachedPartnerRepository) FindByClientID(ctx context.Context, clientID string) (*entity.Partner, error) {
+	return r.inner.FindByClientID(ctx, clientID)
+}
+
+func (r *CachedPartnerRepository) FindByID(ctx context.Context, id string) (*entity.Partner, error) {
+	now := time.Now()
+
+	r.mu.RLock()
+	entry, exists := r.entries[id]
+	r.mu.RUnlock()
+
+	if exists && now.Before(entry.expiresAt) {
+		return clonePartner(entry.partner), nil
+	}
+
+	partner, err := r.inner.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.entries[id] = cachedPartner{partner: clonePartner(partner), expiresAt: now.Add(r.ttl)}
+	r.mu.Unlock()
+
+	return partner, nil
+}
+
+func (r *CachedPartnerRepository) FindByIDForUpdate(ctx context.Context, id string) (*entity.Partner, error) {
+	return r.inner.FindByIDForUpdate(ctx, id)
+}
+
+func (r *CachedPartnerRepository) Create(ctx context.Context, partner *entity.Partner) error {
+	return r.inner.Create(ctx, partner)
+}
+
+// List is not cached; administrative listings should see current data.
+func (r *CachedPartnerRepository) List(ctx context.Context, filter repository.PartnerFilter) ([]*entity.Partner, error) {
+	return r.inner.List(ctx, filter)
+}
+
+func (r *CachedPartnerRepository) Update(ctx context.Context, partner *entity.Partner) error {
+	// A conflict means the cached copy may be stale too.
+	err := r.inner.Update(ctx, partner)
+	r.Invalidate(partner.ID)
+	return err
+}
+
+// Invalidate drops the cached entry for a partner.
+func (r *CachedPartnerRepository) Invalidate(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, id)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+func TestPartnerUpdateRejectsStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	inner, err := NewInMemoryPartnerRepository(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inner.Create(ctx, entity.NewPartner("partner_a", "A", "client_a", "wlt_a")); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCachedPartnerRepository(inner, time.Hour)
+
+	// Warm the cache, then change the partner behind its back.
+	cached, err := cache.FindByID(ctx, "partner_a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, _ := inner.FindByIDForUpdate(ctx, "partner_a")
+	if err := writer.Suspend(); err != nil {
+		t.Fatal(err)
+	}
+	if err := inner.Update(ctx, writer); err != nil {
+		t.Fatal(err)
+	}
+	if writer.Version != cached.Version+1 {
+		t.Fatalf("version after update = %d, want %d", writer.Version, cached.Version+1)
+	}
+
+	fresh, err := cache.FindByIDForUpdate(ctx, "partner_a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.Status != entity.PartnerStatusSuspended {
+		t.Fatalf("FindByIDForUpdate status = %s, want the stored SUSPENDED", fresh.Status)
+	}
+
+	cached.Name = "renamed"
+	if err := cache.Update(ctx, cached); !errors.Is(err, repository.ErrPartnerConflict) {
+		t.Fatalf("update from stale copy: err = %v, want ErrPartnerConflict", err)
+	}
+	stored, _ := cache.FindByID(ctx, "partner_a")
+	if stored.Status != entity.PartnerStatusSuspended || stored.Name != "A" {
+		t.Fatalf("after rejected update: status %s, name %q; want SUSPENDED, \"A\"", stored.Status, stored.Name)
+	}
+}
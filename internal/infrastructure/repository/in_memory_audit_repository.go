@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+type InMemoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []*entity.AuditEntry
+}
+
+func NewInMemoryAuditRepository() repository.AuditRepository {
+	return &InMemoryAuditRepository{}
+}
+
+func (r *InMemoryAuditRepository) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prevHash := ""
+	if n := len(r.entries); n > 0 {
+		prevHash = r.entries[n-1].Hash
+	}
+	entry.Seal(int64(len(r.entries)+1), prevHash)
+
+	r.entries = append(r.entries, entry.Clone())
+	return nil
+}
+
+func (r *InMemoryAuditRepository) FindByResource(ctx context.Context, resourceType, resourceID string) ([]*entity.AuditEntry, error) {
+	return r.Query(ctx, repository.AuditFilter{ResourceType: resourceType, ResourceID: resourceID})
+}
+
+func (r *InMemoryAuditRepository) Query(ctx context.Context, filter repository.AuditFilter) ([]*entity.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found := []*entity.AuditEntry{}
+	for _, entry := range r.entries {
+		if !matchesAuditFilter(entry, filter) {
+			continue
+		}
+		found = append(found, entry.Clone())
+		if filter.Limit > 0 && len(found) == filter.Limit {
+			break
+		}
+	}
+	return found, nil
+}
+
+func matchesAuditFilter(entry *entity.AuditEntry, filter repository.AuditFilter) bool {
+	switch {
+	case entry.Sequence <= filter.AfterSequence:
+		return false
+	case filter.Action != "" && entry.Action != filter.Action:
+		return false
+	case filter.Actor != "" && entry.Actor != filter.Actor:
+		return false
+	case filter.ResourceType != "" && entry.ResourceType != filter.ResourceType:
+		return false
+	case filter.ResourceID != "" && entry.ResourceID != filter.ResourceID:
+		return false
+	case !filter.Since.IsZero() && entry.Timestamp.Before(filter.Since):
+		return false
+	case !filter.Until.IsZero() && !entry.Timestamp.Before(filter.Until):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+// InMemoryLeaseRepository only coordinates within one process. Deployments
+// running several instances need a shared implementation.
+type InMemoryLeaseRepository struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+type lease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+func NewInMemoryLeaseRepository() repository.LeaseRepository {
+	return &InMemoryLeaseRepository{
+		leases: make(map[string]lease),
+	}
+}
+
+func (r *InMemoryLeaseRepository) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if current, held := r.leases[name]; held && current.owner != owner && now.Before(current.expiresAt) {
+		return false, nil
+	}
+
+	r.leases[name] = lease{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (r *InMemoryLeaseRepository) Release(ctx context.Context, name, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, held := r.leases[name]; held && current.owner == owner {
+		delete(r.leases, name)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+// loginAttemptPurgeInterval bounds how often stale entries are swept so that attempts
+// with random client IDs cannot grow the maps without limit.
+const loginAttemptPurgeInterval = time.Minute
+
+type InMemoryLoginAttemptRepository struct {
+	mu        sync.Mutex
+	failures  map[string][]time.Time
+	locks     map[string]time.Time
+	window    time.Duration
+	lastPurge time.Time
+}
+
+func NewInMemoryLoginAttemptRepository() repository.LoginAttemptRepository {
+	return &InMemoryLoginAttemptRepository{
+		failures: make(map[string][]time.Time),
+		locks:    make(map[string]time.Time),
+	}
+}
+
+func (r *InMemoryLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if window > r.window {
+		r.window = window
+	}
+	r.purge(now)
+
+	recent := trimBefore(r.failures[key], now.Add(-window))
+	recent = append(recent, now)
+	r.failures[key] = recent
+	return len(recent), nil
+}
+
+func (r *InMemoryLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.failures, key)
+	delete(r.locks, key)
+	return nil
+}
+
+func (r *InMemoryLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locks[key] = until
+	delete(r.failures, key)
+	return nil
+}
+
+func (r *InMemoryLoginAttemptRepository) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, exists := r.locks[key]
+	if !exists || time.Now().After(until) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+// purge drops expired lockouts and failures. Callers must hold the lock.
+func (r *InMemoryLoginAttemptRepository) purge(now time.Time) {
+	if now.Sub(r.lastPurge) < loginAttemptPurgeInterval {
+		return
+	}
+	r.lastPurge = now
+
+	for key, until := range r.locks {
+		if now.After(until) {
+			delete(r.locks, key)
+		}
+	}
+	for key, times := range r.failures {
+		if recent := trimBefore(times, now.Add(-r.window)); len(recent) == 0 {
+			delete(r.failures, key)
+		} else {
+			r.failures[key] = recent
+		}
+	}
+}
+
+func trimBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+type InMemoryNonceRepository struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastPurge time.Time
+}
+
+func NewInMemoryNonceRepository() repository.NonceRepository {
+	return &InMemoryNonceRepository{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+func (r *InMemoryNonceRepository) Remember(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastPurge) >= time.Minute {
+		r.lastPurge = now
+		for n, exp := range r.nonces {
+			if now.After(exp) {
+				delete(r.nonces, n)
+			}
+		}
+	}
+
+	if exp, exists := r.nonces[nonce]; exists && now.Before(exp) {
+		return false, nil
+	}
+
+	r.nonces[nonce] = expiresAt
+	return true, nil
+}
@@ -3,10 +3,14 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
 	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/auth"
 )
 
 type InMemoryPartnerRepository struct {
@@ -14,25 +18,65 @@ type InMemoryPartnerRepository struct {
 	partners map[string]*entity.Partner
 }
 
-func NewInMemoryPartnerRepository() repository.PartnerRepository {
+// Secret names holding the demo partner's credentials.
+const (
+	DemoClientSecretName  = "demo/client_secret"
+	DemoSigningSecretName = "demo/request_signing_key"
+)
+
+// NewInMemoryPartnerRepository builds an empty repository. With a non-nil
+// demoSecrets it is seeded with the demo partner documented in the README,
+// whose credentials are read from that provider.
+func NewInMemoryPartnerRepository(ctx context.Context, demoSecrets auth.SecretProvider) (repository.PartnerRepository, error) {
 	repo := &InMemoryPartnerRepository{
 		partners: make(map[string]*entity.Partner),
 	}
 
-	// Seed with sample partner data
-	repo.seedData()
-	return repo
+	if demoSecrets != nil {
+		if err := repo.seedData(ctx, demoSecrets); err != nil {
+			return nil, fmt.Errorf("seed demo partner: %w", err)
+		}
+	}
+	return repo, nil
 }
 
-func (r *InMemoryPartnerRepository) seedData() {
+func (r *InMemoryPartnerRepository) seedData(ctx context.Context, secrets auth.SecretProvider) error {
+	clientSecret, err := secrets.Secret(ctx, DemoClientSecretName)
+	if err != nil {
+		return err
+	}
+	// The signing key is only checked here; requests resolve it by
+	// reference so it can be rotated in the secret store.
+	if _, err := secrets.Secret(ctx, DemoSigningSecretName); err != nil {
+		return err
+	}
+
 	partner := entity.NewPartner(
 		"partner_bella",
 		"Bella Mobile",
 		"bella_mobile_prod",
-		"secret_bella_123",
 		"wlt_partner_bella",
 	)
+	partner.Scopes = append([]string(nil), entity.AllScopes...)
+	partner.AuthModes = []entity.AuthMode{entity.AuthModeBearer, entity.AuthModeHMAC}
+	partner.SigningKeys = []entity.RequestSigningKey{{
+		ID:        "sig_bella_seed",
+		SecretRef: DemoSigningSecretName,
+		CreatedAt: time.Now(),
+	}}
+
+	hash, err := auth.HashSecret(clientSecret)
+	if err != nil {
+		return err
+	}
+	partner.AddClientSecret(entity.ClientSecret{
+		ID:        "sec_bella_seed",
+		Hash:      hash,
+		CreatedAt: time.Now(),
+	})
+
 	r.partners[partner.ID] = partner
+	return nil
 }
 
 func (r *InMemoryPartnerRepository) FindByClientID(ctx context.Context, clientID string) (*entity.Partner, error) {
@@ -41,7 +85,7 @@ func (r *InMemoryPartnerRepository) FindByClientID(ctx context.Context, clientID
 
 	for _, partner := range r.partners {
 		if partner.ClientID == clientID {
-			return partner, nil
+			return clonePartner(partner), nil
 		}
 	}
 
@@ -57,5 +101,94 @@ func (r *InMemoryPartnerRepository) FindByID(ctx context.Context, id string) (*e
 		return nil, errors.New("partner not found")
 	}
 
-	return partner, nil
+	return clonePartner(partner), nil
+}
+
+func (r *InMemoryPartnerRepository) FindByIDForUpdate(ctx context.Context, id string) (*entity.Partner, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *InMemoryPartnerRepository) Create(ctx context.Context, partner *entity.Partner) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.partners[partner.ID]; exists {
+		return repository.ErrPartnerExists
+	}
+	for _, existing := range r.partners {
+		if existing.ClientID == partner.ClientID {
+			return repository.ErrPartnerExists
+		}
+	}
+
+	r.partners[partner.ID] = clonePartner(partner)
+	return nil
+}
+
+func (r *InMemoryPartnerRepository) Update(ctx context.Context, partner *entity.Partner) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.partners[partner.ID]
+	if !exists {
+		return errors.New("partner not found")
+	}
+	if stored.Version != partner.Version {
+		return repository.ErrPartnerConflict
+	}
+
+	partner.Version++
+	r.partners[partner.ID] = clonePartner(partner)
+	return nil
+}
+
+func (r *InMemoryPartnerRepository) List(ctx context.Context, filter repository.PartnerFilter) ([]*entity.Partner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var partners []*entity.Partner
+	for _, partner := range r.partners {
+		if filter.Status != "" && partner.Status != filter.Status {
+			continue
+		}
+		if filter.AfterID != "" && partner.ID <= filter.AfterID {
+			continue
+		}
+		partners = append(partners, partner)
+	}
+
+	sort.Slice(partners, func(i, j int) bool {
+		return partners[i].ID < partners[j].ID
+	})
+	if filter.Limit > 0 && len(partners) > filter.Limit {
+		partners = partners[:filter.Limit]
+	}
+
+	result := make([]*entity.Partner, len(partners))
+	for i, partner := range partners {
+		result[i] = clonePartner(partner)
+	}
+	return result, nil
+}
+
+// clonePartner copies a partner so callers can modify it without racing
+// readers; changes only become visible through Update.
+func clonePartner(p *entity.Partner) *entity.Partner {
+	c := *p
+	c.ClientSecrets = append([]entity.ClientSecret(nil), p.ClientSecrets...)
+	c.Scopes = append([]string(nil), p.Scopes...)
+	c.AuthModes = append([]entity.AuthMode(nil), p.AuthModes...)
+	c.SigningKeys = append([]entity.RequestSigningKey(nil), p.SigningKeys...)
+	c.ClientCertThumbprints = append([]string(nil), p.ClientCertThumbprints...)
+	c.ClientCertSubjects = append([]string(nil), p.ClientCertSubjects...)
+	c.AllowedCIDRs = append([]string(nil), p.AllowedCIDRs...)
+	c.SpendingLimits = append([]entity.SpendingLimit(nil), p.SpendingLimits...)
+	c.AllowedTransactionTypes = append([]entity.TransactionType(nil), p.AllowedTransactionTypes...)
+	if p.RateLimits != nil {
+		c.RateLimits = make(map[entity.RateLimitGroup]int, len(p.RateLimits))
+		for group, limit := range p.RateLimits {
+			c.RateLimits[group] = limit
+		}
+	}
+	return &c
 }
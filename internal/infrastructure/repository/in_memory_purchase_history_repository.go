@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+const (
+	// purchaseHistoryRetention bounds how far back velocity rules can look.
+	purchaseHistoryRetention = 7 * 24 * time.Hour
+	// walletRetention is how long a wallet is remembered after its last
+	// purchase; a wallet inactive for longer counts as new again.
+	walletRetention = 30 * 24 * time.Hour
+)
+
+type walletSighting struct {
+	firstSeen     time.Time
+	lastSeen      time.Time
+	transactionID string
+}
+
+type InMemoryPurchaseHistoryRepository struct {
+	mu          sync.Mutex
+	records     []repository.PurchaseRecord
+	walletsSeen map[string]*walletSighting
+	lastPurge   time.Time
+}
+
+func NewInMemoryPurchaseHistoryRepository() repository.PurchaseHistoryRepository {
+	return &InMemoryPurchaseHistoryRepository{
+		walletsSeen: make(map[string]*walletSighting),
+	}
+}
+
+func (r *InMemoryPurchaseHistoryRepository) Record(ctx context.Context, record repository.PurchaseRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastPurge) >= time.Minute {
+		r.purge(now)
+	}
+
+	r.records = append(r.records, record)
+	if record.WalletID != "" {
+		seen, ok := r.walletsSeen[record.WalletID]
+		if !ok {
+			r.walletsSeen[record.WalletID] = &walletSighting{
+				firstSeen:     record.At,
+				lastSeen:      record.At,
+				transactionID: record.TransactionID,
+			}
+		} else if record.At.After(seen.lastSeen) {
+			seen.lastSeen = record.At
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryPurchaseHistoryRepository) Remove(ctx context.Context, transactionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Removals follow shortly after the record, so search from the newest.
+	for i := len(r.records) - 1; i >= 0; i-- {
+		rec := r.records[i]
+		if rec.TransactionID != transactionID {
+			continue
+		}
+		r.records = append(r.records[:i], r.records[i+1:]...)
+
+		// A wallet first seen in the removed purchase has not been seen after
+		// all, unless another purchase from it has been recorded since.
+		if seen, ok := r.walletsSeen[rec.WalletID]; ok && seen.transactionID == transactionID {
+			delete(r.walletsSeen, rec.WalletID)
+			for _, other := range r.records[i:] {
+				if other.WalletID == rec.WalletID {
+					r.walletsSeen[rec.WalletID] = &walletSighting{
+						firstSeen:     other.At,
+						lastSeen:      seen.lastSeen,
+						transactionID: other.TransactionID,
+					}
+					break
+				}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func (r *InMemoryPurchaseHistoryRepository) FindByPhoneNumber(ctx context.Context, phoneNumber string, since time.Time) ([]repository.PurchaseRecord, error) {
+	return r.find(since, func(rec repository.PurchaseRecord) bool {
+		return rec.PhoneNumber == phoneNumber
+	}), nil
+}
+
+func (r *InMemoryPurchaseHistoryRepository) FindByUser(ctx context.Context, partnerID, userID string, since time.Time) ([]repository.PurchaseRecord, error) {
+	return r.find(since, func(rec repository.PurchaseRecord) bool {
+		return rec.PartnerID == partnerID && rec.UserID == userID
+	}), nil
+}
+
+func (r *InMemoryPurchaseHistoryRepository) WalletFirstSeen(ctx context.Context, walletID string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if seen, ok := r.walletsSeen[walletID]; ok && time.Since(seen.lastSeen) < walletRetention {
+		return seen.firstSeen, nil
+	}
+	return time.Time{}, nil
+}
+
+func (r *InMemoryPurchaseHistoryRepository) find(since time.Time, match func(repository.PurchaseRecord) bool) []repository.PurchaseRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found []repository.PurchaseRecord
+	for i := len(r.records) - 1; i >= 0 && !r.records[i].At.Before(since); i-- {
+		if match(r.records[i]) {
+			found = append(found, r.records[i])
+		}
+	}
+	return found
+}
+
+// purge drops purchases past retention and wallets inactive for longer than
+// walletRetention. Callers must hold the lock.
+func (r *InMemoryPurchaseHistoryRepository) purge(now time.Time) {
+	r.lastPurge = now
+
+	cutoff := now.Add(-purchaseHistoryRetention)
+	i := 0
+	for i < len(r.records) && r.records[i].At.Before(cutoff) {
+		i++
+	}
+	r.records = append([]repository.PurchaseRecord(nil), r.records[i:]...)
+
+	walletCutoff := now.Add(-walletRetention)
+	for id, seen := range r.walletsSeen {
+		if seen.lastSeen.Before(walletCutoff) {
+			delete(r.walletsSeen, id)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+func TestPurchaseHistoryFindByUserIsPartnerScoped(t *testing.T) {
+	ctx := context.Background()
+	history := NewInMemoryPurchaseHistoryRepository()
+	now := time.Now()
+
+	for _, rec := range []repository.PurchaseRecord{
+		{TransactionID: "txn_1", PartnerID: "partner_a", UserID: "u1", At: now},
+		{TransactionID: "txn_2", PartnerID: "partner_b", UserID: "u1", At: now},
+		{TransactionID: "txn_3", PartnerID: "partner_a", UserID: "u2", At: now},
+	} {
+		if err := history.Record(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := history.FindByUser(ctx, "partner_a", "u1", now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].TransactionID != "txn_1" {
+		t.Fatalf("FindByUser = %+v, want only txn_1", found)
+	}
+}
+
+func TestPurchaseHistoryRemoveRestoresWalletFirstSeen(t *testing.T) {
+	first := time.Now().Add(-time.Minute)
+	second := first.Add(time.Second)
+
+	tests := []struct {
+		name    string
+		records []repository.PurchaseRecord
+		remove  string
+		want    time.Time
+	}{
+		{
+			name: "later purchase",
+			records: []repository.PurchaseRecord{
+				{TransactionID: "txn_1", WalletID: "w1", At: first},
+				{TransactionID: "txn_2", WalletID: "w1", At: second},
+			},
+			remove: "txn_2",
+			want:   first,
+		},
+		{
+			name: "first purchase",
+			records: []repository.PurchaseRecord{
+				{TransactionID: "txn_1", WalletID: "w1", At: first},
+				{TransactionID: "txn_2", WalletID: "w1", At: second},
+			},
+			remove: "txn_1",
+			want:   second,
+		},
+		{
+			name: "only purchase",
+			records: []repository.PurchaseRecord{
+				{TransactionID: "txn_1", WalletID: "w1", At: first},
+			},
+			remove: "txn_1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			history := NewInMemoryPurchaseHistoryRepository()
+			for _, rec := range tt.records {
+				if err := history.Record(ctx, rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := history.Remove(ctx, tt.remove); err != nil {
+				t.Fatal(err)
+			}
+			got, err := history.WalletFirstSeen(ctx, "w1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("WalletFirstSeen = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurchaseHistoryForgetsInactiveWallets(t *testing.T) {
+	ctx := context.Background()
+	history := NewInMemoryPurchaseHistoryRepository()
+
+	stale := time.Now().Add(-walletRetention - time.Hour)
+	if err := history.Record(ctx, repository.PurchaseRecord{TransactionID: "txn_1", WalletID: "w1", At: stale}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := history.WalletFirstSeen(ctx, "w1"); !got.IsZero() {
+		t.Fatalf("inactive wallet first seen at %v, want zero", got)
+	}
+
+	// The next purge drops the wallet altogether.
+	r := history.(*InMemoryPurchaseHistoryRepository)
+	r.mu.Lock()
+	r.purge(time.Now())
+	n := len(r.walletsSeen)
+	r.mu.Unlock()
+	if n != 0 {
+		t.Fatalf("%d wallets remembered after purge, want 0", n)
+	}
+}
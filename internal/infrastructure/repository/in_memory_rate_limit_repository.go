@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+type rateLimitWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+type InMemoryRateLimitRepository struct {
+	mu        sync.Mutex
+	windows   map[string]*rateLimitWindow
+	maxKeys   int
+	lastPurge time.Time
+}
+
+func NewInMemoryRateLimitRepository() repository.RateLimitRepository {
+	return &InMemoryRateLimitRepository{
+		windows: make(map[string]*rateLimitWindow),
+	}
+}
+
+// NewBoundedRateLimitRepository builds an in-memory store that tracks at
+// most maxKeys keys, returning repository.ErrRateLimitCapacity for new keys
+// once full. It suits a local fallback whose memory must stay bounded.
+func NewBoundedRateLimitRepository(maxKeys int) repository.RateLimitRepository {
+	return &InMemoryRateLimitRepository{
+		windows: make(map[string]*rateLimitWindow),
+		maxKeys: maxKeys,
+	}
+}
+
+func (r *InMemoryRateLimitRepository) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	// A full store purges more often, but not on every request, so keeping
+	// it full does not make each request scan every key.
+	sincePurge := now.Sub(r.lastPurge)
+	full := r.maxKeys > 0 && len(r.windows) >= r.maxKeys
+	if sincePurge >= time.Minute || (full && sincePurge >= time.Second) {
+		r.purge(now)
+	}
+
+	w, exists := r.windows[key]
+	if !exists && r.maxKeys > 0 && len(r.windows) >= r.maxKeys {
+		return 0, time.Time{}, repository.ErrRateLimitCapacity
+	}
+	if !exists || !now.Before(w.resetAt) {
+		w = &rateLimitWindow{resetAt: now.Add(window)}
+		r.windows[key] = w
+	}
+	w.count++
+	return w.count, w.resetAt, nil
+}
+
+// purge drops windows that have ended. Callers must hold the lock.
+func (r *InMemoryRateLimitRepository) purge(now time.Time) {
+	r.lastPurge = now
+	for k, w := range r.windows {
+		if !now.Before(w.resetAt) {
+			delete(r.windows, k)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+func TestBoundedRateLimitRepository(t *testing.T) {
+	ctx := context.Background()
+	store := NewBoundedRateLimitRepository(2)
+
+	for _, key := range []string{"a", "b", "a"} {
+		if _, _, err := store.Increment(ctx, key, time.Minute); err != nil {
+			t.Fatalf("Increment(%q): %v", key, err)
+		}
+	}
+	if _, _, err := store.Increment(ctx, "c", time.Minute); !errors.Is(err, repository.ErrRateLimitCapacity) {
+		t.Fatalf("new key when full: err = %v, want ErrRateLimitCapacity", err)
+	}
+	count, _, err := store.Increment(ctx, "a", time.Minute)
+	if err != nil || count != 3 {
+		t.Fatalf("existing key when full: count = %d, err = %v; want 3, nil", count, err)
+	}
+}
+
+func TestBoundedRateLimitRepositoryReclaimsEndedWindows(t *testing.T) {
+	ctx := context.Background()
+	store := NewBoundedRateLimitRepository(1)
+
+	if _, _, err := store.Increment(ctx, "a", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if _, _, err := store.Increment(ctx, "b", time.Minute); err != nil {
+		t.Fatalf("new key after window ended: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+type InMemoryRefreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]*entity.RefreshToken // id -> token
+	hashes map[string]string               // tokenHash -> id
+}
+
+func NewInMemoryRefreshTokenRepository() repository.RefreshTokenRepository {
+	return &InMemoryRefreshTokenRepository{
+		tokens: make(map[string]*entity.RefreshToken),
+		hashes: make(map[string]string),
+	}
+}
+
+func (r *InMemoryRefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.ID]; exists {
+		return errors.New("refresh token already exists")
+	}
+
+	r.purgeExpired(time.Now())
+
+	stored := *token
+	r.tokens[token.ID] = &stored
+	r.hashes[token.TokenHash] = token.ID
+	return nil
+}
+
+func (r *InMemoryRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.hashes[tokenHash]
+	if !exists {
+		return nil, errors.New("refresh token not found")
+	}
+
+	token := *r.tokens[id]
+	return &token, nil
+}
+
+func (r *InMemoryRefreshTokenRepository) FindByFamily(ctx context.Context, familyID string) ([]*entity.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var family []*entity.RefreshToken
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID {
+			t := *token
+			family = append(family, &t)
+		}
+	}
+
+	return family, nil
+}
+
+func (r *InMemoryRefreshTokenRepository) Update(ctx context.Context, token *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.ID]; !exists {
+		return errors.New("refresh token not found")
+	}
+
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *InMemoryRefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.tokens[id]
+	if !exists {
+		return errors.New("refresh token not found")
+	}
+
+	if token.UsedAt != nil {
+		return repository.ErrRefreshTokenUsed
+	}
+
+	token.UsedAt = &usedAt
+	return nil
+}
+
+// purgeExpired drops tokens past their expiry. Callers must hold the write lock.
+func (r *InMemoryRefreshTokenRepository) purgeExpired(now time.Time) {
+	for id, token := range r.tokens {
+		if now.After(token.ExpiresAt) {
+			delete(r.hashes, token.TokenHash)
+			delete(r.tokens, id)
+		}
+	}
+}
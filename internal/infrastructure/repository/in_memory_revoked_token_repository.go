@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+type InMemoryRevokedTokenRepository struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time // tokenID -> token expiry
+}
+
+func NewInMemoryRevokedTokenRepository() repository.RevokedTokenRepository {
+	return &InMemoryRevokedTokenRepository{
+		revoked: make(map[string]time.Time),
+	}
+}
+
+func (r *InMemoryRevokedTokenRepository) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range r.revoked {
+		if now.After(exp) {
+			delete(r.revoked, id)
+		}
+	}
+
+	if expiresAt.After(now) {
+		r.revoked[tokenID] = expiresAt
+	}
+	return nil
+}
+
+func (r *InMemoryRevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	expiresAt, exists := r.revoked[tokenID]
+	if !exists {
+		return false, nil
+	}
+
+	// Once the token itself has expired the entry no longer matters.
+	return time.Now().Before(expiresAt), nil
+}
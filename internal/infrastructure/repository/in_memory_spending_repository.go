@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+// spendingTolerance absorbs floating-point error when a purchase brings a
+// total exactly to its limit.
+const spendingTolerance = 1e-9
+
+type spendingTotal struct {
+	amount    float64
+	expiresAt time.Time
+}
+
+type InMemorySpendingRepository struct {
+	mu        sync.Mutex
+	totals    map[string]*spendingTotal
+	lastPurge time.Time
+}
+
+func NewInMemorySpendingRepository() repository.SpendingRepository {
+	return &InMemorySpendingRepository{
+		totals: make(map[string]*spendingTotal),
+	}
+}
+
+func (r *InMemorySpendingRepository) Reserve(ctx context.Context, counters []repository.SpendingCounter, amount float64) ([]float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.purge(now)
+
+	used := make([]float64, len(counters))
+	exceeded := false
+	for i, c := range counters {
+		used[i] = r.current(c.Key, now)
+		if c.Limit > 0 && used[i]+amount > c.Limit+spendingTolerance {
+			exceeded = true
+		}
+	}
+	if exceeded {
+		return used, repository.ErrSpendingLimitReached
+	}
+
+	for i, c := range counters {
+		t, exists := r.totals[c.Key]
+		if !exists || now.After(t.expiresAt) {
+			t = &spendingTotal{}
+			r.totals[c.Key] = t
+		}
+		t.amount += amount
+		if c.ExpiresAt.After(t.expiresAt) {
+			t.expiresAt = c.ExpiresAt
+		}
+		used[i] = t.amount
+	}
+	return used, nil
+}
+
+func (r *InMemorySpendingRepository) Release(ctx context.Context, keys []string, amount float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range keys {
+		if t, exists := r.totals[key]; exists {
+			t.amount -= amount
+			if t.amount < 0 {
+				t.amount = 0
+			}
+		}
+	}
+	return nil
+}
+
+func (r *InMemorySpendingRepository) Usage(ctx context.Context, keys []string) ([]float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	used := make([]float64, len(keys))
+	for i, key := range keys {
+		used[i] = r.current(key, now)
+	}
+	return used, nil
+}
+
+// current returns the live total for key. Callers must hold the lock.
+func (r *InMemorySpendingRepository) current(key string, now time.Time) float64 {
+	t, exists := r.totals[key]
+	if !exists || now.After(t.expiresAt) {
+		return 0
+	}
+	return t.amount
+}
+
+// purge drops expired counters. Callers must hold the lock.
+func (r *InMemorySpendingRepository) purge(now time.Time) {
+	if now.Sub(r.lastPurge) < time.Minute {
+		return
+	}
+	r.lastPurge = now
+
+	for key, t := range r.totals {
+		if now.After(t.expiresAt) {
+			delete(r.totals, key)
+		}
+	}
+}
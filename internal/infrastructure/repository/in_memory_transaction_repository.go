@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 
 	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
@@ -30,7 +31,7 @@ func (r *InMemoryTransactionRepository) Create(ctx context.Context, transaction
 		return errors.New("transaction already exists")
 	}
 
-	r.transactions[transaction.ID] = transaction
+	r.transactions[transaction.ID] = cloneTransaction(transaction)
 	return nil
 }
 
@@ -43,7 +44,24 @@ func (r *InMemoryTransactionRepository) FindByID(ctx context.Context, id string)
 		return nil, errors.New("transaction not found")
 	}
 
-	return transaction, nil
+	return cloneTransaction(transaction), nil
+}
+
+// FindByStatus returns matching transactions, oldest first.
+func (r *InMemoryTransactionRepository) FindByStatus(ctx context.Context, status entity.TransactionStatus) ([]*entity.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found []*entity.Transaction
+	for _, transaction := range r.transactions {
+		if transaction.Status == status {
+			found = append(found, cloneTransaction(transaction))
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].CreatedAt.Before(found[j].CreatedAt)
+	})
+	return found, nil
 }
 
 func (r *InMemoryTransactionRepository) FindByIdempotencyKey(ctx context.Context, key string) (*entity.Transaction, error) {
@@ -55,7 +73,11 @@ func (r *InMemoryTransactionRepository) FindByIdempotencyKey(ctx context.Context
 		return nil, errors.New("transaction not found")
 	}
 
-	return r.transactions[transactionID], nil
+	transaction, exists := r.transactions[transactionID]
+	if !exists {
+		return nil, errors.New("transaction not found")
+	}
+	return cloneTransaction(transaction), nil
 }
 
 func (r *InMemoryTransactionRepository) Update(ctx context.Context, transaction *entity.Transaction) error {
@@ -66,7 +88,7 @@ func (r *InMemoryTransactionRepository) Update(ctx context.Context, transaction
 		return errors.New("transaction not found")
 	}
 
-	r.transactions[transaction.ID] = transaction
+	r.transactions[transaction.ID] = cloneTransaction(transaction)
 	return nil
 }
 
@@ -77,3 +99,78 @@ func (r *InMemoryTransactionRepository) StoreIdempotencyKey(ctx context.Context,
 	r.idempotencyKeys[key] = transactionID
 	return nil
 }
+
+func (r *InMemoryTransactionRepository) List(ctx context.Context, filter repository.TransactionFilter) ([]*entity.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var after *entity.Transaction
+	if filter.AfterID != "" {
+		cursor, exists := r.transactions[filter.AfterID]
+		if !exists {
+			return nil, errors.New("transaction not found")
+		}
+		after = cursor
+	}
+
+	var found []*entity.Transaction
+	for _, transaction := range r.transactions {
+		if filter.PartnerID != "" && transaction.PartnerID != filter.PartnerID {
+			continue
+		}
+		if filter.Status != "" && transaction.Status != filter.Status {
+			continue
+		}
+		if !filter.Since.IsZero() && transaction.CreatedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !transaction.CreatedAt.Before(filter.Until) {
+			continue
+		}
+		if after != nil && !transactionBefore(after, transaction) {
+			continue
+		}
+		found = append(found, cloneTransaction(transaction))
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return transactionBefore(found[i], found[j])
+	})
+	if filter.Limit > 0 && len(found) > filter.Limit {
+		found = found[:filter.Limit]
+	}
+	return found, nil
+}
+
+// cloneTransaction copies a transaction so callers cannot change the stored
+// one except through Update.
+func cloneTransaction(t *entity.Transaction) *entity.Transaction {
+	c := *t
+	if t.Risk != nil {
+		risk := *t.Risk
+		risk.TriggeredRules = append([]entity.TriggeredRule(nil), t.Risk.TriggeredRules...)
+		c.Risk = &risk
+	}
+	if t.Review != nil {
+		review := *t.Review
+		c.Review = &review
+	}
+	if t.Recovery != nil {
+		recovery := *t.Recovery
+		if t.Recovery.EscalatedAt != nil {
+			escalatedAt := *t.Recovery.EscalatedAt
+			recovery.EscalatedAt = &escalatedAt
+		}
+		c.Recovery = &recovery
+	}
+	return &c
+}
+
+// transactionBefore orders transactions by creation time, breaking ties by
+// ID so paging is stable.
+func transactionBefore(a, b *entity.Transaction) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+)
+
+func TestTransactionRepositoryChangesOnlyThroughUpdate(t *testing.T) {
+	ctx := context.Background()
+	transactions := NewInMemoryTransactionRepository()
+
+	txn := entity.NewTransaction("txn_1", "partner_a", "u1", "USD", 10)
+	txn.Risk = entity.NewRiskAssessment([]entity.TriggeredRule{{Rule: "r", Decision: entity.RiskDecisionReview}}, "v1")
+	txn.HoldForReview()
+	if err := transactions.Create(ctx, txn); err != nil {
+		t.Fatal(err)
+	}
+	if err := transactions.StoreIdempotencyKey(ctx, "key_1", txn.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Changing the created value leaves the stored one alone.
+	txn.MarkFailed()
+
+	byStatus, err := transactions.FindByStatus(ctx, entity.TransactionStatusHeldForReview)
+	if err != nil || len(byStatus) != 1 {
+		t.Fatalf("FindByStatus = %v, %v; want the held transaction", byStatus, err)
+	}
+	byKey, err := transactions.FindByIdempotencyKey(ctx, "key_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed, err := transactions.List(ctx, repository.TransactionFilter{})
+	if err != nil || len(listed) != 1 {
+		t.Fatalf("List = %v, %v; want one transaction", listed, err)
+	}
+	for name, found := range map[string]*entity.Transaction{"FindByStatus": byStatus[0], "FindByIdempotencyKey": byKey, "List": listed[0]} {
+		found.Approve("alice", "ok", false)
+		found.Risk.TriggeredRules[0].Rule = "changed"
+		found.Recovery = &entity.RecoveryState{Attempts: 1}
+
+		stored, err := transactions.FindByID(ctx, txn.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != entity.TransactionStatusHeldForReview || stored.Review != nil || stored.Recovery != nil || stored.Risk.TriggeredRules[0].Rule != "r" {
+			t.Fatalf("changing the %s result changed the stored transaction: %+v", name, stored)
+		}
+	}
+
+	found, err := transactions.FindByID(ctx, txn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found.Approve("alice", "ok", false)
+	if err := transactions.Update(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	found.Review.Reviewer = "mallory"
+
+	stored, err := transactions.FindByID(ctx, txn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != entity.TransactionStatusPending || stored.Review == nil || stored.Review.Reviewer != "alice" {
+		t.Fatalf("stored transaction %+v, review %+v; want alice's update only", stored, stored.Review)
+	}
+}
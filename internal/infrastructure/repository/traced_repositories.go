@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The Traced* decorators wrap a repository so that every call appears as a
+// client span in the request's trace, whichever storage backs it.
+
+var tracer = otel.Tracer("github.com/sample-provider/buy-credit-api/internal/infrastructure/repository")
+
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.operation", name)),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type TracedTransactionRepository struct {
+	inner repository.TransactionRepository
+}
+
+func NewTracedTransactionRepository(inner repository.TransactionRepository) repository.TransactionRepository {
+	return &TracedTransactionRepository{inner: inner}
+}
+
+func (r *TracedTransactionRepository) Create(ctx context.Context, transaction *entity.Transaction) error {
+	ctx, span := startSpan(ctx, "TransactionRepository.Create")
+	err := r.inner.Create(ctx, transaction)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTransactionRepository) FindByID(ctx context.Context, id string) (*entity.Transaction, error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.FindByID")
+	transaction, err := r.inner.FindByID(ctx, id)
+	endSpan(span, err)
+	return transaction, err
+}
+
+func (r *TracedTransactionRepository) FindByStatus(ctx context.Context, status entity.TransactionStatus) ([]*entity.Transaction, error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.FindByStatus")
+	transactions, err := r.inner.FindByStatus(ctx, status)
+	endSpan(span, err)
+	return transactions, err
+}
+
+func (r *TracedTransactionRepository) FindByIdempotencyKey(ctx context.Context, key string) (*entity.Transaction, error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.FindByIdempotencyKey")
+	transaction, err := r.inner.FindByIdempotencyKey(ctx, key)
+	endSpan(span, err)
+	return transaction, err
+}
+
+func (r *TracedTransactionRepository) Update(ctx context.Context, transaction *entity.Transaction) error {
+	ctx, span := startSpan(ctx, "TransactionRepository.Update")
+	err := r.inner.Update(ctx, transaction)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTransactionRepository) StoreIdempotencyKey(ctx context.Context, key, transactionID string) error {
+	ctx, span := startSpan(ctx, "TransactionRepository.StoreIdempotencyKey")
+	err := r.inner.StoreIdempotencyKey(ctx, key, transactionID)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTransactionRepository) List(ctx context.Context, filter repository.TransactionFilter) ([]*entity.Transaction, error) {
+	ctx, span := startSpan(ctx, "TransactionRepository.List")
+	transactions, err := r.inner.List(ctx, filter)
+	endSpan(span, err)
+	return transactions, err
+}
+
+type TracedPartnerRepository struct {
+	inner repository.PartnerRepository
+}
+
+func NewTracedPartnerRepository(inner repository.PartnerRepository) repository.PartnerRepository {
+	return &TracedPartnerRepository{inner: inner}
+}
+
+func (r *TracedPartnerRepository) FindByClientID(ctx context.Context, clientID string) (*entity.Partner, error) {
+	ctx, span := startSpan(ctx, "PartnerRepository.FindByClientID")
+	partner, err := r.inner.FindByClientID(ctx, clientID)
+	endSpan(span, err)
+	return partner, err
+}
+
+func (r *TracedPartnerRepository) FindByID(ctx context.Context, id string) (*entity.Partner, error) {
+	ctx, span := startSpan(ctx, "PartnerRepository.FindByID")
+	partner, err := r.inner.FindByID(ctx, id)
+	endSpan(span, err)
+	return partner, err
+}
+
+func (r *TracedPartnerRepository) FindByIDForUpdate(ctx context.Context, id string) (*entity.Partner, error) {
+	ctx, span := startSpan(ctx, "PartnerRepository.FindByIDForUpdate")
+	partner, err := r.inner.FindByIDForUpdate(ctx, id)
+	endSpan(span, err)
+	return partner, err
+}
+
+func (r *TracedPartnerRepository) Create(ctx context.Context, partner *entity.Partner) error {
+	ctx, span := startSpan(ctx, "PartnerRepository.Create")
+	err := r.inner.Create(ctx, partner)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedPartnerRepository) Update(ctx context.Context, partner *entity.Partner) error {
+	ctx, span := startSpan(ctx, "PartnerRepository.Update")
+	err := r.inner.Update(ctx, partner)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedPartnerRepository) List(ctx context.Context, filter repository.PartnerFilter) ([]*entity.Partner, error) {
+	ctx, span := startSpan(ctx, "PartnerRepository.List")
+	partners, err := r.inner.List(ctx, filter)
+	endSpan(span, err)
+	return partners, err
+}
+
+type TracedRefreshTokenRepository struct {
+	inner repository.RefreshTokenRepository
+}
+
+func NewTracedRefreshTokenRepository(inner repository.RefreshTokenRepository) repository.RefreshTokenRepository {
+	return &TracedRefreshTokenRepository{inner: inner}
+}
+
+func (r *TracedRefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	ctx, span := startSpan(ctx, "RefreshTokenRepository.Create")
+	err := r.inner.Create(ctx, token)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	ctx, span := startSpan(ctx, "RefreshTokenRepository.FindByHash")
+	token, err := r.inner.FindByHash(ctx, tokenHash)
+	endSpan(span, err)
+	return token, err
+}
+
+func (r *TracedRefreshTokenRepository) FindByFamily(ctx context.Context, familyID string) ([]*entity.RefreshToken, error) {
+	ctx, span := startSpan(ctx, "RefreshTokenRepository.FindByFamily")
+	tokens, err := r.inner.FindByFamily(ctx, familyID)
+	endSpan(span, err)
+	return tokens, err
+}
+
+func (r *TracedRefreshTokenRepository) Update(ctx context.Context, token *entity.RefreshToken) error {
+	ctx, span := startSpan(ctx, "RefreshTokenRepository.Update")
+	err := r.inner.Update(ctx, token)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedRefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	ctx, span := startSpan(ctx, "RefreshTokenRepository.MarkUsed")
+	err := r.inner.MarkUsed(ctx, id, usedAt)
+	endSpan(span, err)
+	return err
+}
+
+type TracedRevokedTokenRepository struct {
+	inner repository.RevokedTokenRepository
+}
+
+func NewTracedRevokedTokenRepository(inner repository.RevokedTokenRepository) repository.RevokedTokenRepository {
+	return &TracedRevokedTokenRepository{inner: inner}
+}
+
+func (r *TracedRevokedTokenRepository) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ctx, span := startSpan(ctx, "RevokedTokenRepository.Revoke")
+	err := r.inner.Revoke(ctx, tokenID, expiresAt)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedRevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	ctx, span := startSpan(ctx, "RevokedTokenRepository.IsRevoked")
+	revoked, err := r.inner.IsRevoked(ctx, tokenID)
+	endSpan(span, err)
+	return revoked, err
+}
+
+type TracedSpendingRepository struct {
+	inner repository.SpendingRepository
+}
+
+func NewTracedSpendingRepository(inner repository.SpendingRepository) repository.SpendingRepository {
+	return &TracedSpendingRepository{inner: inner}
+}
+
+func (r *TracedSpendingRepository) Reserve(ctx context.Context, counters []repository.SpendingCounter, amount float64) ([]float64, error) {
+	ctx, span := startSpan(ctx, "SpendingRepository.Reserve")
+	used, err := r.inner.Reserve(ctx, counters, amount)
+	// Hitting a limit is an expected outcome, not a storage failure.
+	if err == repository.ErrSpendingLimitReached {
+		span.SetAttributes(attribute.Bool("spending.limit_reached", true))
+		endSpan(span, nil)
+	} else {
+		endSpan(span, err)
+	}
+	return used, err
+}
+
+func (r *TracedSpendingRepository) Release(ctx context.Context, keys []string, amount float64) error {
+	ctx, span := startSpan(ctx, "SpendingRepository.Release")
+	err := r.inner.Release(ctx, keys, amount)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedSpendingRepository) Usage(ctx context.Context, keys []string) ([]float64, error) {
+	ctx, span := startSpan(ctx, "SpendingRepository.Usage")
+	used, err := r.inner.Usage(ctx, keys)
+	endSpan(span, err)
+	return used, err
+}
@@ -0,0 +1,272 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/domain/repository"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Engine evaluates purchases against a rule set that can be replaced at
+// runtime. Rules are read from a JSON file when one is configured.
+type Engine struct {
+	history repository.PurchaseHistoryRepository
+	path    string
+
+	mu      sync.RWMutex
+	rules   *RuleSet
+	modTime time.Time
+}
+
+// NewEngine loads the rules at path, or uses DefaultRuleSet if path is empty.
+func NewEngine(history repository.PurchaseHistoryRepository, path string) (*Engine, error) {
+	e := &Engine{
+		history: history,
+		path:    path,
+		rules:   DefaultRuleSet(),
+	}
+	if path != "" {
+		if err := e.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Reload re-reads the rules file. An invalid file leaves the current rules
+// in place.
+func (e *Engine) Reload() error {
+	if e.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return err
+	}
+
+	var rules RuleSet
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("parse risk rules: %w", err)
+	}
+	if err := rules.Validate(); err != nil {
+		return fmt.Errorf("invalid risk rules: %w", err)
+	}
+
+	e.mu.Lock()
+	e.rules = &rules
+	e.modTime = info.ModTime()
+	e.mu.Unlock()
+	return nil
+}
+
+// Run polls the rules file every interval and reloads it when it changes,
+// until ctx is cancelled.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	if e.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(e.path)
+			if err != nil {
+				continue
+			}
+			e.mu.RLock()
+			unchanged := info.ModTime().Equal(e.modTime)
+			e.mu.RUnlock()
+			if unchanged {
+				continue
+			}
+			if err := e.Reload(); err != nil {
+				slog.Error("risk rules reload failed", "error", err)
+				continue
+			}
+			slog.Info("risk rules reloaded", "version", e.Rules().Version)
+		}
+	}
+}
+
+// Rules returns the rule set currently in force.
+func (e *Engine) Rules() *RuleSet {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rules
+}
+
+// Assess evaluates every enabled rule against the purchase. The purchase is
+// recorded in the history first, so that the rules see concurrent purchases
+// and concurrent purchases see this one; it is removed again if denied.
+func (e *Engine) Assess(ctx context.Context, txn *entity.Transaction) (_ *entity.RiskAssessment, err error) {
+	ctx, span := otel.Tracer("github.com/sample-provider/buy-credit-api/internal/infrastructure/risk").Start(ctx, "RiskEngine.Assess")
+	defer span.End()
+
+	rules := e.Rules()
+	phone := normalizePhoneNumber(txn.Metadata.PhoneNumber)
+
+	if err := e.history.Record(ctx, repository.PurchaseRecord{
+		TransactionID: txn.ID,
+		PartnerID:     txn.PartnerID,
+		UserID:        txn.UserID,
+		WalletID:      txn.WalletID,
+		PhoneNumber:   phone,
+		Amount:        txn.Amount,
+		Currency:      txn.Currency,
+		At:            txn.CreatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("record purchase: %w", err)
+	}
+	var assessment *entity.RiskAssessment
+	defer func() {
+		if err != nil || assessment.Decision == entity.RiskDecisionDeny {
+			if forgetErr := e.Forget(context.WithoutCancel(ctx), txn); forgetErr != nil {
+				logging.FromContext(ctx).Error("risk history rollback failed", "error", forgetErr)
+			}
+		}
+	}()
+
+	var triggered []entity.TriggeredRule
+	for _, rule := range rules.Rules {
+		if rule.Disabled {
+			continue
+		}
+		if rule.Currency != "" && !strings.EqualFold(rule.Currency, txn.Currency) {
+			continue
+		}
+
+		reason, err := e.evaluate(ctx, rule, txn, phone)
+		if err != nil {
+			return nil, fmt.Errorf("risk rule %s: %w", rule.Name, err)
+		}
+		if reason != "" {
+			triggered = append(triggered, entity.TriggeredRule{
+				Rule:     rule.Name,
+				Decision: rule.Decision,
+				Reason:   reason,
+			})
+		}
+	}
+
+	assessment = entity.NewRiskAssessment(triggered, rules.Version)
+	span.SetAttributes(
+		attribute.String("risk.decision", string(assessment.Decision)),
+		attribute.Int("risk.triggered_rules", len(triggered)),
+	)
+	return assessment, nil
+}
+
+// Forget removes a purchase that Assess recorded but that did not go ahead.
+func (e *Engine) Forget(ctx context.Context, txn *entity.Transaction) error {
+	return e.history.Remove(ctx, txn.ID)
+}
+
+// evaluate returns why the rule matched, or "" if it did not. The history
+// already holds txn, so counts include it.
+func (e *Engine) evaluate(ctx context.Context, rule Rule, txn *entity.Transaction, phone string) (string, error) {
+	since := txn.CreatedAt.Add(-rule.Window.Duration)
+
+	switch rule.Type {
+	case RuleTypePhoneVelocity:
+		if phone == "" {
+			return "", nil
+		}
+		records, err := e.history.FindByPhoneNumber(ctx, phone, since)
+		if err != nil {
+			return "", err
+		}
+		if count := len(records); count > rule.MaxCount {
+			return fmt.Sprintf("%d purchases for phone number within %s", count, rule.Window), nil
+		}
+
+	case RuleTypePhoneUserFanout:
+		if phone == "" {
+			return "", nil
+		}
+		records, err := e.history.FindByPhoneNumber(ctx, phone, since)
+		if err != nil {
+			return "", err
+		}
+		users := make(map[string]bool, len(records))
+		for _, rec := range records {
+			users[rec.UserID] = true
+		}
+		if len(users) > rule.MaxCount {
+			return fmt.Sprintf("%d users bought for phone number within %s", len(users), rule.Window), nil
+		}
+
+	case RuleTypeRepeatedMaxAmount:
+		if txn.Amount < rule.MinAmount {
+			return "", nil
+		}
+		records, err := e.history.FindByUser(ctx, txn.PartnerID, txn.UserID, since)
+		if err != nil {
+			return "", err
+		}
+		// MinAmount is in the purchase's currency, so only purchases in that
+		// currency are compared with it.
+		count := 0
+		for _, rec := range records {
+			if rec.Amount >= rule.MinAmount && strings.EqualFold(rec.Currency, txn.Currency) {
+				count++
+			}
+		}
+		if count > rule.MaxCount {
+			return fmt.Sprintf("%d purchases of at least %.2f within %s", count, rule.MinAmount, rule.Window), nil
+		}
+
+	case RuleTypeNewWalletLargeAmount:
+		if txn.Amount < rule.MinAmount {
+			return "", nil
+		}
+		if txn.WalletID == "" {
+			return fmt.Sprintf("purchase of %.2f without a wallet", txn.Amount), nil
+		}
+		firstSeen, err := e.history.WalletFirstSeen(ctx, txn.WalletID)
+		if err != nil {
+			return "", err
+		}
+		if firstSeen.IsZero() || txn.CreatedAt.Sub(firstSeen) < rule.MaxWalletAge.Duration {
+			return fmt.Sprintf("purchase of %.2f from wallet first seen less than %s ago", txn.Amount, rule.MaxWalletAge), nil
+		}
+
+	case RuleTypeMissingPhoneNumber:
+		if phone == "" && txn.Amount >= rule.MinAmount {
+			return "purchase without a phone number", nil
+		}
+	}
+
+	return "", nil
+}
+
+// normalizePhoneNumber strips formatting so that "+254 700-000 000" and
+// "+254700000000" count as the same number.
+func normalizePhoneNumber(phone string) string {
+	var b strings.Builder
+	for i, r := range phone {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
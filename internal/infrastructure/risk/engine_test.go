@@ -0,0 +1,234 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	inmemory "github.com/sample-provider/buy-credit-api/internal/infrastructure/repository"
+)
+
+// purchase is one purchase in a test sequence and the decision expected for it.
+// purchase describes one transaction to assess. Purchases without a phone
+// get a distinct one unless missingPhone is set.
+type purchase struct {
+	partner      string
+	user         string
+	phone        string
+	missingPhone bool
+	wallet       string
+	amount       float64
+	currency     string
+	want         entity.RiskDecision
+}
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	e, err := NewEngine(inmemory.NewInMemoryPurchaseHistoryRepository(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func newPurchase(n int, p purchase) *entity.Transaction {
+	partner, currency := p.partner, p.currency
+	if partner == "" {
+		partner = "partner_a"
+	}
+	if currency == "" {
+		currency = "USD"
+	}
+	txn := entity.NewTransaction(fmt.Sprintf("txn_%d", n), partner, p.user, currency, p.amount)
+	txn.WalletID = p.wallet
+	txn.Metadata.PhoneNumber = p.phone
+	if p.phone == "" && !p.missingPhone {
+		txn.Metadata.PhoneNumber = fmt.Sprintf("+25471%07d", n)
+	}
+	return txn
+}
+
+func TestAssessDefaultRules(t *testing.T) {
+	const (
+		allow  = entity.RiskDecisionAllow
+		review = entity.RiskDecisionReview
+		deny   = entity.RiskDecisionDeny
+	)
+	tests := []struct {
+		name      string
+		purchases []purchase
+	}{
+		{
+			name: "phone velocity counts formatting variants together",
+			purchases: []purchase{
+				{user: "u1", phone: "+254700000001", amount: 10, want: allow},
+				{user: "u1", phone: "+254 700 000 001", amount: 10, want: allow},
+				{user: "u1", phone: "+254-700-000-001", amount: 10, want: allow},
+				{user: "u1", phone: "+254700000001", amount: 10, want: allow},
+				{user: "u1", phone: "+254700000001", amount: 10, want: allow},
+				{user: "u1", phone: "+254700000001", amount: 10, want: review},
+			},
+		},
+		{
+			name: "repeated max amount within one currency",
+			purchases: []purchase{
+				{user: "u1", amount: 100, want: allow},
+				{user: "u1", amount: 150, want: allow},
+				{user: "u1", amount: 100, want: allow},
+				{user: "u1", amount: 100, want: review},
+			},
+		},
+		{
+			name: "repeated max amount ignores other currencies",
+			purchases: []purchase{
+				{user: "u1", amount: 100, currency: "USD", want: allow},
+				{user: "u1", amount: 100, currency: "USD", want: allow},
+				{user: "u1", amount: 100, currency: "USD", want: allow},
+				{user: "u1", amount: 100, currency: "KES", want: allow},
+			},
+		},
+		{
+			name: "repeated max amount is scoped to the partner",
+			purchases: []purchase{
+				{partner: "partner_a", user: "u1", amount: 100, want: allow},
+				{partner: "partner_a", user: "u1", amount: 100, want: allow},
+				{partner: "partner_a", user: "u1", amount: 100, want: allow},
+				{partner: "partner_b", user: "u1", amount: 100, want: allow},
+				{partner: "partner_a", user: "u1", amount: 100, want: review},
+			},
+		},
+		{
+			name: "denied purchases are not counted",
+			purchases: []purchase{
+				{user: "u1", phone: "+254700000002", amount: 10, want: allow},
+				{user: "u2", phone: "+254700000002", amount: 10, want: allow},
+				{user: "u3", phone: "+254700000002", amount: 10, want: allow},
+				{user: "u4", phone: "+254700000002", amount: 10, want: deny},
+				{user: "u5", phone: "+254700000002", amount: 10, want: deny},
+				{user: "u1", phone: "+254700000002", amount: 10, want: allow},
+			},
+		},
+		{
+			name: "new wallet large amount",
+			purchases: []purchase{
+				{user: "u1", wallet: "w1", amount: 250, want: review},
+				{user: "u2", wallet: "w2", amount: 50, want: allow},
+			},
+		},
+		{
+			name: "missing wallet",
+			purchases: []purchase{
+				{user: "u1", wallet: "", amount: 250, want: review},
+				{user: "u2", wallet: "", amount: 50, want: allow},
+			},
+		},
+		{
+			name: "missing phone number",
+			purchases: []purchase{
+				{user: "u1", wallet: "w1", missingPhone: true, amount: 10, want: review},
+				{user: "u2", wallet: "w2", phone: "n/a", amount: 10, want: review},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			for i, p := range tt.purchases {
+				got, err := e.Assess(context.Background(), newPurchase(i, p))
+				if err != nil {
+					t.Fatalf("purchase %d: %v", i+1, err)
+				}
+				if got.Decision != p.want {
+					t.Fatalf("purchase %d: decision %s, want %s (rules %+v)", i+1, got.Decision, p.want, got.TriggeredRules)
+				}
+			}
+		})
+	}
+}
+
+func TestAssessConcurrentPurchasesSeeEachOther(t *testing.T) {
+	e := newTestEngine(t)
+
+	const purchases = 20
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < purchases; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got, err := e.Assess(context.Background(), newPurchase(i, purchase{user: "u1", phone: "+254700000003", amount: 10}))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got.Decision == entity.RiskDecisionAllow {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// phone_velocity_review allows five purchases an hour.
+	if allowed > 5 {
+		t.Fatalf("%d concurrent purchases allowed, want at most 5", allowed)
+	}
+}
+
+func TestForgetRemovesPurchase(t *testing.T) {
+	e := newTestEngine(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		txn := newPurchase(i, purchase{user: "u1", amount: 100})
+		if _, err := e.Assess(ctx, txn); err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 {
+			if err := e.Forget(ctx, txn); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	got, err := e.Assess(ctx, newPurchase(3, purchase{user: "u1", amount: 100}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Decision != entity.RiskDecisionAllow {
+		t.Fatalf("decision %s after forgetting a purchase, want ALLOW", got.Decision)
+	}
+}
+
+func TestRuleSetValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"valid window", Rule{Name: "r", Type: RuleTypePhoneVelocity, Decision: entity.RiskDecisionReview, Window: Duration{maxRuleWindow}}, false},
+		{"window too long", Rule{Name: "r", Type: RuleTypePhoneVelocity, Decision: entity.RiskDecisionReview, Window: Duration{maxRuleWindow + 1}}, true},
+		{"missing window", Rule{Name: "r", Type: RuleTypeRepeatedMaxAmount, Decision: entity.RiskDecisionReview}, true},
+		{"wallet age too long", Rule{Name: "r", Type: RuleTypeNewWalletLargeAmount, Decision: entity.RiskDecisionReview, MaxWalletAge: Duration{maxWalletAge + 1}}, true},
+		{"unknown decision", Rule{Name: "r", Type: RuleTypePhoneVelocity, Decision: "MAYBE", Window: Duration{maxRuleWindow}}, true},
+		{"unknown type", Rule{Name: "r", Type: "OTHER", Decision: entity.RiskDecisionReview}, true},
+		{"negative amount", Rule{Name: "r", Type: RuleTypePhoneVelocity, Decision: entity.RiskDecisionReview, Window: Duration{maxRuleWindow}, MinAmount: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := RuleSet{Rules: []Rule{tt.rule}}
+			if err := rs.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := DefaultRuleSet().Validate(); err != nil {
+		t.Fatalf("default rules: %v", err)
+	}
+}
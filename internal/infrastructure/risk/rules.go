@@ -0,0 +1,169 @@
+package risk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+)
+
+// RuleType selects the check a rule performs.
+type RuleType string
+
+const (
+	// RuleTypePhoneVelocity triggers when more than MaxCount purchases target
+	// one phone number within Window.
+	RuleTypePhoneVelocity RuleType = "PHONE_VELOCITY"
+	// RuleTypeRepeatedMaxAmount triggers when an end user makes more than
+	// MaxCount purchases of at least MinAmount within Window.
+	RuleTypeRepeatedMaxAmount RuleType = "REPEATED_MAX_AMOUNT"
+	// RuleTypeNewWalletLargeAmount triggers on a purchase of at least
+	// MinAmount from a wallet first seen less than MaxWalletAge ago, or
+	// without a wallet, which has no history to vouch for it.
+	RuleTypeNewWalletLargeAmount RuleType = "NEW_WALLET_LARGE_AMOUNT"
+	// RuleTypePhoneUserFanout triggers when more than MaxCount distinct end
+	// users buy for one phone number within Window.
+	RuleTypePhoneUserFanout RuleType = "PHONE_USER_FANOUT"
+	// RuleTypeMissingPhoneNumber triggers on a purchase of at least MinAmount
+	// without a phone number, which the phone rules cannot assess.
+	RuleTypeMissingPhoneNumber RuleType = "MISSING_PHONE_NUMBER"
+)
+
+// Duration is a time.Duration that reads and writes JSON strings such as "1h".
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"1h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// Rule is one configurable risk check.
+type Rule struct {
+	Name     string              `json:"name"`
+	Type     RuleType            `json:"type"`
+	Decision entity.RiskDecision `json:"decision"`
+	Disabled bool                `json:"disabled,omitempty"`
+
+	// Currency limits amount-based rules to one currency; empty applies to
+	// all, comparing MinAmount with each purchase in its own currency.
+	Currency     string   `json:"currency,omitempty"`
+	Window       Duration `json:"window,omitempty"`
+	MaxCount     int      `json:"maxCount,omitempty"`
+	MinAmount    float64  `json:"minAmount,omitempty"`
+	MaxWalletAge Duration `json:"maxWalletAge,omitempty"`
+}
+
+// RuleSet is a versioned set of rules, loaded as a whole.
+type RuleSet struct {
+	Version string `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+const (
+	// maxRuleWindow matches the purchase history retention.
+	maxRuleWindow = 7 * 24 * time.Hour
+	// maxWalletAge matches how long inactive wallets are remembered.
+	maxWalletAge = 30 * 24 * time.Hour
+)
+
+// Validate reports the first problem with the rule set.
+func (rs *RuleSet) Validate() error {
+	names := make(map[string]bool, len(rs.Rules))
+	for _, rule := range rs.Rules {
+		if rule.Name == "" {
+			return errors.New("rule name is required")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if !entity.IsKnownRiskDecision(rule.Decision) {
+			return fmt.Errorf("rule %q: unknown decision %q", rule.Name, rule.Decision)
+		}
+		if rule.MaxCount < 0 || rule.MinAmount < 0 {
+			return fmt.Errorf("rule %q: maxCount and minAmount must not be negative", rule.Name)
+		}
+
+		switch rule.Type {
+		case RuleTypePhoneVelocity, RuleTypePhoneUserFanout, RuleTypeRepeatedMaxAmount:
+			if rule.Window.Duration <= 0 || rule.Window.Duration > maxRuleWindow {
+				return fmt.Errorf("rule %q: window must be between 0 and %s", rule.Name, maxRuleWindow)
+			}
+		case RuleTypeNewWalletLargeAmount:
+			if rule.MaxWalletAge.Duration <= 0 || rule.MaxWalletAge.Duration > maxWalletAge {
+				return fmt.Errorf("rule %q: maxWalletAge must be between 0 and %s", rule.Name, maxWalletAge)
+			}
+		case RuleTypeMissingPhoneNumber:
+		default:
+			return fmt.Errorf("rule %q: unknown type %q", rule.Name, rule.Type)
+		}
+	}
+	return nil
+}
+
+// DefaultRuleSet is used when no rules file is configured.
+func DefaultRuleSet() *RuleSet {
+	return &RuleSet{
+		Version: "default",
+		Rules: []Rule{
+			{
+				Name:     "phone_velocity_review",
+				Type:     RuleTypePhoneVelocity,
+				Decision: entity.RiskDecisionReview,
+				Window:   Duration{time.Hour},
+				MaxCount: 5,
+			},
+			{
+				Name:     "phone_velocity_deny",
+				Type:     RuleTypePhoneVelocity,
+				Decision: entity.RiskDecisionDeny,
+				Window:   Duration{time.Hour},
+				MaxCount: 15,
+			},
+			{
+				Name:      "repeated_max_amount",
+				Type:      RuleTypeRepeatedMaxAmount,
+				Decision:  entity.RiskDecisionReview,
+				Window:    Duration{24 * time.Hour},
+				MaxCount:  3,
+				MinAmount: 100,
+			},
+			{
+				Name:         "new_wallet_large_amount",
+				Type:         RuleTypeNewWalletLargeAmount,
+				Decision:     entity.RiskDecisionReview,
+				MaxWalletAge: Duration{24 * time.Hour},
+				MinAmount:    200,
+			},
+			{
+				Name:     "phone_user_fanout",
+				Type:     RuleTypePhoneUserFanout,
+				Decision: entity.RiskDecisionDeny,
+				Window:   Duration{24 * time.Hour},
+				MaxCount: 3,
+			},
+			{
+				Name:     "missing_phone_number",
+				Type:     RuleTypeMissingPhoneNumber,
+				Decision: entity.RiskDecisionReview,
+			},
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package security
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sample-provider/buy-credit-api/internal/domain/entity"
+	"github.com/sample-provider/buy-credit-api/internal/infrastructure/logging"
+)
+
+// LogEventPublisher writes security events as structured warnings so they
+// can be picked up by log-based alerting.
+type LogEventPublisher struct{}
+
+func NewLogEventPublisher() *LogEventPublisher {
+	return &LogEventPublisher{}
+}
+
+func (p *LogEventPublisher) Publish(ctx context.Context, event *entity.SecurityEvent) {
+	if event.PartnerID != "" {
+		ctx = logging.WithPartnerID(ctx, event.PartnerID)
+	}
+	attrs := []any{
+		slog.String("event_type", string(event.Type)),
+		slog.String("reason", event.Reason),
+		slog.Time("event_time", event.Timestamp),
+	}
+	if event.ClientID != "" {
+		attrs = append(attrs, slog.String("client_id", event.ClientID))
+	}
+	if event.IP != "" {
+		attrs = append(attrs, slog.String("ip", event.IP))
+	}
+	if len(event.Details) > 0 {
+		details := make([]any, 0, len(event.Details))
+		for k, v := range event.Details {
+			details = append(details, slog.String(k, v))
+		}
+		attrs = append(attrs, slog.Group("details", details...))
+	}
+	logging.FromContext(ctx).Warn("security_event", attrs...)
+}
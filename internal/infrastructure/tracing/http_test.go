@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanID  = "00f067aa0ba902b7"
+)
+
+// useTestProvider installs a provider sampling like Setup does at ratio and
+// returns the recorder its spans end up in.
+func useTestProvider(t *testing.T, ratio float64) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func TestMiddlewareDoesNotTrustCallerTraceContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		ratio       float64
+		traceparent string
+		wantSpans   int
+		wantLink    bool
+	}{
+		{
+			name:        "sampled flag cannot force sampling",
+			ratio:       0,
+			traceparent: "00-" + remoteTraceID + "-" + remoteSpanID + "-01",
+			wantSpans:   0,
+		},
+		{
+			name:        "remote trace is linked, not continued",
+			ratio:       1,
+			traceparent: "00-" + remoteTraceID + "-" + remoteSpanID + "-00",
+			wantSpans:   1,
+			wantLink:    true,
+		},
+		{
+			name:        "malformed traceparent is ignored",
+			ratio:       1,
+			traceparent: "00-not-a-trace-01",
+			wantSpans:   1,
+		},
+		{
+			name:      "no traceparent",
+			ratio:     1,
+			wantSpans: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useTestProvider(t, tt.ratio)
+
+			var handlerBaggage baggage.Baggage
+			h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerBaggage = baggage.FromContext(r.Context())
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/v1/limits", nil)
+			if tt.traceparent != "" {
+				r.Header.Set("traceparent", tt.traceparent)
+			}
+			r.Header.Set("baggage", "tenant=partner_other,debug=true")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if handlerBaggage.Len() != 0 {
+				t.Errorf("handler saw caller baggage %q", handlerBaggage.String())
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != tt.wantSpans {
+				t.Fatalf("%d spans recorded, want %d", len(spans), tt.wantSpans)
+			}
+			if len(spans) == 0 {
+				return
+			}
+			span := spans[0]
+			if span.Parent().IsValid() {
+				t.Errorf("span has parent %v, want a new root", span.Parent())
+			}
+			if got := span.SpanContext().TraceID().String(); got == remoteTraceID {
+				t.Errorf("span continued the caller's trace %s", got)
+			}
+			if got := w.Header().Get(TraceIDHeader); got != span.SpanContext().TraceID().String() {
+				t.Errorf("%s = %q, want the new trace ID %s", TraceIDHeader, got, span.SpanContext().TraceID())
+			}
+
+			links := span.Links()
+			if !tt.wantLink {
+				if len(links) != 0 {
+					t.Errorf("span has %d links, want none", len(links))
+				}
+				return
+			}
+			if len(links) != 1 {
+				t.Fatalf("span has %d links, want 1", len(links))
+			}
+			want, _ := trace.TraceIDFromHex(remoteTraceID)
+			if links[0].SpanContext.TraceID() != want || !links[0].SpanContext.IsRemote() {
+				t.Errorf("link = %v, want remote span in trace %s", links[0].SpanContext, remoteTraceID)
+			}
+		})
+	}
+}